@@ -0,0 +1,190 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conversion converts between the versions of a kind. The versions share
+// their json field names, each one adds to or drops from the previous, so a
+// version is converted by copying its spec and status through json.
+//
+// Spec fields that the target version cannot hold are kept in the DataAnnotation
+// of the target, as the json of the source spec. When that object is converted back, the
+// changes made to it since are applied to the kept source, so that a client that
+// reads and updates an older version does not drop the fields it does not know.
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const DataAnnotation = "broker.amq.io/conversion-data"
+
+// Convert fills dst, a different version of the kind of src
+func Convert(src client.Object, dst client.Object) error {
+	spec, err := contentOf(src, "spec")
+	if err != nil {
+		return err
+	}
+	// the status is written by the operator through the storage version, only the spec is kept
+	status, err := contentOf(src, "status")
+	if err != nil {
+		return err
+	}
+
+	dstSpec := spec
+	if data, found := src.GetAnnotations()[DataAnnotation]; found {
+		if dstSpec, err = restore(src, []byte(data), spec); err != nil {
+			return fmt.Errorf("unable to restore the spec of %s from the %s annotation, %v", src.GetName(), DataAnnotation, err)
+		}
+	}
+
+	copyObjectMeta(src, dst)
+	annotations := map[string]string{}
+	for k, v := range src.GetAnnotations() {
+		if k != DataAnnotation {
+			annotations[k] = v
+		}
+	}
+	if err := json.Unmarshal(dstSpec, dst); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(status, dst); err != nil {
+		return err
+	}
+
+	// what dst can hold of the spec of src, anything else is kept for the way back
+	projected, err := project(dst, src, "spec")
+	if err != nil {
+		return err
+	}
+	if equal, err := sameContent(spec, projected); err != nil {
+		return err
+	} else if !equal {
+		annotations[DataAnnotation] = string(spec)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	dst.SetAnnotations(annotations)
+	return nil
+}
+
+// restore applies the changes made to the spec of src since it was converted from
+// data, which is the spec of an object of the version being converted to
+func restore(src client.Object, data []byte, spec []byte) ([]byte, error) {
+	kept, err := newOfType(src)
+	if err != nil {
+		return nil, err
+	}
+	// the kept spec as src saw it
+	if err := json.Unmarshal(data, kept); err != nil {
+		return nil, err
+	}
+	base, err := contentOf(kept, "spec")
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.CreateMergePatch(base, spec)
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.MergePatch(data, patch)
+}
+
+// the fields of from as they are held by an object of the type of as
+func project(from client.Object, as client.Object, fields ...string) ([]byte, error) {
+	content, err := contentOf(from, fields...)
+	if err != nil {
+		return nil, err
+	}
+	projected, err := newOfType(as)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, projected); err != nil {
+		return nil, err
+	}
+	return contentOf(projected, fields...)
+}
+
+// the json of an object with only the given top level fields
+func contentOf(obj client.Object, fields ...string) ([]byte, error) {
+	full, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(full, &all); err != nil {
+		return nil, err
+	}
+	content := map[string]json.RawMessage{}
+	for _, field := range fields {
+		if value, found := all[field]; found {
+			content[field] = value
+		}
+	}
+	return json.Marshal(content)
+}
+
+func sameContent(a []byte, b []byte) (bool, error) {
+	var decodedA, decodedB interface{}
+	if err := decode(a, &decodedA); err != nil {
+		return false, err
+	}
+	if err := decode(b, &decodedB); err != nil {
+		return false, err
+	}
+	return equality.Semantic.DeepEqual(decodedA, decodedB), nil
+}
+
+// numbers stay as written, large integers would lose precision as float64
+func decode(data []byte, into interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(into)
+}
+
+func newOfType(obj client.Object) (client.Object, error) {
+	created, ok := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an object", obj)
+	}
+	return created, nil
+}
+
+func copyObjectMeta(from client.Object, to client.Object) {
+	to.SetName(from.GetName())
+	to.SetGenerateName(from.GetGenerateName())
+	to.SetNamespace(from.GetNamespace())
+	to.SetUID(from.GetUID())
+	to.SetResourceVersion(from.GetResourceVersion())
+	to.SetGeneration(from.GetGeneration())
+	to.SetSelfLink(from.GetSelfLink())
+	to.SetCreationTimestamp(from.GetCreationTimestamp())
+	to.SetDeletionTimestamp(from.GetDeletionTimestamp())
+	to.SetDeletionGracePeriodSeconds(from.GetDeletionGracePeriodSeconds())
+	to.SetLabels(maps.Clone(from.GetLabels()))
+	to.SetFinalizers(slices.Clone(from.GetFinalizers()))
+	to.SetOwnerReferences(slices.Clone(from.GetOwnerReferences()))
+	to.SetManagedFields(slices.Clone(from.GetManagedFields()))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/arkmq-org/activemq-artemis-operator/api/internal/conversion"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1alpha1"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v2alpha1"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v2alpha2"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v2alpha3"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v2alpha4"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v2alpha5"
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const fuzzIterations = 100
+
+var conversions = []struct {
+	hub   ctrlconversion.Hub
+	spoke ctrlconversion.Convertible
+}{
+	{&v1beta1.ActiveMQArtemis{}, &v2alpha1.ActiveMQArtemis{}},
+	{&v1beta1.ActiveMQArtemis{}, &v2alpha2.ActiveMQArtemis{}},
+	{&v1beta1.ActiveMQArtemis{}, &v2alpha3.ActiveMQArtemis{}},
+	{&v1beta1.ActiveMQArtemis{}, &v2alpha4.ActiveMQArtemis{}},
+	{&v1beta1.ActiveMQArtemis{}, &v2alpha5.ActiveMQArtemis{}},
+	{&v1beta1.ActiveMQArtemisAddress{}, &v2alpha1.ActiveMQArtemisAddress{}},
+	{&v1beta1.ActiveMQArtemisAddress{}, &v2alpha2.ActiveMQArtemisAddress{}},
+	{&v1beta1.ActiveMQArtemisAddress{}, &v2alpha3.ActiveMQArtemisAddress{}},
+	{&v1beta1.ActiveMQArtemisScaledown{}, &v2alpha1.ActiveMQArtemisScaledown{}},
+	{&v1beta1.ActiveMQArtemisSecurity{}, &v1alpha1.ActiveMQArtemisSecurity{}},
+}
+
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.3).NumElements(0, 2).MaxDepth(12).Funcs(
+		func(meta *metav1.ObjectMeta, c fuzz.Continue) {
+			c.Fuzz(&meta.Name)
+			c.Fuzz(&meta.Namespace)
+			c.Fuzz(&meta.Labels)
+			c.Fuzz(&meta.Annotations)
+		},
+		func(typeMeta *metav1.TypeMeta, c fuzz.Continue) {},
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+		},
+		func(u *unstructured.Unstructured, c fuzz.Continue) {
+			u.Object = map[string]interface{}{"kind": "Pod", "apiVersion": "v1", "value": c.RandString()}
+		},
+	)
+}
+
+func newOfType[T any](obj T) T {
+	return reflect.New(reflect.TypeOf(obj).Elem()).Interface().(T)
+}
+
+func TestSpokeHubSpokeRoundTrip(t *testing.T) {
+	for _, c := range conversions {
+		t.Run(fmt.Sprintf("%T", c.spoke), func(t *testing.T) {
+			fuzzer := newFuzzer(rand.Int63())
+			for i := 0; i < fuzzIterations; i++ {
+				spoke := newOfType(c.spoke)
+				fuzzer.Fuzz(spoke)
+
+				hub := newOfType(c.hub)
+				if !assert.NoError(t, spoke.ConvertTo(hub)) {
+					return
+				}
+				back := newOfType(c.spoke)
+				if !assert.NoError(t, back.ConvertFrom(hub)) {
+					return
+				}
+				if !equality.Semantic.DeepEqual(spoke, back) {
+					assert.Equal(t, spoke, back)
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestHubSpokeHubRoundTrip(t *testing.T) {
+	for _, c := range conversions {
+		t.Run(fmt.Sprintf("%T", c.spoke), func(t *testing.T) {
+			fuzzer := newFuzzer(rand.Int63())
+			for i := 0; i < fuzzIterations; i++ {
+				hub := newOfType(c.hub)
+				fuzzer.Fuzz(hub)
+
+				spoke := newOfType(c.spoke)
+				if !assert.NoError(t, spoke.ConvertFrom(hub)) {
+					return
+				}
+				back := newOfType(c.hub)
+				if !assert.NoError(t, spoke.ConvertTo(back)) {
+					return
+				}
+				// the status is written through the hub, only the spec has to survive
+				expected, actual := specAndMeta(hub), specAndMeta(back)
+				if !equality.Semantic.DeepEqual(expected, actual) {
+					assert.Equal(t, expected, actual)
+					return
+				}
+			}
+		})
+	}
+}
+
+func specAndMeta(hub ctrlconversion.Hub) []interface{} {
+	value := reflect.ValueOf(hub).Elem()
+	return []interface{}{value.FieldByName("ObjectMeta").Interface(), value.FieldByName("Spec").Interface()}
+}
+
+func TestUpdateThroughSpokeKeepsHubFields(t *testing.T) {
+	hub := &v1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "default"},
+		Spec: v1beta1.ActiveMQArtemisSpec{
+			BrokerProperties: []string{"globalMaxSize=512m"},
+			Env:              []corev1.EnvVar{{Name: "A", Value: "a"}},
+			AdminUser:        "admin",
+			DeploymentPlan:   v1beta1.DeploymentPlanType{Size: int32Of(2), Image: "image"},
+		},
+	}
+
+	spoke := &v2alpha5.ActiveMQArtemis{}
+	assert.NoError(t, spoke.ConvertFrom(hub))
+	assert.Contains(t, spoke.Annotations, conversion.DataAnnotation)
+	assert.Equal(t, "admin", spoke.Spec.AdminUser)
+
+	// a client that only knows the spoke changes one field and clears another
+	spoke.Spec.DeploymentPlan.Size = int32Of(3)
+	spoke.Spec.AdminUser = ""
+
+	updated := &v1beta1.ActiveMQArtemis{}
+	assert.NoError(t, spoke.ConvertTo(updated))
+	assert.NotContains(t, updated.Annotations, conversion.DataAnnotation)
+	assert.Equal(t, int32(3), *updated.Spec.DeploymentPlan.Size)
+	assert.Equal(t, "image", updated.Spec.DeploymentPlan.Image)
+	assert.Empty(t, updated.Spec.AdminUser)
+	assert.Equal(t, hub.Spec.BrokerProperties, updated.Spec.BrokerProperties)
+	assert.Equal(t, hub.Spec.Env, updated.Spec.Env)
+}
+
+func TestSpokeOnlyFieldsAreKeptOnTheHub(t *testing.T) {
+	multiplier := float32(1.5)
+	spoke := &v2alpha5.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "default"},
+		Spec: v2alpha5.ActiveMQArtemisSpec{
+			AddressSettings: v2alpha5.AddressSettingsType{
+				AddressSetting: []v2alpha5.AddressSettingType{{Match: "#", RedeliveryDelayMultiplier: &multiplier}},
+			},
+		},
+	}
+
+	hub := &v1beta1.ActiveMQArtemis{}
+	assert.NoError(t, spoke.ConvertTo(hub))
+	assert.Contains(t, hub.Annotations, conversion.DataAnnotation)
+	assert.Equal(t, "#", hub.Spec.AddressSettings.AddressSetting[0].Match)
+
+	back := &v2alpha5.ActiveMQArtemis{}
+	assert.NoError(t, back.ConvertFrom(hub))
+	assert.NotContains(t, back.Annotations, conversion.DataAnnotation)
+	assert.Equal(t, multiplier, *back.Spec.AddressSettings.AddressSetting[0].RedeliveryDelayMultiplier)
+}
+
+func int32Of(value int32) *int32 {
+	return &value
+}
@@ -1,16 +1,15 @@
 package v1alpha1
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/conversion"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"github.com/arkmq-org/activemq-artemis-operator/api/internal/conversion"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
-var log = logf.Log.WithName("v1alpha1conversion")
-
-func (r *ActiveMQArtemisSecurity) ConvertTo(dst conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemisSecurity) ConvertTo(dst ctrlconversion.Hub) error {
+	return conversion.Convert(r, dst.(*v1beta1.ActiveMQArtemisSecurity))
 }
 
-func (r *ActiveMQArtemisSecurity) ConvertFrom(src conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemisSecurity) ConvertFrom(src ctrlconversion.Hub) error {
+	return conversion.Convert(src.(*v1beta1.ActiveMQArtemisSecurity), r)
 }
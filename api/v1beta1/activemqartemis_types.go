@@ -38,6 +38,9 @@ type ActiveMQArtemisSpec struct {
 	// Password for standard broker user. It is required for connecting to the broker and the web console. If left empty, it will be generated.
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Admin Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	AdminPassword string `json:"adminPassword,omitempty"`
+	// Source of the password for standard broker user in an external secret store, used when adminPassword is empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Admin Password From"
+	AdminPasswordFrom *SecretSourceType `json:"adminPasswordFrom,omitempty"`
 	// Specifies the deployment plan
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Deployment Plan"
 	DeploymentPlan DeploymentPlanType `json:"deploymentPlan,omitempty"`
@@ -75,6 +78,138 @@ type ActiveMQArtemisSpec struct {
 	// Restricted deployment, mtls jolokia agent with RBAC
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Restricted"
 	Restricted *bool `json:"restricted,omitempty"`
+
+	// Specifies periodic rotation of the generated admin and cluster credentials
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credential Rotation"
+	CredentialRotation *CredentialRotationType `json:"credentialRotation,omitempty"`
+
+	// Specifies the codec used to mask the passwords the operator writes into the generated broker configuration
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Password Codec"
+	PasswordCodec *PasswordCodecType `json:"passwordCodec,omitempty"`
+
+	// Suspends the reconcile of parts of the deployment, the rest, including status, keeps being reconciled
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pause"
+	Pause *PauseType `json:"pause,omitempty"`
+
+	// Restricts the changes of the pod template, which restart the brokers, to recurring windows. Broker properties are still applied at once
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Maintenance Window"
+	MaintenanceWindow *MaintenanceWindowType `json:"maintenanceWindow,omitempty"`
+
+	// Checks the journal disk and address memory usage of each broker, the DiskPressure condition is set when a threshold is passed
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Disk Pressure"
+	DiskPressure *DiskPressureType `json:"diskPressure,omitempty"`
+}
+
+type DiskPressureType struct {
+	// Percentage of the journal disk in use above which a broker is under pressure, keep it below the max-disk-usage where the broker blocks producers. Defaults to 80
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Disk Usage Threshold",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	DiskUsageThreshold *int32 `json:"diskUsageThreshold,omitempty"`
+	// Percentage of the global-max-size held by the addresses above which a broker is under pressure, the addresses page at 100. Defaults to 90
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address Memory Usage Threshold",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	AddressMemoryUsageThreshold *int32 `json:"addressMemoryUsageThreshold,omitempty"`
+	// Period between two checks of the usage, for example 5m. Defaults to 1m
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Check Period",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	CheckPeriod *metav1.Duration `json:"checkPeriod,omitempty"`
+	// Grows the journal volume claims of the brokers when one of them is under disk pressure
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Auto Expand"
+	AutoExpand *AutoExpandType `json:"autoExpand,omitempty"`
+}
+
+type AutoExpandType struct {
+	// Storage added to the journal volume on each expansion, for example 1Gi
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Increment",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Increment string `json:"increment"`
+	// Size the journal volume does not grow beyond, for example 20Gi
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	MaxSize string `json:"maxSize"`
+}
+
+type MaintenanceWindowType struct {
+	// Cron schedule, in the standard five field format, of the start of each window, for example "0 2 * * 6"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Schedule string `json:"schedule"`
+	// Length of each window, for example 2h
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Duration",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Duration metav1.Duration `json:"duration"`
+	// IANA time zone of the schedule, for example Europe/Paris. Defaults to UTC
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Time Zone",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type PauseType struct {
+	// Leave the StatefulSet as deployed, no change of the spec rolls or scales the pods
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="StatefulSet",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	StatefulSet bool `json:"statefulSet,omitempty"`
+	// Leave the Services, Routes and Ingresses of the acceptors, connectors and console as deployed
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Exposure bool `json:"exposure,omitempty"`
+	// Leave the broker properties as deployed, changes to brokerProperties are not passed to the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Properties",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	BrokerProperties bool `json:"brokerProperties,omitempty"`
+	// Do not pass changes of ActiveMQArtemisAddress and ActiveMQArtemisSecurity CRs to the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address And Security",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	AddressAndSecurity bool `json:"addressAndSecurity,omitempty"`
+}
+
+type PasswordCodecType struct {
+	// Not supported, a custom codec can not decode the passwords the operator masks with the two way algorithm of the default codec. A CR that sets it is not valid
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Class Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ClassName string `json:"className,omitempty"`
+	// Key of a Secret, in the namespace of the CR, holding the key of the codec. The default key of the codec is used when not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key Secret"
+	KeySecretRef *corev1.SecretKeySelector `json:"keySecretRef,omitempty"`
+}
+
+// Reference to a secret value held outside of the CR, exactly one source must be set
+type SecretSourceType struct {
+	// Key of a Secret in any namespace the operator can read. The Secret must list the namespace of the referencing CR, or *, in its arkmq.org/secret-source-namespaces annotation
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret"
+	Secret *SecretSourceSecretType `json:"secret,omitempty"`
+	// File in the operator pod, typically mounted by the secrets store CSI driver, below the directory configured with the SECRET_SOURCE_FILE_ROOT env var of the operator
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="File"
+	File *SecretSourceFileType `json:"file,omitempty"`
+	// Key of a secret in a Vault compatible key value store
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault"
+	Vault *SecretSourceVaultType `json:"vault,omitempty"`
+}
+
+type SecretSourceSecretType struct {
+	// Namespace of the Secret, defaults to the namespace of the CR
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the Secret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// Key of the value in the Secret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Key string `json:"key"`
+}
+
+type SecretSourceFileType struct {
+	// Path of the file holding the value, relative to the configured file root
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Path",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path"`
+}
+
+type SecretSourceVaultType struct {
+	// Address of the Vault compatible server, for example https://vault.vault.svc:8200
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Address",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Address string `json:"address"`
+	// Path of the secret below /v1, including the mount, for example secret/data/broker for a version 2 key value engine
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Path",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path"`
+	// Key of the value in the secret
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Key string `json:"key"`
+	// Key of a Secret, in the namespace of the CR, holding the token used to authenticate with the server
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Token Secret"
+	TokenSecretRef corev1.SecretKeySelector `json:"tokenSecretRef"`
+}
+
+type CredentialRotationType struct {
+	// Cron schedule, in the standard five field format, on which new admin and cluster credentials are generated, for example "0 3 * * 0"
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Schedule string `json:"schedule,omitempty"`
 }
 
 type AddressSettingsType struct {
@@ -333,6 +468,9 @@ type DeploymentPlanType struct {
 	// If true enable the management role based access control
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Management RBAC Enabled",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	ManagementRBACEnabled bool `json:"managementRBACEnabled,omitempty"`
+	// Specifies the management operation groups granted to roles, rendered as securityRoles for the mops prefix. Needs managementRBACEnabled unless the broker is restricted
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Management RBAC"
+	ManagementRBAC []ManagementRBACGrantType `json:"managementRBAC,omitempty"`
 	// Specifies extra mounts
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extra Mounts"
 	ExtraMounts ExtraMountsType `json:"extraMounts,omitempty"`
@@ -391,6 +529,138 @@ type DeploymentPlanType struct {
 	// Specifies Extra Volume Claims Templates for the broker pods
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extra Volume Claims Templates"
 	ExtraVolumeClaimTemplates []VolumeClaimTemplate `json:"extraVolumeClaimTemplates,omitempty"`
+	// Specifies overrides of the broker pods by ordinal. The pods wait for the operator to apply them before they are scheduled
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ordinal Overrides"
+	OrdinalOverrides []OrdinalOverrideType `json:"ordinalOverrides,omitempty"`
+	// Specifies groups of brokers with their own size, resources, storage and scheduling. Each group is a StatefulSet whose brokers join the cluster of the deployment plan
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Groups"
+	BrokerGroups []BrokerGroupType `json:"brokerGroups,omitempty"`
+	// Specifies the placement of the brokers in the zones of the cluster, with spread constraints and anti-affinity generated per zone
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Zone Placement"
+	ZonePlacement *ZonePlacementType `json:"zonePlacement,omitempty"`
+	// Specifies containers to run next to the broker container, they can mount the volumes of the broker pod by name
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Sidecars"
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+	// Specifies init containers to run after the init container of the operator, they can mount the volumes of the broker pod by name
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Init Containers"
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Specifies the heap, garbage collector and diagnostics of the JVM of the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="JVM"
+	Jvm *JvmType `json:"jvm,omitempty"`
+}
+
+type JvmType struct {
+	// The initial and maximum heap as a percentage of the memory limit of the broker container, at most 80
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Heap Percentage",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	HeapPercentage *int32 `json:"heapPercentage,omitempty"`
+	// The initial heap size, like 512Mi
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Initial Heap Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	InitialHeapSize string `json:"initialHeapSize,omitempty"`
+	// The maximum heap size, like 2Gi
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Heap Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	MaxHeapSize string `json:"maxHeapSize,omitempty"`
+	// The garbage collector, one of G1, Parallel, Serial, Z or Shenandoah, G1 when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Garbage Collector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	GarbageCollector string `json:"garbageCollector,omitempty"`
+	// If true log the garbage collections to the standard output
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="GC Logging",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	GCLogging bool `json:"gcLogging,omitempty"`
+	// Specifies a continuous flight recording of the brokers to a volume
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Flight Recorder"
+	FlightRecorder *FlightRecorderType `json:"flightRecorder,omitempty"`
+	// If true write a heap dump to the data volume when the heap runs out of memory
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Heap Dump On Out Of Memory",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	HeapDumpOnOutOfMemory bool `json:"heapDumpOnOutOfMemory,omitempty"`
+	// Arguments added to the JVM after those of the operator, each a single argument of letters, digits and -_.,:=+/@%
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extra Args"
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+type FlightRecorderType struct {
+	// Name of the volume of the recordings, the data volume or a volume of extraVolumes or extraVolumeClaimTemplates
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Volume Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	VolumeName string `json:"volumeName"`
+	// How long the recording keeps its data, like 6h
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Age",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	MaxAge string `json:"maxAge,omitempty"`
+	// The size the recording keeps, like 250Mi
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	MaxSize string `json:"maxSize,omitempty"`
+	// The settings of the recording, default or profile, default when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Settings",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Settings string `json:"settings,omitempty"`
+}
+
+type ZonePlacementType struct {
+	// The zones of the brokers, the broker of ordinal n is placed in zone n modulo the number of zones
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Zones"
+	Zones []string `json:"zones"`
+	// The node label with the zone of a node, topology.kubernetes.io/zone when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Topology Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// Name of the ActiveMQArtemis in the namespace whose brokers are the HA pairs of these brokers, it needs a zonePlacement with the same topologyKey. The broker of an ordinal is placed in the first of its zones, from that of the ordinal, that is not the zone of the same ordinal of the pair
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="HA Pair Of",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	HAPairOf string `json:"haPairOf,omitempty"`
+}
+
+type BrokerGroupType struct {
+	// Name of the group, the StatefulSet of the group is named <name of the CR>-ss-<name>
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+	// The number of broker pods of the group, 1 when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:podCount"}
+	Size *int32 `json:"size,omitempty"`
+	// Specifies the minimum/maximum amount of compute resources of the brokers of the group, those of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Specifies the journal storage of the group, that of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Configurations"
+	Storage *BrokerGroupStorageType `json:"storage,omitempty"`
+	// Specifies the node selector of the group, that of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector"}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Specifies the tolerations of the group, those of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations"
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Specifies the affinity of the group, that of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Affinity Configurations"
+	Affinity *AffinityConfig `json:"affinity,omitempty"`
+	// Specifies the topology spread constraints of the group, those of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Topology Spread Constraints"
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+type BrokerGroupStorageType struct {
+	// The size of the journal volumes of the group
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Size",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Size string `json:"size,omitempty"`
+	// The storage class of the journal volumes of the group
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Class Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+type OrdinalOverrideType struct {
+	// The ordinal of the broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ordinal",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Ordinal int32 `json:"ordinal"`
+	// Specifies the minimum/maximum amount of compute resources of the broker container
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Env vars of the broker container that replace or add to spec.env
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Environment Variables"
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// Labels added to the pod, the keys `ActiveMQArtemis` and `application` are not allowed
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels"
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations added to the pod
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Annotations"
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Node selector terms that replace or add to spec.deploymentPlan.nodeSelector
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Selector",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:selector"}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations added to spec.deploymentPlan.tolerations
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tolerations"
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 }
 type VolumeClaimTemplate struct {
 	// Specifies the desired metadata of a volume claim
@@ -497,6 +767,63 @@ type StorageType struct {
 	// The storageClassName to be used in PVC
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Storage Class Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	StorageClassName string `json:"storageClassName,omitempty"`
+	// Populate the journal volume claims of new brokers from the snapshots of an ActiveMQArtemisBackup
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Restore From"
+	RestoreFrom *StorageRestoreType `json:"restoreFrom,omitempty"`
+}
+
+type StorageRestoreType struct {
+	// Name of the ActiveMQArtemisBackup, in the same namespace
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Backup",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Backup string `json:"backup"`
+	// Id of the backup to restore, the latest backup with every snapshot ready when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Backup Id",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	BackupID string `json:"backupId,omitempty"`
+}
+
+type ManagementRBACGrantType struct {
+	// The role the operation groups are granted to
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Role",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Role string `json:"role"`
+	// The scope of the grant, broker, address or queue. Default is broker
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scope",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Scope *ManagementRBACScope `json:"scope,omitempty"`
+	// The address or queue match for the address and queue scopes, the broker wildcard syntax applies
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Match",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Match string `json:"match,omitempty"`
+	// The operation groups, view, browse, send, manage or admin. Each group includes view
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Operations"
+	Operations []ManagementOperationGroup `json:"operations,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=broker;address;queue
+type ManagementRBACScope string
+
+var ManagementRBACScopes = struct {
+	Broker  ManagementRBACScope
+	Address ManagementRBACScope
+	Queue   ManagementRBACScope
+}{
+	Broker:  "broker",
+	Address: "address",
+	Queue:   "queue",
+}
+
+// +kubebuilder:validation:Enum=view;browse;send;manage;admin
+type ManagementOperationGroup string
+
+var ManagementOperationGroups = struct {
+	View   ManagementOperationGroup
+	Browse ManagementOperationGroup
+	Send   ManagementOperationGroup
+	Manage ManagementOperationGroup
+	Admin  ManagementOperationGroup
+}{
+	View:   "view",
+	Browse: "browse",
+	Send:   "send",
+	Manage: "manage",
+	Admin:  "admin",
 }
 
 // +kubebuilder:validation:Enum=ingress;route
@@ -726,6 +1053,194 @@ type ActiveMQArtemisStatus struct {
 
 	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Upgrade Status"
 	Upgrade UpgradeStatus `json:"upgrade,omitempty"`
+
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Credential Rotation Status"
+	CredentialRotation CredentialRotationStatus `json:"credentialRotation,omitempty"`
+
+	// Changes that applying the spec would make, reported while the arkmq.org/plan annotation is true
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Plan"
+	Plan *PlanStatus `json:"plan,omitempty"`
+
+	// Changes of the pod template that wait for the maintenance window, reported while a maintenance window is set
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Maintenance"
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+
+	// Broker properties keys whose change takes effect once the brokers restart, reported until the restart completes
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Properties Pending Restart"
+	PropertiesPendingRestart []string `json:"propertiesPendingRestart,omitempty"`
+
+	// Expansion of the persistent volume claims of the brokers, reported until each claim has the requested size
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Volume Expansion"
+	VolumeExpansion []VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// Journal disk and address memory usage of each broker, reported when diskPressure is set
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Disk Usage"
+	DiskUsage []BrokerDiskUsage `json:"diskUsage,omitempty"`
+
+	// Size of the journal volumes after an automatic expansion, above the size in the spec
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Expanded Storage Size"
+	ExpandedStorageSize string `json:"expandedStorageSize,omitempty"`
+
+	// The pods of each group of spec.deploymentPlan.brokerGroups
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Broker Groups"
+	BrokerGroups []BrokerGroupStatus `json:"brokerGroups,omitempty"`
+
+	// The zone of each broker of spec.deploymentPlan.zonePlacement
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Zones"
+	Zones []BrokerZoneStatus `json:"zones,omitempty"`
+}
+
+type BrokerZoneStatus struct {
+	// The ordinal of the broker
+	Ordinal int32 `json:"ordinal"`
+	// The zone the broker is placed in
+	PlacementZone string `json:"placementZone"`
+	// The zone of the broker pod once it is scheduled, from the topology label of its node, unknown when the node can not be read
+	Zone string `json:"zone,omitempty"`
+	// Why the broker is co-located or its zone is unknown, empty when it is in its placement zone apart from its HA pair
+	Message string `json:"message,omitempty"`
+}
+
+type BrokerGroupStatus struct {
+	// Name of the group
+	Name string `json:"name"`
+	// Name of the StatefulSet of the group
+	StatefulSet string `json:"statefulSet"`
+	// The number of broker pods of the group in the spec
+	Size int32 `json:"size"`
+	// The number of broker pods of the deployed StatefulSet of the group
+	Replicas int32 `json:"replicas,omitempty"`
+	// The pods of the group
+	PodStatus olm.DeploymentStatus `json:"podStatus"`
+	// The label selector of the pods of the group
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+type BrokerDiskUsage struct {
+	// Ordinal of the broker pod
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Ordinal"
+	Ordinal string `json:"ordinal"`
+
+	// Percentage of the journal disk in use
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Disk Usage"
+	DiskUsage int32 `json:"diskUsage"`
+
+	// Percentage of the journal disk in use at which the broker blocks producers
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Max Disk Usage"
+	MaxDiskUsage int32 `json:"maxDiskUsage,omitempty"`
+
+	// Size of the messages held in memory by the addresses
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Address Memory Usage"
+	AddressMemoryUsage string `json:"addressMemoryUsage,omitempty"`
+
+	// Size of the messages held in memory above which the addresses page
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Global Max Size"
+	GlobalMaxSize string `json:"globalMaxSize,omitempty"`
+
+	// True when the address memory usage reached the global max size and the addresses page
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Paging"
+	Paging bool `json:"paging,omitempty"`
+}
+
+type VolumeExpansionStatus struct {
+	// Name of the persistent volume claim
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Name"
+	Name string `json:"name"`
+
+	// Size requested by the spec
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Requested Size"
+	RequestedSize string `json:"requestedSize,omitempty"`
+
+	// Size of the bound volume
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Capacity"
+	Capacity string `json:"capacity,omitempty"`
+
+	// One of Pending, Resizing, FileSystemResizePending, Failed or Unsupported
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="State"
+	State string `json:"state,omitempty"`
+
+	// Why the expansion is failed or unsupported
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Message"
+	Message string `json:"message,omitempty"`
+}
+
+type MaintenanceStatus struct {
+	// Start of the next maintenance window
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Next Window"
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+
+	// Paths of the pod template fields whose change waits for a window
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pending Changes"
+	PendingChanges []string `json:"pendingChanges,omitempty"`
+
+	// Time since when changes have been waiting for a window
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Pending Since"
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+}
+
+type PlanStatus struct {
+	// Generation of the CR that the plan was computed for
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Observed Generation",xDescriptors="urn:alm:descriptor:text"
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// True when the pod template of the StatefulSet changes, which rolls the broker pods
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Rolls Pods",xDescriptors="urn:alm:descriptor:text"
+	RollsPods bool `json:"rollsPods,omitempty"`
+
+	// Resources that would be created, updated or deleted
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Resources"
+	Resources []PlannedResourceChange `json:"resources,omitempty"`
+
+	// Broker properties that would be added, changed or removed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Broker Properties"
+	BrokerProperties []PlannedPropertyChange `json:"brokerProperties,omitempty"`
+}
+
+type PlanAction string
+
+const (
+	PlanActionCreate PlanAction = "Create"
+	PlanActionUpdate PlanAction = "Update"
+	PlanActionDelete PlanAction = "Delete"
+)
+
+type PlannedResourceChange struct {
+	// Kind of the resource, like StatefulSet or Secret
+	Kind string `json:"kind"`
+
+	// Name of the resource
+	Name string `json:"name"`
+
+	// One of Create, Update or Delete
+	Action PlanAction `json:"action"`
+
+	// Paths of the fields that an update changes, values are not reported as they may be secret
+	Fields []string `json:"fields,omitempty"`
+}
+
+type PlannedPropertyChange struct {
+	// Properties file of the key, broker.properties or the file of an ordinal like broker-0.broker.properties
+	File string `json:"file"`
+
+	// The property key
+	Key string `json:"key"`
+
+	// One of Create, Update or Delete
+	Action PlanAction `json:"action"`
+}
+
+type CredentialRotationStatus struct {
+	// Time of the last completed rotation of the generated credentials
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Rotation Time"
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// Value of the rotate credentials annotation that was last acted on
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Trigger",xDescriptors="urn:alm:descriptor:text"
+	LastTrigger string `json:"lastTrigger,omitempty"`
+
+	// Admin user replaced by the last rotation, reported until it is removed from the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Retired User",xDescriptors="urn:alm:descriptor:text"
+	RetiredUser string `json:"retiredUser,omitempty"`
 }
 
 type VersionStatus struct {
@@ -821,6 +1336,19 @@ const (
 	ValidConditionInvalidCertSecretReason            = "InvalidCertSecret"
 	ValidConditionFailedDuplicateBrokerPropertiesKey = "DuplicateBrokerPropertiesKey"
 	ValidConditionInvalidInternalVarUsage            = "InvalidInternalVarUsage"
+	ValidConditionFailedInvalidManagementRBAC        = "InvalidManagementRBAC"
+	ValidConditionFailedInvalidCredentialRotation    = "InvalidCredentialRotation"
+	ValidConditionFailedInvalidMaintenanceWindow     = "InvalidMaintenanceWindow"
+	ValidConditionFailedSecretSourceReason           = "InvalidSecretSource"
+	ValidConditionFailedInvalidPasswordCodec         = "InvalidPasswordCodec"
+	ValidConditionFailedStorageShrink                = "StorageShrinkNotSupported"
+	ValidConditionFailedInvalidDiskPressure          = "InvalidDiskPressure"
+	ValidConditionFailedInvalidRestore               = "InvalidRestore"
+	ValidConditionFailedInvalidOrdinalOverrides      = "InvalidOrdinalOverrides"
+	ValidConditionFailedInvalidBrokerGroups          = "InvalidBrokerGroups"
+	ValidConditionFailedInvalidZonePlacement         = "InvalidZonePlacement"
+	ValidConditionFailedInvalidContainers            = "InvalidContainers"
+	ValidConditionFailedInvalidJvm                   = "InvalidJvm"
 
 	ReadyConditionType      = "Ready"
 	ReadyConditionReason    = "ResourceReady"
@@ -842,4 +1370,18 @@ const (
 
 	ReconcileBlockedType   = "ReconcileBlocked"
 	ReconcileBlockedReason = "AnnotationPresent"
+
+	ReconcilePausedType   = "ReconcilePaused"
+	ReconcilePausedReason = "PausedInSpec"
+
+	DiskPressureConditionType   = "DiskPressure"
+	DiskPressureThresholdReason = "UsageAboveThreshold"
+
+	CredentialsRotatedConditionType          = "CredentialsRotated"
+	CredentialsRotatedConditionRotatedReason = "Rotated"
+	CredentialsRotatedConditionFailedReason  = "RotationFailed"
+
+	OrdinalOverridesAppliedConditionType             = "OrdinalOverridesApplied"
+	OrdinalOverridesAppliedConditionAppliedReason    = "Applied"
+	OrdinalOverridesAppliedConditionNotAppliedReason = "NotApplied"
 )
@@ -0,0 +1,137 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ActiveMQArtemisBackupSpec defines the desired state of ActiveMQArtemisBackup
+type ActiveMQArtemisBackupSpec struct {
+	// Name of the ActiveMQArtemis CR, in the same namespace, whose journal volumes are backed up
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Name"
+	BrokerName string `json:"brokerName"`
+	// Cron schedule of the backups in the standard five field format, like "0 2 * * *", in UTC. A single backup is taken when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule"
+	Schedule string `json:"schedule,omitempty"`
+	// Name of the VolumeSnapshotClass of the snapshots, the default class of the CSI driver when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Volume Snapshot Class Name"
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	// Stop the acceptors of the brokers with Jolokia until the snapshots are cut, so that clients do not write to the journal while it is captured
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Quiesce",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Quiesce bool `json:"quiesce,omitempty"`
+	// The backups to keep, older backups and their snapshots are deleted
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retention"
+	Retention *BackupRetentionType `json:"retention,omitempty"`
+}
+
+type BackupRetentionType struct {
+	// Number of most recent backups to keep
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Count",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MaxCount *int32 `json:"maxCount,omitempty"`
+	// Age after which a backup is deleted, like 168h
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Age"
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// ActiveMQArtemisBackupStatus defines the observed state of ActiveMQArtemisBackup
+type ActiveMQArtemisBackupStatus struct {
+	// Current state of the resource
+	// Conditions represent the latest available observations of an object's state
+	//+optional
+	//+patchMergeKey=type
+	//+patchStrategy=merge
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+
+	// Time of the last backup
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Last Backup Time"
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// Time of the next scheduled backup
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Next Backup Time"
+	NextBackupTime *metav1.Time `json:"nextBackupTime,omitempty"`
+
+	// Time the acceptors of the brokers were stopped for the last backup, until the snapshots are cut
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Quiesced Since"
+	QuiescedSince *metav1.Time `json:"quiescedSince,omitempty"`
+
+	// The snapshots of the kept backups
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Snapshots"
+	Snapshots []BackupSnapshotStatus `json:"snapshots,omitempty"`
+}
+
+type BackupSnapshotStatus struct {
+	// Name of the VolumeSnapshot
+	Name string `json:"name"`
+	// Id of the backup the snapshot belongs to, the UTC time it was taken like 20060102T150405Z
+	BackupID string `json:"backupId"`
+	// Ordinal of the broker
+	Ordinal int32 `json:"ordinal"`
+	// Name of the journal volume claim of the broker
+	ClaimName string `json:"claimName"`
+	// The snapshot is cut, the journal can change again
+	Cut bool `json:"cut,omitempty"`
+	// The snapshot can be restored
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+	// The minimum size of a volume restored from the snapshot
+	RestoreSize string `json:"restoreSize,omitempty"`
+	// The error of the snapshot controller
+	Error string `json:"error,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:storageversion
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=activemqartemisbackups,shortName=aab
+//+kubebuilder:printcolumn:name="Broker",type=string,JSONPath=`.spec.brokerName`
+//+kubebuilder:printcolumn:name="Last Backup",type=date,JSONPath=`.status.lastBackupTime`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// Backs up the journal volumes of the brokers with CSI volume snapshots
+// +operator-sdk:csv:customresourcedefinitions:displayName="ActiveMQ Artemis Backup"
+type ActiveMQArtemisBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ActiveMQArtemisBackupSpec   `json:"spec,omitempty"`
+	Status ActiveMQArtemisBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ActiveMQArtemisBackupList contains a list of ActiveMQArtemisBackup
+type ActiveMQArtemisBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ActiveMQArtemisBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ActiveMQArtemisBackup{}, &ActiveMQArtemisBackupList{})
+}
+
+const (
+	BackupSnapshotsReadyReason    = "SnapshotsReady"
+	BackupSnapshotsPendingReason  = "SnapshotsPending"
+	BackupSnapshotFailedReason    = "SnapshotFailed"
+	BackupBrokerNotFoundReason    = "BrokerNotFound"
+	BackupNoPersistenceReason     = "PersistenceDisabled"
+	BackupInvalidSpecReason       = "InvalidSpec"
+	BackupQuiesceFailedReason     = "QuiesceFailed"
+	BackupVolumeSnapshotApiReason = "VolumeSnapshotApiUnavailable"
+)
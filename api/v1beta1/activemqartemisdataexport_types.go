@@ -0,0 +1,143 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=Export;Import
+type DataExportAction string
+
+const (
+	DataExportActionExport DataExportAction = "Export"
+	DataExportActionImport DataExportAction = "Import"
+)
+
+// ActiveMQArtemisDataExportSpec defines the desired state of ActiveMQArtemisDataExport
+type ActiveMQArtemisDataExportSpec struct {
+	// Name of the ActiveMQArtemis CR, in the same namespace, of the broker
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Name"
+	BrokerName string `json:"brokerName"`
+	// Ordinal of the broker. An export reads the journal volume of a stopped or scaled down broker, an import sends to a running broker
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ordinal",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Ordinal int32 `json:"ordinal,omitempty"`
+	// Export the messages of the journal to the XML file of the target, or Import the messages of the file. Default is Export
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Action"
+	Action DataExportAction `json:"action,omitempty"`
+	// The volume of the XML file
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Target"
+	Target DataExportTargetType `json:"target"`
+	// Name of the acceptor of spec.acceptors an import connects to, it must accept the core protocol. Port 61616 when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Acceptor",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Acceptor string `json:"acceptor,omitempty"`
+	// Specifies the minimum/maximum amount of compute resources required/allowed by the job
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+type DataExportTargetType struct {
+	// Name of the persistent volume claim that holds the file, a claim on an object store CSI driver stands in for an object store
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Claim Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ClaimName string `json:"claimName"`
+	// Path of the file in the volume, <name of the CR>.xml when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="File",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	File string `json:"file,omitempty"`
+}
+
+// ActiveMQArtemisDataExportStatus defines the observed state of ActiveMQArtemisDataExport
+type ActiveMQArtemisDataExportStatus struct {
+	// Current state of the resource
+	// Conditions represent the latest available observations of an object's state
+	//+optional
+	//+patchMergeKey=type
+	//+patchStrategy=merge
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+
+	// Name of the job that runs the artemis data tool
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Job Name"
+	JobName string `json:"jobName,omitempty"`
+
+	// Time the job started
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Start Time"
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time the job completed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Completion Time"
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Number of messages of each queue in the file
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Queue Message Counts"
+	QueueMessageCounts []QueueMessageCount `json:"queueMessageCounts,omitempty"`
+
+	// Number of messages in the file
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Total Messages"
+	TotalMessages int64 `json:"totalMessages,omitempty"`
+}
+
+type QueueMessageCount struct {
+	// Name of the queue
+	Queue string `json:"queue"`
+	// Number of messages
+	Messages int64 `json:"messages"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:storageversion
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=activemqartemisdataexports,shortName=aade
+//+kubebuilder:printcolumn:name="Broker",type=string,JSONPath=`.spec.brokerName`
+//+kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Messages",type=integer,JSONPath=`.status.totalMessages`
+
+// Exports the messages of a broker journal to an XML file, or imports them into a broker, with the artemis data tools
+// +operator-sdk:csv:customresourcedefinitions:displayName="ActiveMQ Artemis Data Export"
+type ActiveMQArtemisDataExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ActiveMQArtemisDataExportSpec   `json:"spec,omitempty"`
+	Status ActiveMQArtemisDataExportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ActiveMQArtemisDataExportList contains a list of ActiveMQArtemisDataExport
+type ActiveMQArtemisDataExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ActiveMQArtemisDataExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ActiveMQArtemisDataExport{}, &ActiveMQArtemisDataExportList{})
+}
+
+const (
+	DataExportPendingReason              = "Pending"
+	DataExportRunningReason              = "Running"
+	DataExportSucceededReason            = "Succeeded"
+	DataExportFailedReason               = "Failed"
+	DataExportInvalidSpecReason          = "InvalidSpec"
+	DataExportBrokerNotFoundReason       = "BrokerNotFound"
+	DataExportBrokerRunningReason        = "BrokerRunning"
+	DataExportBrokerNotReadyReason       = "BrokerNotReady"
+	DataExportOrdinalNotScaledDownReason = "OrdinalNotScaledDown"
+)
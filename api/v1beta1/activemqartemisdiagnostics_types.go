@@ -0,0 +1,133 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Enum=ThreadDump;HeapHistogram;FlightRecording
+type DiagnosticsAction string
+
+const (
+	DiagnosticsActionThreadDump      DiagnosticsAction = "ThreadDump"
+	DiagnosticsActionHeapHistogram   DiagnosticsAction = "HeapHistogram"
+	DiagnosticsActionFlightRecording DiagnosticsAction = "FlightRecording"
+)
+
+// ActiveMQArtemisDiagnosticsSpec defines the desired state of ActiveMQArtemisDiagnostics
+type ActiveMQArtemisDiagnosticsSpec struct {
+	// Name of the ActiveMQArtemis CR, in the same namespace, of the brokers
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Name"
+	BrokerName string `json:"brokerName"`
+	// Name of a group of spec.deploymentPlan.brokerGroups of the brokers, the brokers of the deployment plan when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Broker Group"
+	BrokerGroup string `json:"brokerGroup,omitempty"`
+	// Ordinals of the brokers, all the brokers of the deployment plan or of the group when empty
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ordinals"
+	Ordinals []int32 `json:"ordinals,omitempty"`
+	// ThreadDump or HeapHistogram of the JVM into a ConfigMap, or a FlightRecording into the flight recorder volume of spec.deploymentPlan.jvm of the broker. Default is ThreadDump
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Action"
+	Action DiagnosticsAction `json:"action,omitempty"`
+	// Duration of a FlightRecording, like 5m. Default is 1m, at most 1h
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Duration"
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// ActiveMQArtemisDiagnosticsStatus defines the observed state of ActiveMQArtemisDiagnostics
+type ActiveMQArtemisDiagnosticsStatus struct {
+	// Current state of the resource
+	// Conditions represent the latest available observations of an object's state
+	//+optional
+	//+patchMergeKey=type
+	//+patchStrategy=merge
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Conditions",xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
+
+	// Time the diagnostics started
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Start Time"
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time the diagnostics completed
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Completion Time"
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The output of each broker
+	//+operator-sdk:csv:customresourcedefinitions:type=status,displayName="Results"
+	Results []DiagnosticsResult `json:"results,omitempty"`
+}
+
+type DiagnosticsResult struct {
+	// Group of the broker, empty for a broker of the deployment plan
+	BrokerGroup string `json:"brokerGroup,omitempty"`
+	// Ordinal of the broker
+	Ordinal int32 `json:"ordinal"`
+	// Name of the ConfigMap of a thread dump or a heap histogram
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Key of the output in the ConfigMap
+	Key string `json:"key,omitempty"`
+	// The output is cut to the size limit of a ConfigMap
+	Truncated bool `json:"truncated,omitempty"`
+	// Name of the volume claim of a flight recording, empty for a volume of spec.deploymentPlan.extraVolumes
+	ClaimName string `json:"claimName,omitempty"`
+	// Name of the volume of a flight recording in the broker pod
+	VolumeName string `json:"volumeName,omitempty"`
+	// Path of a flight recording in the volume
+	Path string `json:"path,omitempty"`
+	// The error of the diagnostic command of the broker
+	Error string `json:"error,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:storageversion
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=activemqartemisdiagnostics,shortName=aadi
+//+kubebuilder:printcolumn:name="Broker",type=string,JSONPath=`.spec.brokerName`
+//+kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+
+// Captures a thread dump, a heap histogram or a flight recording of the brokers with the DiagnosticCommand MBean of their JVM
+// +operator-sdk:csv:customresourcedefinitions:displayName="ActiveMQ Artemis Diagnostics"
+type ActiveMQArtemisDiagnostics struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ActiveMQArtemisDiagnosticsSpec   `json:"spec,omitempty"`
+	Status ActiveMQArtemisDiagnosticsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ActiveMQArtemisDiagnosticsList contains a list of ActiveMQArtemisDiagnostics
+type ActiveMQArtemisDiagnosticsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ActiveMQArtemisDiagnostics `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ActiveMQArtemisDiagnostics{}, &ActiveMQArtemisDiagnosticsList{})
+}
+
+const (
+	DiagnosticsRunningReason        = "Running"
+	DiagnosticsSucceededReason      = "Succeeded"
+	DiagnosticsFailedReason         = "Failed"
+	DiagnosticsInvalidSpecReason    = "InvalidSpec"
+	DiagnosticsBrokerNotFoundReason = "BrokerNotFound"
+	DiagnosticsBrokerNotReadyReason = "BrokerNotReady"
+)
@@ -80,3 +80,6 @@ type ActiveMQArtemisScaledownList struct {
 func init() {
 	SchemeBuilder.Register(&ActiveMQArtemisScaledown{}, &ActiveMQArtemisScaledownList{})
 }
+
+func (r *ActiveMQArtemisScaledown) Hub() {
+}
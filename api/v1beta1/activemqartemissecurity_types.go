@@ -40,6 +40,9 @@ type ActiveMQArtemisSecuritySpec struct {
 	// Apply this security config to the broker crs in the current namespace. A value of * or empty string means applying to all broker crs. Default apply to all broker crs
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Apply to Broker CR Names"
 	ApplyToCrNames []string `json:"applyToCrNames,omitempty"`
+	// Write the passwords of the properties login module users as one way hashes of the default codec, in the ENC() form
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Mask Passwords",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	MaskPasswords *bool `json:"maskPasswords,omitempty"`
 }
 
 type LoginModulesType struct {
@@ -52,6 +55,9 @@ type LoginModulesType struct {
 	// Specifies the Keycloak login modules
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Keycloak Login Modules"
 	KeycloakLoginModules []KeycloakLoginModuleType `json:"keycloakLoginModules,omitempty"`
+	// Specifies the OpenID Connect login modules, validating the access tokens issued by any OIDC compliant provider that clients present as their password
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OIDC Login Modules"
+	OIDCLoginModules []OIDCLoginModuleType `json:"oidcLoginModules,omitempty"`
 }
 
 type PropertiesLoginModuleType struct {
@@ -70,6 +76,9 @@ type UserType struct {
 	// Password to be defined in properties login module
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	Password *string `json:"password,omitempty"`
+	// Source of the password in an external secret store, used when password is not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Password From"
+	PasswordFrom *SecretSourceType `json:"passwordFrom,omitempty"`
 	// Roles to be defined in properties login module
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Roles"
 	Roles []string `json:"roles,omitempty"`
@@ -163,15 +172,24 @@ type KeycloakModuleConfigurationType struct {
 	// Truststore password
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TrustStore Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	TrustStorePassword *string `json:"trustStorePassword,omitempty"`
+	// Source of the truststore password in an external secret store, used when trustStorePassword is not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="TrustStore Password From"
+	TrustStorePasswordFrom *SecretSourceType `json:"trustStorePasswordFrom,omitempty"`
 	// Path of a client keystore
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client KeyStore",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	ClientKeyStore *string `json:"clientKeyStore,omitempty"`
 	// Client keystore password
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client KeyStore Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	ClientKeyStorePassword *string `json:"clientKeyStorePassword,omitempty"`
+	// Source of the client keystore password in an external secret store, used when clientKeyStorePassword is not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client KeyStore Password From"
+	ClientKeyStorePasswordFrom *SecretSourceType `json:"clientKeyStorePasswordFrom,omitempty"`
 	// Client key password
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client Key Password",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:password"}
 	ClientKeyPassword *string `json:"clientKeyPassword,omitempty"`
+	// Source of the client key password in an external secret store, used when clientKeyPassword is not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client Key Password From"
+	ClientKeyPasswordFrom *SecretSourceType `json:"clientKeyPasswordFrom,omitempty"`
 	// If always refresh token
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Always Refresh Token",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	AlwaysRefreshToken *bool `json:"alwaysRefreshToken,omitempty"`
@@ -225,6 +243,57 @@ type KeycloakModuleConfigurationType struct {
 	Scope *string `json:"scope,omitempty"`
 }
 
+type OIDCLoginModuleType struct {
+	// Name for OIDCLoginModule, referenced from the security domains
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name,omitempty"`
+	// URL of the token issuer, the .well-known/openid-configuration discovery document is resolved relative to it
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Issuer Url",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	IssuerUrl string `json:"issuerUrl,omitempty"`
+	// Audience that must be present in the aud claim of a token, no audience check when not set
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Audience",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Audience *string `json:"audience,omitempty"`
+	// Client id the management console uses for the authorization code flow
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client Id",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ClientId *string `json:"clientId,omitempty"`
+	// The OAuth2 scope requested by the management console, default is openid
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scope",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Scope *string `json:"scope,omitempty"`
+	// Redirect URI of the management console, it must be registered with the provider
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Redirect Uri",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RedirectUri *string `json:"redirectUri,omitempty"`
+	// Path of the token claim to populate the UserPrincipal name with, default is sub
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Principal Claim",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	PrincipalClaim *string `json:"principalClaim,omitempty"`
+	// Path of the token claim holding the roles, for example realm_access.roles
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Roles Claim",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RolesClaim *string `json:"rolesClaim,omitempty"`
+	// Specifies how values of the roles claim map to broker roles, unmapped values are used as they are
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Role Mappings"
+	RoleMappings []OIDCRoleMappingType `json:"roleMappings,omitempty"`
+	// Specifies caching of the provider public keys retrieved from the jwks_uri
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Jwks Cache"
+	JwksCache OIDCJwksCacheType `json:"jwksCache,omitempty"`
+}
+
+type OIDCRoleMappingType struct {
+	// Value of the roles claim
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Claim",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Claim string `json:"claim"`
+	// Broker roles granted for the claim value
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Roles"
+	Roles []string `json:"roles,omitempty"`
+}
+
+type OIDCJwksCacheType struct {
+	// Time in seconds the public keys are cached before they are fetched again, default is 3600
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cache Ttl",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	CacheTtl *int64 `json:"cacheTtl,omitempty"`
+	// Minimum interval in seconds between two requests for public keys on an unknown key id, default is 10
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Min Time Between Requests",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MinTimeBetweenRequests *int64 `json:"minTimeBetweenRequests,omitempty"`
+}
+
 type KeyValueType struct {
 	// The regular expression to match the Redirect URI
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
@@ -220,6 +220,354 @@ func (in *ActiveMQArtemisAddressStatus) DeepCopy() *ActiveMQArtemisAddressStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisBackup) DeepCopyInto(out *ActiveMQArtemisBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisBackup.
+func (in *ActiveMQArtemisBackup) DeepCopy() *ActiveMQArtemisBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisBackupList) DeepCopyInto(out *ActiveMQArtemisBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ActiveMQArtemisBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisBackupList.
+func (in *ActiveMQArtemisBackupList) DeepCopy() *ActiveMQArtemisBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisBackupSpec) DeepCopyInto(out *ActiveMQArtemisBackupSpec) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetentionType)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisBackupSpec.
+func (in *ActiveMQArtemisBackupSpec) DeepCopy() *ActiveMQArtemisBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisBackupStatus) DeepCopyInto(out *ActiveMQArtemisBackupStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextBackupTime != nil {
+		in, out := &in.NextBackupTime, &out.NextBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.QuiescedSince != nil {
+		in, out := &in.QuiescedSince, &out.QuiescedSince
+		*out = (*in).DeepCopy()
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]BackupSnapshotStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisBackupStatus.
+func (in *ActiveMQArtemisBackupStatus) DeepCopy() *ActiveMQArtemisBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDataExport) DeepCopyInto(out *ActiveMQArtemisDataExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDataExport.
+func (in *ActiveMQArtemisDataExport) DeepCopy() *ActiveMQArtemisDataExport {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDataExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisDataExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDataExportList) DeepCopyInto(out *ActiveMQArtemisDataExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ActiveMQArtemisDataExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDataExportList.
+func (in *ActiveMQArtemisDataExportList) DeepCopy() *ActiveMQArtemisDataExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDataExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisDataExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDataExportSpec) DeepCopyInto(out *ActiveMQArtemisDataExportSpec) {
+	*out = *in
+	out.Target = in.Target
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDataExportSpec.
+func (in *ActiveMQArtemisDataExportSpec) DeepCopy() *ActiveMQArtemisDataExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDataExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDataExportStatus) DeepCopyInto(out *ActiveMQArtemisDataExportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.QueueMessageCounts != nil {
+		in, out := &in.QueueMessageCounts, &out.QueueMessageCounts
+		*out = make([]QueueMessageCount, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDataExportStatus.
+func (in *ActiveMQArtemisDataExportStatus) DeepCopy() *ActiveMQArtemisDataExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDataExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDiagnostics) DeepCopyInto(out *ActiveMQArtemisDiagnostics) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDiagnostics.
+func (in *ActiveMQArtemisDiagnostics) DeepCopy() *ActiveMQArtemisDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisDiagnostics) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDiagnosticsList) DeepCopyInto(out *ActiveMQArtemisDiagnosticsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ActiveMQArtemisDiagnostics, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDiagnosticsList.
+func (in *ActiveMQArtemisDiagnosticsList) DeepCopy() *ActiveMQArtemisDiagnosticsList {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDiagnosticsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActiveMQArtemisDiagnosticsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDiagnosticsSpec) DeepCopyInto(out *ActiveMQArtemisDiagnosticsSpec) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDiagnosticsSpec.
+func (in *ActiveMQArtemisDiagnosticsSpec) DeepCopy() *ActiveMQArtemisDiagnosticsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDiagnosticsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMQArtemisDiagnosticsStatus) DeepCopyInto(out *ActiveMQArtemisDiagnosticsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]DiagnosticsResult, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisDiagnosticsStatus.
+func (in *ActiveMQArtemisDiagnosticsStatus) DeepCopy() *ActiveMQArtemisDiagnosticsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMQArtemisDiagnosticsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisList) DeepCopyInto(out *ActiveMQArtemisList) {
 	*out = *in
@@ -419,6 +767,11 @@ func (in *ActiveMQArtemisSecuritySpec) DeepCopyInto(out *ActiveMQArtemisSecurity
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MaskPasswords != nil {
+		in, out := &in.MaskPasswords, &out.MaskPasswords
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisSecuritySpec.
@@ -456,6 +809,11 @@ func (in *ActiveMQArtemisSecurityStatus) DeepCopy() *ActiveMQArtemisSecurityStat
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisSpec) DeepCopyInto(out *ActiveMQArtemisSpec) {
 	*out = *in
+	if in.AdminPasswordFrom != nil {
+		in, out := &in.AdminPasswordFrom, &out.AdminPasswordFrom
+		*out = new(SecretSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	in.DeploymentPlan.DeepCopyInto(&out.DeploymentPlan)
 	if in.Acceptors != nil {
 		in, out := &in.Acceptors, &out.Acceptors
@@ -498,6 +856,31 @@ func (in *ActiveMQArtemisSpec) DeepCopyInto(out *ActiveMQArtemisSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.CredentialRotation != nil {
+		in, out := &in.CredentialRotation, &out.CredentialRotation
+		*out = new(CredentialRotationType)
+		**out = **in
+	}
+	if in.PasswordCodec != nil {
+		in, out := &in.PasswordCodec, &out.PasswordCodec
+		*out = new(PasswordCodecType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(PauseType)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowType)
+		**out = **in
+	}
+	if in.DiskPressure != nil {
+		in, out := &in.DiskPressure, &out.DiskPressure
+		*out = new(DiskPressureType)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisSpec.
@@ -528,6 +911,44 @@ func (in *ActiveMQArtemisStatus) DeepCopyInto(out *ActiveMQArtemisStatus) {
 	}
 	out.Version = in.Version
 	out.Upgrade = in.Upgrade
+	in.CredentialRotation.DeepCopyInto(&out.CredentialRotation)
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(PlanStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropertiesPendingRestart != nil {
+		in, out := &in.PropertiesPendingRestart, &out.PropertiesPendingRestart
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeExpansion != nil {
+		in, out := &in.VolumeExpansion, &out.VolumeExpansion
+		*out = make([]VolumeExpansionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskUsage != nil {
+		in, out := &in.DiskUsage, &out.DiskUsage
+		*out = make([]BrokerDiskUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.BrokerGroups != nil {
+		in, out := &in.BrokerGroups, &out.BrokerGroups
+		*out = make([]BrokerGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]BrokerZoneStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMQArtemisStatus.
@@ -1008,6 +1429,76 @@ func (in *AuthorisationConfigType) DeepCopy() *AuthorisationConfigType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoExpandType) DeepCopyInto(out *AutoExpandType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoExpandType.
+func (in *AutoExpandType) DeepCopy() *AutoExpandType {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoExpandType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionType) DeepCopyInto(out *BackupRetentionType) {
+	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionType.
+func (in *BackupRetentionType) DeepCopy() *BackupRetentionType {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSnapshotStatus) DeepCopyInto(out *BackupSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSnapshotStatus.
+func (in *BackupSnapshotStatus) DeepCopy() *BackupSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerDiskUsage) DeepCopyInto(out *BrokerDiskUsage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerDiskUsage.
+func (in *BrokerDiskUsage) DeepCopy() *BrokerDiskUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerDiskUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerDomainType) DeepCopyInto(out *BrokerDomainType) {
 	*out = *in
@@ -1035,6 +1526,93 @@ func (in *BrokerDomainType) DeepCopy() *BrokerDomainType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerGroupStatus) DeepCopyInto(out *BrokerGroupStatus) {
+	*out = *in
+	in.PodStatus.DeepCopyInto(&out.PodStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerGroupStatus.
+func (in *BrokerGroupStatus) DeepCopy() *BrokerGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerGroupStorageType) DeepCopyInto(out *BrokerGroupStorageType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerGroupStorageType.
+func (in *BrokerGroupStorageType) DeepCopy() *BrokerGroupStorageType {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerGroupStorageType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerGroupType) DeepCopyInto(out *BrokerGroupType) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(BrokerGroupStorageType)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(AffinityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerGroupType.
+func (in *BrokerGroupType) DeepCopy() *BrokerGroupType {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerGroupType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerSecuritySettingType) DeepCopyInto(out *BrokerSecuritySettingType) {
 	*out = *in
@@ -1057,6 +1635,21 @@ func (in *BrokerSecuritySettingType) DeepCopy() *BrokerSecuritySettingType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerZoneStatus) DeepCopyInto(out *BrokerZoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerZoneStatus.
+func (in *BrokerZoneStatus) DeepCopy() *BrokerZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorConfigType) DeepCopyInto(out *ConnectorConfigType) {
 	*out = *in
@@ -1202,6 +1795,55 @@ func (in *ConsoleType) DeepCopy() *ConsoleType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialRotationStatus) DeepCopyInto(out *CredentialRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialRotationStatus.
+func (in *CredentialRotationStatus) DeepCopy() *CredentialRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialRotationType) DeepCopyInto(out *CredentialRotationType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialRotationType.
+func (in *CredentialRotationType) DeepCopy() *CredentialRotationType {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialRotationType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataExportTargetType) DeepCopyInto(out *DataExportTargetType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataExportTargetType.
+func (in *DataExportTargetType) DeepCopy() *DataExportTargetType {
+	if in == nil {
+		return nil
+	}
+	out := new(DataExportTargetType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultAccessType) DeepCopyInto(out *DefaultAccessType) {
 	*out = *in
@@ -1246,7 +1888,7 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 		**out = **in
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	out.Storage = in.Storage
+	in.Storage.DeepCopyInto(&out.Storage)
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
 		*out = make([]v1.TopologySpreadConstraint, len(*in))
@@ -1254,6 +1896,13 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ManagementRBAC != nil {
+		in, out := &in.ManagementRBAC, &out.ManagementRBAC
+		*out = make([]ManagementRBACGrantType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.ExtraMounts.DeepCopyInto(&out.ExtraMounts)
 	if in.Clustered != nil {
 		in, out := &in.Clustered, &out.Clustered
@@ -1351,14 +2000,102 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.OrdinalOverrides != nil {
+		in, out := &in.OrdinalOverrides, &out.OrdinalOverrides
+		*out = make([]OrdinalOverrideType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BrokerGroups != nil {
+		in, out := &in.BrokerGroups, &out.BrokerGroups
+		*out = make([]BrokerGroupType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZonePlacement != nil {
+		in, out := &in.ZonePlacement, &out.ZonePlacement
+		*out = new(ZonePlacementType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Jvm != nil {
+		in, out := &in.Jvm, &out.Jvm
+		*out = new(JvmType)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentPlanType.
+func (in *DeploymentPlanType) DeepCopy() *DeploymentPlanType {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentPlanType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiagnosticsResult) DeepCopyInto(out *DiagnosticsResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsResult.
+func (in *DiagnosticsResult) DeepCopy() *DiagnosticsResult {
+	if in == nil {
+		return nil
+	}
+	out := new(DiagnosticsResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskPressureType) DeepCopyInto(out *DiskPressureType) {
+	*out = *in
+	if in.DiskUsageThreshold != nil {
+		in, out := &in.DiskUsageThreshold, &out.DiskUsageThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AddressMemoryUsageThreshold != nil {
+		in, out := &in.AddressMemoryUsageThreshold, &out.AddressMemoryUsageThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CheckPeriod != nil {
+		in, out := &in.CheckPeriod, &out.CheckPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AutoExpand != nil {
+		in, out := &in.AutoExpand, &out.AutoExpand
+		*out = new(AutoExpandType)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentPlanType.
-func (in *DeploymentPlanType) DeepCopy() *DeploymentPlanType {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskPressureType.
+func (in *DiskPressureType) DeepCopy() *DiskPressureType {
 	if in == nil {
 		return nil
 	}
-	out := new(DeploymentPlanType)
+	out := new(DiskPressureType)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1403,6 +2140,21 @@ func (in *ExtraMountsType) DeepCopy() *ExtraMountsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlightRecorderType) DeepCopyInto(out *FlightRecorderType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlightRecorderType.
+func (in *FlightRecorderType) DeepCopy() *FlightRecorderType {
+	if in == nil {
+		return nil
+	}
+	out := new(FlightRecorderType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GuestLoginModuleType) DeepCopyInto(out *GuestLoginModuleType) {
 	*out = *in
@@ -1428,6 +2180,36 @@ func (in *GuestLoginModuleType) DeepCopy() *GuestLoginModuleType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JvmType) DeepCopyInto(out *JvmType) {
+	*out = *in
+	if in.HeapPercentage != nil {
+		in, out := &in.HeapPercentage, &out.HeapPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FlightRecorder != nil {
+		in, out := &in.FlightRecorder, &out.FlightRecorder
+		*out = new(FlightRecorderType)
+		**out = **in
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JvmType.
+func (in *JvmType) DeepCopy() *JvmType {
+	if in == nil {
+		return nil
+	}
+	out := new(JvmType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyValueType) DeepCopyInto(out *KeyValueType) {
 	*out = *in
@@ -1579,6 +2361,11 @@ func (in *KeycloakModuleConfigurationType) DeepCopyInto(out *KeycloakModuleConfi
 		*out = new(string)
 		**out = **in
 	}
+	if in.TrustStorePasswordFrom != nil {
+		in, out := &in.TrustStorePasswordFrom, &out.TrustStorePasswordFrom
+		*out = new(SecretSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClientKeyStore != nil {
 		in, out := &in.ClientKeyStore, &out.ClientKeyStore
 		*out = new(string)
@@ -1589,11 +2376,21 @@ func (in *KeycloakModuleConfigurationType) DeepCopyInto(out *KeycloakModuleConfi
 		*out = new(string)
 		**out = **in
 	}
+	if in.ClientKeyStorePasswordFrom != nil {
+		in, out := &in.ClientKeyStorePasswordFrom, &out.ClientKeyStorePasswordFrom
+		*out = new(SecretSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClientKeyPassword != nil {
 		in, out := &in.ClientKeyPassword, &out.ClientKeyPassword
 		*out = new(string)
 		**out = **in
 	}
+	if in.ClientKeyPasswordFrom != nil {
+		in, out := &in.ClientKeyPasswordFrom, &out.ClientKeyPasswordFrom
+		*out = new(SecretSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AlwaysRefreshToken != nil {
 		in, out := &in.AlwaysRefreshToken, &out.AlwaysRefreshToken
 		*out = new(bool)
@@ -1688,129 +2485,393 @@ func (in *KeycloakModuleConfigurationType) DeepCopy() *KeycloakModuleConfigurati
 	if in == nil {
 		return nil
 	}
-	out := new(KeycloakModuleConfigurationType)
+	out := new(KeycloakModuleConfigurationType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginModuleReferenceType) DeepCopyInto(out *LoginModuleReferenceType) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Flag != nil {
+		in, out := &in.Flag, &out.Flag
+		*out = new(string)
+		**out = **in
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Reload != nil {
+		in, out := &in.Reload, &out.Reload
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginModuleReferenceType.
+func (in *LoginModuleReferenceType) DeepCopy() *LoginModuleReferenceType {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginModuleReferenceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoginModulesType) DeepCopyInto(out *LoginModulesType) {
+	*out = *in
+	if in.PropertiesLoginModules != nil {
+		in, out := &in.PropertiesLoginModules, &out.PropertiesLoginModules
+		*out = make([]PropertiesLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GuestLoginModules != nil {
+		in, out := &in.GuestLoginModules, &out.GuestLoginModules
+		*out = make([]GuestLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeycloakLoginModules != nil {
+		in, out := &in.KeycloakLoginModules, &out.KeycloakLoginModules
+		*out = make([]KeycloakLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OIDCLoginModules != nil {
+		in, out := &in.OIDCLoginModules, &out.OIDCLoginModules
+		*out = make([]OIDCLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginModulesType.
+func (in *LoginModulesType) DeepCopy() *LoginModulesType {
+	if in == nil {
+		return nil
+	}
+	out := new(LoginModulesType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
+	*out = *in
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingSince != nil {
+		in, out := &in.PendingSince, &out.PendingSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowType) DeepCopyInto(out *MaintenanceWindowType) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowType.
+func (in *MaintenanceWindowType) DeepCopy() *MaintenanceWindowType {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementRBACGrantType) DeepCopyInto(out *ManagementRBACGrantType) {
+	*out = *in
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(ManagementRBACScope)
+		**out = **in
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]ManagementOperationGroup, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementRBACGrantType.
+func (in *ManagementRBACGrantType) DeepCopy() *ManagementRBACGrantType {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementRBACGrantType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementSecuritySettingsType) DeepCopyInto(out *ManagementSecuritySettingsType) {
+	*out = *in
+	if in.HawtioRoles != nil {
+		in, out := &in.HawtioRoles, &out.HawtioRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Connector.DeepCopyInto(&out.Connector)
+	in.Authorisation.DeepCopyInto(&out.Authorisation)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementSecuritySettingsType.
+func (in *ManagementSecuritySettingsType) DeepCopy() *ManagementSecuritySettingsType {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementSecuritySettingsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCJwksCacheType) DeepCopyInto(out *OIDCJwksCacheType) {
+	*out = *in
+	if in.CacheTtl != nil {
+		in, out := &in.CacheTtl, &out.CacheTtl
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinTimeBetweenRequests != nil {
+		in, out := &in.MinTimeBetweenRequests, &out.MinTimeBetweenRequests
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCJwksCacheType.
+func (in *OIDCJwksCacheType) DeepCopy() *OIDCJwksCacheType {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCJwksCacheType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCLoginModuleType) DeepCopyInto(out *OIDCLoginModuleType) {
+	*out = *in
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = new(string)
+		**out = **in
+	}
+	if in.ClientId != nil {
+		in, out := &in.ClientId, &out.ClientId
+		*out = new(string)
+		**out = **in
+	}
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(string)
+		**out = **in
+	}
+	if in.RedirectUri != nil {
+		in, out := &in.RedirectUri, &out.RedirectUri
+		*out = new(string)
+		**out = **in
+	}
+	if in.PrincipalClaim != nil {
+		in, out := &in.PrincipalClaim, &out.PrincipalClaim
+		*out = new(string)
+		**out = **in
+	}
+	if in.RolesClaim != nil {
+		in, out := &in.RolesClaim, &out.RolesClaim
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleMappings != nil {
+		in, out := &in.RoleMappings, &out.RoleMappings
+		*out = make([]OIDCRoleMappingType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.JwksCache.DeepCopyInto(&out.JwksCache)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCLoginModuleType.
+func (in *OIDCLoginModuleType) DeepCopy() *OIDCLoginModuleType {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCLoginModuleType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCRoleMappingType) DeepCopyInto(out *OIDCRoleMappingType) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCRoleMappingType.
+func (in *OIDCRoleMappingType) DeepCopy() *OIDCRoleMappingType {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCRoleMappingType)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoginModuleReferenceType) DeepCopyInto(out *LoginModuleReferenceType) {
+func (in *ObjectMeta) DeepCopyInto(out *ObjectMeta) {
 	*out = *in
-	if in.Name != nil {
-		in, out := &in.Name, &out.Name
-		*out = new(string)
-		**out = **in
-	}
-	if in.Flag != nil {
-		in, out := &in.Flag, &out.Flag
-		*out = new(string)
-		**out = **in
-	}
-	if in.Debug != nil {
-		in, out := &in.Debug, &out.Debug
-		*out = new(bool)
-		**out = **in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Reload != nil {
-		in, out := &in.Reload, &out.Reload
-		*out = new(bool)
-		**out = **in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginModuleReferenceType.
-func (in *LoginModuleReferenceType) DeepCopy() *LoginModuleReferenceType {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectMeta.
+func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	if in == nil {
 		return nil
 	}
-	out := new(LoginModuleReferenceType)
+	out := new(ObjectMeta)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoginModulesType) DeepCopyInto(out *LoginModulesType) {
+func (in *OrdinalOverrideType) DeepCopyInto(out *OrdinalOverrideType) {
 	*out = *in
-	if in.PropertiesLoginModules != nil {
-		in, out := &in.PropertiesLoginModules, &out.PropertiesLoginModules
-		*out = make([]PropertiesLoginModuleType, len(*in))
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.GuestLoginModules != nil {
-		in, out := &in.GuestLoginModules, &out.GuestLoginModules
-		*out = make([]GuestLoginModuleType, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.KeycloakLoginModules != nil {
-		in, out := &in.KeycloakLoginModules, &out.KeycloakLoginModules
-		*out = make([]KeycloakLoginModuleType, len(*in))
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoginModulesType.
-func (in *LoginModulesType) DeepCopy() *LoginModulesType {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrdinalOverrideType.
+func (in *OrdinalOverrideType) DeepCopy() *OrdinalOverrideType {
 	if in == nil {
 		return nil
 	}
-	out := new(LoginModulesType)
+	out := new(OrdinalOverrideType)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementSecuritySettingsType) DeepCopyInto(out *ManagementSecuritySettingsType) {
+func (in *PasswordCodecType) DeepCopyInto(out *PasswordCodecType) {
 	*out = *in
-	if in.HawtioRoles != nil {
-		in, out := &in.HawtioRoles, &out.HawtioRoles
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.KeySecretRef != nil {
+		in, out := &in.KeySecretRef, &out.KeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
 	}
-	in.Connector.DeepCopyInto(&out.Connector)
-	in.Authorisation.DeepCopyInto(&out.Authorisation)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementSecuritySettingsType.
-func (in *ManagementSecuritySettingsType) DeepCopy() *ManagementSecuritySettingsType {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordCodecType.
+func (in *PasswordCodecType) DeepCopy() *PasswordCodecType {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementSecuritySettingsType)
+	out := new(PasswordCodecType)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectMeta) DeepCopyInto(out *ObjectMeta) {
+func (in *PauseType) DeepCopyInto(out *PauseType) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectMeta.
-func (in *ObjectMeta) DeepCopy() *ObjectMeta {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PauseType.
+func (in *PauseType) DeepCopy() *PauseType {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectMeta)
+	out := new(PauseType)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1835,6 +2896,68 @@ func (in *PermissionType) DeepCopy() *PermissionType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanStatus) DeepCopyInto(out *PlanStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PlannedResourceChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BrokerProperties != nil {
+		in, out := &in.BrokerProperties, &out.BrokerProperties
+		*out = make([]PlannedPropertyChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanStatus.
+func (in *PlanStatus) DeepCopy() *PlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedPropertyChange) DeepCopyInto(out *PlannedPropertyChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedPropertyChange.
+func (in *PlannedPropertyChange) DeepCopy() *PlannedPropertyChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedPropertyChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedResourceChange) DeepCopyInto(out *PlannedResourceChange) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedResourceChange.
+func (in *PlannedResourceChange) DeepCopy() *PlannedResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSecurityType) DeepCopyInto(out *PodSecurityType) {
 	*out = *in
@@ -2027,6 +3150,21 @@ func (in *QueueConfigurationType) DeepCopy() *QueueConfigurationType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueMessageCount) DeepCopyInto(out *QueueMessageCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueMessageCount.
+func (in *QueueMessageCount) DeepCopy() *QueueMessageCount {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueMessageCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceSelector) DeepCopyInto(out *ResourceSelector) {
 	*out = *in
@@ -2127,6 +3265,82 @@ func (in *RoleAccessType) DeepCopy() *RoleAccessType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSourceFileType) DeepCopyInto(out *SecretSourceFileType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSourceFileType.
+func (in *SecretSourceFileType) DeepCopy() *SecretSourceFileType {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretSourceFileType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSourceSecretType) DeepCopyInto(out *SecretSourceSecretType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSourceSecretType.
+func (in *SecretSourceSecretType) DeepCopy() *SecretSourceSecretType {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretSourceSecretType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSourceType) DeepCopyInto(out *SecretSourceType) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSourceSecretType)
+		**out = **in
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(SecretSourceFileType)
+		**out = **in
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(SecretSourceVaultType)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSourceType.
+func (in *SecretSourceType) DeepCopy() *SecretSourceType {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretSourceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretSourceVaultType) DeepCopyInto(out *SecretSourceVaultType) {
+	*out = *in
+	in.TokenSecretRef.DeepCopyInto(&out.TokenSecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretSourceVaultType.
+func (in *SecretSourceVaultType) DeepCopy() *SecretSourceVaultType {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretSourceVaultType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityDomainsType) DeepCopyInto(out *SecurityDomainsType) {
 	*out = *in
@@ -2167,9 +3381,29 @@ func (in *SecuritySettingsType) DeepCopy() *SecuritySettingsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageRestoreType) DeepCopyInto(out *StorageRestoreType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageRestoreType.
+func (in *StorageRestoreType) DeepCopy() *StorageRestoreType {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageRestoreType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageType) DeepCopyInto(out *StorageType) {
 	*out = *in
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(StorageRestoreType)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageType.
@@ -2205,6 +3439,11 @@ func (in *UserType) DeepCopyInto(out *UserType) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(SecretSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
@@ -2253,3 +3492,38 @@ func (in *VolumeClaimTemplate) DeepCopy() *VolumeClaimTemplate {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExpansionStatus) DeepCopyInto(out *VolumeExpansionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExpansionStatus.
+func (in *VolumeExpansionStatus) DeepCopy() *VolumeExpansionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExpansionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZonePlacementType) DeepCopyInto(out *ZonePlacementType) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZonePlacementType.
+func (in *ZonePlacementType) DeepCopy() *ZonePlacementType {
+	if in == nil {
+		return nil
+	}
+	out := new(ZonePlacementType)
+	in.DeepCopyInto(out)
+	return out
+}
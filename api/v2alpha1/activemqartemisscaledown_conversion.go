@@ -0,0 +1,15 @@
+package v2alpha1
+
+import (
+	"github.com/arkmq-org/activemq-artemis-operator/api/internal/conversion"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+func (r *ActiveMQArtemisScaledown) ConvertTo(dst ctrlconversion.Hub) error {
+	return conversion.Convert(r, dst.(*v1beta1.ActiveMQArtemisScaledown))
+}
+
+func (r *ActiveMQArtemisScaledown) ConvertFrom(src ctrlconversion.Hub) error {
+	return conversion.Convert(src.(*v1beta1.ActiveMQArtemisScaledown), r)
+}
@@ -1,16 +1,15 @@
 package v2alpha2
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/conversion"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"github.com/arkmq-org/activemq-artemis-operator/api/internal/conversion"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
-var loga = logf.Log.WithName("v2alpha2conversion")
-
-func (r *ActiveMQArtemisAddress) ConvertTo(dst conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemisAddress) ConvertTo(dst ctrlconversion.Hub) error {
+	return conversion.Convert(r, dst.(*v1beta1.ActiveMQArtemisAddress))
 }
 
-func (r *ActiveMQArtemisAddress) ConvertFrom(src conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemisAddress) ConvertFrom(src ctrlconversion.Hub) error {
+	return conversion.Convert(src.(*v1beta1.ActiveMQArtemisAddress), r)
 }
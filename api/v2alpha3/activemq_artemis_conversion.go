@@ -1,16 +1,15 @@
 package v2alpha3
 
 import (
-	"sigs.k8s.io/controller-runtime/pkg/conversion"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"github.com/arkmq-org/activemq-artemis-operator/api/internal/conversion"
+	"github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	ctrlconversion "sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
-var log = logf.Log.WithName("v2alpha3Conversion")
-
-func (r *ActiveMQArtemis) ConvertTo(dst conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemis) ConvertTo(dst ctrlconversion.Hub) error {
+	return conversion.Convert(r, dst.(*v1beta1.ActiveMQArtemis))
 }
 
-func (r *ActiveMQArtemis) ConvertFrom(src conversion.Hub) error {
-	return nil
+func (r *ActiveMQArtemis) ConvertFrom(src ctrlconversion.Hub) error {
+	return conversion.Convert(src.(*v1beta1.ActiveMQArtemis), r)
 }
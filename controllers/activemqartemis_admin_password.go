@@ -0,0 +1,85 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	adminPasswordVolumeName = "admin-password"
+	adminPasswordMountPath  = "/amq/extra/credentials"
+	adminPasswordFileName   = "admin-password"
+)
+
+// the scripts of the images read the admin password from the environment of
+// their shell, it is set from the projected file rather than the pod spec
+var adminPasswordFileExport = fmt.Sprintf("export AMQ_PASSWORD=$(cat %s/%s)", adminPasswordMountPath, adminPasswordFileName)
+
+// a password of .Spec.AdminPasswordFrom is resolved by the operator into the
+// credentials secret and reaches the broker pod as a file of that secret
+func isAdminPasswordProjected(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	return !common.IsRestricted(customResource) && customResource.Spec.AdminPassword == "" && customResource.Spec.AdminPasswordFrom != nil
+}
+
+func applyAdminPasswordFileToTemplate(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, template *corev1.PodTemplateSpec) {
+	if common.IsRestricted(customResource) || len(template.Spec.Containers) == 0 {
+		return
+	}
+	broker := &template.Spec.Containers[0]
+	// the command is carried over from the deployed broker container
+	if len(broker.Command) > 2 {
+		broker.Command = append([]string{}, broker.Command...)
+		broker.Command[2] = strings.TrimPrefix(broker.Command[2], adminPasswordFileExport+"; ")
+	}
+	if !isAdminPasswordProjected(customResource) {
+		return
+	}
+
+	template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+		Name: adminPasswordVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: namer.SecretsCredentialsNameBuilder.Name(),
+				Items:      []corev1.KeyToPath{{Key: "AMQ_PASSWORD", Path: adminPasswordFileName}},
+			},
+		},
+	})
+	mount := corev1.VolumeMount{
+		Name:      adminPasswordVolumeName,
+		MountPath: adminPasswordMountPath,
+		ReadOnly:  true,
+	}
+
+	broker.VolumeMounts = append(broker.VolumeMounts, mount)
+	if len(broker.Command) > 2 {
+		broker.Command[2] = adminPasswordFileExport + "; " + broker.Command[2]
+	}
+
+	if len(template.Spec.InitContainers) > 0 {
+		init := &template.Spec.InitContainers[0]
+		init.VolumeMounts = append(init.VolumeMounts, mount)
+		if len(init.Args) == 2 {
+			init.Args = []string{init.Args[0], adminPasswordFileExport + " && " + init.Args[1]}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func validateBrokerGroups(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	groups := customResource.Spec.DeploymentPlan.BrokerGroups
+	if len(groups) == 0 {
+		return nil, false
+	}
+	invalid := func(format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidBrokerGroups,
+			Message: fmt.Sprintf(format, args...),
+		}, false
+	}
+
+	if !isClustered(customResource) {
+		return invalid("Spec.DeploymentPlan.BrokerGroups needs clustered brokers, the brokers of a group join the cluster of the deployment plan")
+	}
+	names := map[string]bool{}
+	for index, group := range groups {
+		field := fmt.Sprintf("Spec.DeploymentPlan.BrokerGroups[%d]", index)
+		// the ordinal of a pod is the last segment of its name
+		if _, err := strconv.Atoi(group.Name); err == nil {
+			return invalid("%s.Name %s must not be a number", field, group.Name)
+		}
+		// the pods of the StatefulSet are named <statefulset>-<ordinal>
+		if errs := validation.IsDNS1123Label(namer.CrToGroupSS(customResource.Name, group.Name) + "-0"); len(errs) > 0 {
+			return invalid("%s.Name %s does not make a valid pod name, %v", field, group.Name, errs)
+		}
+		if names[group.Name] {
+			return invalid("%s.Name %s is not unique", field, group.Name)
+		}
+		names[group.Name] = true
+
+		if group.Size != nil && *group.Size < 0 {
+			return invalid("%s.Size %d must not be negative", field, *group.Size)
+		}
+		if group.Storage != nil && group.Storage.Size != "" {
+			if !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+				return invalid("%s.Storage needs Spec.DeploymentPlan.PersistenceEnabled", field)
+			}
+			if _, err := resource.ParseQuantity(group.Storage.Size); err != nil {
+				return invalid("%s.Storage.Size %s is not a quantity, %v", field, group.Storage.Size, err)
+			}
+		}
+	}
+	return nil, false
+}
+
+// the scripts of the broker pods take the ordinal from the name of the pod
+const hostnameOrdinal = "${HOSTNAME##*-}"
+
+// ProcessBrokerGroups requests a StatefulSet for each group from the desired
+// StatefulSet of the deployment plan. The pods of a group have the labels of
+// the deployment plan, they share its services and join its cluster.
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessBrokerGroups(customResource *brokerv1beta1.ActiveMQArtemis, desired *appsv1.StatefulSet) {
+	for index := range customResource.Spec.DeploymentPlan.BrokerGroups {
+		group := &customResource.Spec.DeploymentPlan.BrokerGroups[index]
+		name := namer.CrToGroupSS(customResource.Name, group.Name)
+
+		groupStatefulSet, _ := reconciler.cloneOfDeployed(reflect.TypeOf(appsv1.StatefulSet{}), name).(*appsv1.StatefulSet)
+		if groupStatefulSet == nil {
+			groupStatefulSet = &appsv1.StatefulSet{
+				TypeMeta:   desired.TypeMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: desired.Namespace},
+			}
+		}
+		groupStatefulSet.Labels = desired.Labels
+		groupStatefulSet.Annotations = desired.Annotations
+		groupStatefulSet.Spec = *desired.Spec.DeepCopy()
+		applyBrokerGroup(customResource, group, groupStatefulSet)
+
+		reconciler.trackDesired(groupStatefulSet)
+	}
+}
+
+func applyBrokerGroup(customResource *brokerv1beta1.ActiveMQArtemis, group *brokerv1beta1.BrokerGroupType, statefulSet *appsv1.StatefulSet) {
+	spec := &statefulSet.Spec
+	replicas := common.GetBrokerGroupSize(group)
+	spec.Replicas = &replicas
+
+	selector := map[string]string{selectors.LabelBrokerGroupKey: group.Name}
+	for key, value := range spec.Selector.MatchLabels {
+		selector[key] = value
+	}
+	spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	if spec.Template.Labels == nil {
+		spec.Template.Labels = map[string]string{}
+	}
+	spec.Template.Labels[selectors.LabelBrokerGroupKey] = group.Name
+
+	// the overrides of spec.deploymentPlan.ordinalOverrides are for the ordinals of the deployment plan
+	spec.Template.Spec.SchedulingGates = withoutSchedulingGate(spec.Template.Spec.SchedulingGates, ordinalOverridesSchedulingGate)
+
+	podSpec := &spec.Template.Spec
+	withGroupOrdinal(podSpec, group.Name)
+	if group.Resources != nil {
+		podSpec.Containers[0].Resources = *group.Resources.DeepCopy()
+	}
+	if group.NodeSelector != nil {
+		podSpec.NodeSelector = group.NodeSelector
+	}
+	if group.Tolerations != nil {
+		podSpec.Tolerations = group.Tolerations
+	}
+	if group.Affinity != nil {
+		podSpec.Affinity = &corev1.Affinity{
+			PodAffinity:     group.Affinity.PodAffinity,
+			PodAntiAffinity: group.Affinity.PodAntiAffinity,
+			NodeAffinity:    group.Affinity.NodeAffinity,
+		}
+	}
+	if group.TopologySpreadConstraints != nil {
+		podSpec.TopologySpreadConstraints = group.TopologySpreadConstraints
+	}
+
+	if group.Storage != nil {
+		if journal := findClaimTemplate(spec.VolumeClaimTemplates, customResource.Name); journal != nil {
+			if group.Storage.Size != "" {
+				journal.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse(group.Storage.Size)
+			}
+			if group.Storage.StorageClassName != "" {
+				journal.Spec.StorageClassName = &group.Storage.StorageClassName
+			}
+		}
+	}
+}
+
+// withGroupOrdinal makes the ordinal of a broker of the group <group>-<ordinal>
+// in the scripts of its pod, so that it reads the broker-<group>-<ordinal>
+// properties rather than those of the broker of the deployment plan with the
+// same ordinal, and its probes reach its own pod
+func withGroupOrdinal(podSpec *corev1.PodSpec, group string) {
+	rewrite := func(values []string) {
+		for index := range values {
+			values[index] = strings.ReplaceAll(values[index], hostnameOrdinal, group+"-"+hostnameOrdinal)
+		}
+	}
+	rewriteProbe := func(probe *corev1.Probe) {
+		if probe != nil && probe.Exec != nil {
+			rewrite(probe.Exec.Command)
+		}
+	}
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for index := range containers {
+			container := &containers[index]
+			rewrite(container.Command)
+			rewrite(container.Args)
+			for envIndex := range container.Env {
+				container.Env[envIndex].Value = strings.ReplaceAll(container.Env[envIndex].Value, hostnameOrdinal, group+"-"+hostnameOrdinal)
+			}
+			rewriteProbe(container.LivenessProbe)
+			rewriteProbe(container.ReadinessProbe)
+			rewriteProbe(container.StartupProbe)
+		}
+	}
+}
+
+func withoutSchedulingGate(gates []corev1.PodSchedulingGate, name string) []corev1.PodSchedulingGate {
+	var kept []corev1.PodSchedulingGate
+	for _, gate := range gates {
+		if gate.Name != name {
+			kept = append(kept, gate)
+		}
+	}
+	return kept
+}
@@ -23,6 +23,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -34,6 +35,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
@@ -42,11 +44,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/certutil"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/secretsource"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 	"github.com/go-logr/logr"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
 
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
@@ -111,8 +118,11 @@ type ActiveMQArtemisReconciler struct {
 	rtclient.Client
 	Scheme        *runtime.Scheme
 	events        chan event.GenericEvent
+	recorder      record.EventRecorder
 	log           logr.Logger
 	isOnOpenShift bool
+	// set when the pod webhook applies the ordinal overrides and placement zones
+	podWebhookEnabled bool
 }
 
 func NewActiveMQArtemisReconciler(cluster cluster.Cluster, logger logr.Logger, isOpenShift bool) *ActiveMQArtemisReconciler {
@@ -132,6 +142,7 @@ func NewActiveMQArtemisReconciler(cluster cluster.Cluster, logger logr.Logger, i
 //+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=pods,verbs=get;list
 //+kubebuilder:rbac:groups="",namespace=activemq-artemis-operator,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;routes;serviceaccounts,verbs=get;list;watch;create;delete;update
 //+kubebuilder:rbac:groups="",namespace=activemq-artemis-operator,resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups="",namespace=activemq-artemis-operator,resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=apps,namespace=activemq-artemis-operator,resources=deployments;daemonsets;replicasets;statefulsets,verbs=get;list;watch;create;delete;update
 //+kubebuilder:rbac:groups=networking.k8s.io,namespace=activemq-artemis-operator,resources=ingresses,verbs=get;list;watch;create;delete;update
 //+kubebuilder:rbac:groups=route.openshift.io,namespace=activemq-artemis-operator,resources=routes;routes/custom-host;routes/status,verbs=get;list;watch;create;delete;update
@@ -139,6 +150,7 @@ func NewActiveMQArtemisReconciler(cluster cluster.Cluster, logger logr.Logger, i
 //+kubebuilder:rbac:groups=apps,namespace=activemq-artemis-operator,resources=deployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=activemq-artemis-operator,resources=roles;rolebindings,verbs=create;get;delete
 //+kubebuilder:rbac:groups=policy,namespace=activemq-artemis-operator,resources=poddisruptionbudgets,verbs=create;get;delete;list;update;watch
+//+kubebuilder:rbac:groups=storage.k8s.io,namespace=activemq-artemis-operator,resources=storageclasses,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -160,11 +172,13 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			reqLogger.V(1).Info("ActiveMQArtemis Controller Reconcile encountered a IsNotFound, for request NamespacedName " + request.NamespacedName.String())
+			metrics.Forget(request.NamespacedName)
 			return result, nil
 		}
 		reqLogger.Error(err, "unable to retrieve the ActiveMQArtemis")
 		return result, err
 	}
+	conditionsBefore := append([]metav1.Condition(nil), customResource.Status.Conditions...)
 	var reconcileBlocked bool = false
 	if val, present := customResource.Annotations[common.BlockReconcileAnnotation]; present {
 		if boolVal, err := strconv.ParseBool(val); err == nil {
@@ -174,22 +188,54 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 
 	namer := MakeNamers(customResource)
 	reconciler := NewActiveMQArtemisReconcilerImpl(customResource, r)
+	reconciler.ctx = ctx
+
+	planning := isPlanRequested(customResource)
+	if !planning {
+		customResource.Status.Plan = nil
+	}
 
 	var requeueRequest bool = false
 	var valid bool = false
-	if valid, requeueRequest = reconciler.validate(customResource, r.Client, *namer); valid {
-
-		if !reconcileBlocked {
+	endValidate := reconciler.traceStep("validate")
+	valid, requeueRequest = reconciler.validate(customResource, r.Client, *namer)
+	endValidate(nil)
+	if valid {
+
+		if planning {
+			// a plan only reads, it is reported even when reconcile is blocked
+			customResource.Status.Plan, err = reconciler.Plan(customResource, *namer, r.Client, r.Scheme)
+		} else if !reconcileBlocked {
 			err = reconciler.Process(customResource, *namer, r.Client, r.Scheme)
 		}
-		if reconciler.ProcessBrokerStatus(customResource, r.Client, r.Scheme) {
+		// a plan leaves the brokers and the status of the deployment as they are
+		if !planning {
+			endStatus := reconciler.traceStep("ProcessBrokerStatus")
+			if reconciler.ProcessBrokerStatus(customResource, r.Client, r.Scheme) {
+				requeueRequest = true
+			}
+			endStatus(nil)
+		}
+		if !reconcileBlocked && !planning && reconciler.ProcessCredentialRotation(customResource, *namer, r.Client) {
+			requeueRequest = true
+		}
+		if !reconcileBlocked && !planning && reconciler.ProcessOrdinalOverrides(customResource, *namer, r.Client) {
 			requeueRequest = true
 		}
+		if !planning {
+			reconciler.ProcessZonePlacementStatus(customResource, r.Client)
+		}
 	}
 
 	common.UpdateBlockedStatus(customResource, reconcileBlocked)
+	common.UpdatePausedStatus(customResource)
 	common.ProcessStatus(customResource, r.Client, request.NamespacedName, *namer, err)
 
+	recordConditionEvents(r.recorder, customResource, conditionsBefore)
+	if err != nil {
+		recordEvent(r.recorder, customResource, corev1.EventTypeWarning, EventReasonReconcileFailed, "%s", err.Error())
+	}
+
 	crStatusUpdateErr := r.UpdateCRStatus(customResource, r.Client, request.NamespacedName)
 	if crStatusUpdateErr != nil {
 		requeueRequest = true
@@ -200,9 +246,17 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 		requeueRequest = true
 	}
 
+	if !requeueRequest && isVolumeExpansionInProgress(customResource) {
+		reqLogger.V(1).Info("volume claims are expanding, requeuing")
+		requeueRequest = true
+	}
+
 	if requeueRequest {
 		reqLogger.V(1).Info("requeue reconcile")
 		result = ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}
+	} else if next, scheduled := nextScheduledReconcile(customResource); valid && scheduled {
+		reqLogger.V(1).Info("requeue for scheduled credential rotation, maintenance window or disk usage check", "at", next)
+		result = ctrl.Result{RequeueAfter: time.Until(next)}
 	}
 
 	if valid && err == nil && crStatusUpdateErr == nil {
@@ -212,17 +266,83 @@ func (r *ActiveMQArtemisReconciler) Reconcile(ctx context.Context, request ctrl.
 }
 
 func (r *ActiveMQArtemisReconcilerImpl) validate(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namer common.Namers) (bool, retry bool) {
-	// Do additional validation here
+	validationCondition, retry := r.validationCondition(customResource, client, namer)
+
+	validationCondition.ObservedGeneration = customResource.Generation
+	meta.SetStatusCondition(&customResource.Status.Conditions, validationCondition)
+	if validationCondition.Status == metav1.ConditionFalse {
+		metrics.ValidationFailed(types.NamespacedName{Namespace: customResource.Namespace, Name: customResource.Name}, validationCondition.Reason)
+	}
+
+	return validationCondition.Status != metav1.ConditionFalse, retry
+}
+
+// a false condition with retry is expected to resolve on its own, like a secret
+// that is yet to be created
+func (r *ActiveMQArtemisReconcilerImpl) validationCondition(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namer common.Namers) (metav1.Condition, bool) {
+	validationCondition, retry := specValidationCondition(customResource, r.isOnOpenShift)
+
+	// the checks against the resources of the cluster are left out of admission,
+	// a CR can be applied before what it references, like the SSL secrets that
+	// validateSSLEnabledSecrets waits for with a retry
+	var condition *metav1.Condition
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateExtraMounts(customResource, client)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validatePodWebhook(customResource, r.podWebhookEnabled)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateZonePairing(customResource, client)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = r.validateVolumeClaimSizes(customResource, client, namer)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = r.validateRestore(customResource, client, namer)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateSSLEnabledSecrets(customResource, client, namer)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	return validationCondition, retry
+}
+
+// the checks of the spec alone, shared with the validating webhook, they read
+// nothing from the cluster and change nothing
+func specValidationCondition(customResource *brokerv1beta1.ActiveMQArtemis, isOnOpenShift bool) (metav1.Condition, bool) {
 	validationCondition := metav1.Condition{
 		Type:   brokerv1beta1.ValidConditionType,
 		Status: metav1.ConditionTrue,
 		Reason: brokerv1beta1.ValidConditionSuccessReason,
 	}
 
-	condition, retry := validateExtraMounts(customResource, client)
-	if condition != nil {
-		validationCondition = *condition
-	}
+	var condition *metav1.Condition
+	var retry bool
 
 	if validationCondition.Status != metav1.ConditionFalse && customResource.Spec.DeploymentPlan.PodDisruptionBudget != nil {
 		condition := validatePodDisruption(customResource)
@@ -239,21 +359,21 @@ func (r *ActiveMQArtemisReconcilerImpl) validate(customResource *brokerv1beta1.A
 	}
 
 	if validationCondition.Status != metav1.ConditionFalse {
-		condition, retry = r.validateStorage()
+		condition, retry = validateStorage(customResource)
 		if condition != nil {
 			validationCondition = *condition
 		}
 	}
 
 	if validationCondition.Status != metav1.ConditionFalse {
-		condition, retry = validateAcceptorPorts(customResource)
+		condition, retry = validateRestoreSpec(customResource)
 		if condition != nil {
 			validationCondition = *condition
 		}
 	}
 
 	if validationCondition.Status != metav1.ConditionFalse {
-		condition, retry = validateSSLEnabledSecrets(customResource, client, namer)
+		condition, retry = validateAcceptorPorts(customResource)
 		if condition != nil {
 			validationCondition = *condition
 		}
@@ -274,23 +394,97 @@ func (r *ActiveMQArtemisReconcilerImpl) validate(customResource *brokerv1beta1.A
 	}
 
 	if validationCondition.Status != metav1.ConditionFalse {
-		condition, retry = r.validateExposeModes(customResource)
+		condition, retry = validateExposeModes(customResource, isOnOpenShift)
 		if condition != nil {
 			validationCondition = *condition
 		}
 	}
 
 	if validationCondition.Status != metav1.ConditionFalse {
-		condition, retry = r.validateEnvVars(customResource)
+		condition, retry = validateEnvVars(customResource)
 		if condition != nil {
 			validationCondition = *condition
 		}
 	}
 
-	validationCondition.ObservedGeneration = customResource.Generation
-	meta.SetStatusCondition(&customResource.Status.Conditions, validationCondition)
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateOrdinalOverrides(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
 
-	return validationCondition.Status != metav1.ConditionFalse, retry
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateBrokerGroups(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateZonePlacement(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateContainers(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateJvm(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateManagementRBAC(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateCredentialRotation(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateMaintenanceWindow(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateDiskPressure(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validateAdminPasswordSource(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	if validationCondition.Status != metav1.ConditionFalse {
+		condition, retry = validatePasswordCodec(customResource)
+		if condition != nil {
+			validationCondition = *condition
+		}
+	}
+
+	return validationCondition, retry
 }
 
 func validateNoDupKeysInBrokerProperties(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
@@ -355,9 +549,9 @@ func validateAcceptorPorts(customResource *brokerv1beta1.ActiveMQArtemis) (*meta
 	return nil, false
 }
 
-func (r *ActiveMQArtemisReconcilerImpl) validateExposeModes(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+func validateExposeModes(customResource *brokerv1beta1.ActiveMQArtemis, isOnOpenShift bool) (*metav1.Condition, bool) {
 
-	if !r.isOnOpenShift {
+	if !isOnOpenShift {
 		for _, acceptor := range customResource.Spec.Acceptors {
 			if acceptor.Expose && acceptor.ExposeMode != nil && *acceptor.ExposeMode == brokerv1beta1.ExposeModes.Route {
 				return &metav1.Condition{
@@ -392,7 +586,7 @@ func (r *ActiveMQArtemisReconcilerImpl) validateExposeModes(customResource *brok
 	}
 
 	for _, acceptor := range customResource.Spec.Acceptors {
-		if acceptor.Expose && (acceptor.ExposeMode != nil && *acceptor.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !r.isOnOpenShift) &&
+		if acceptor.Expose && (acceptor.ExposeMode != nil && *acceptor.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !isOnOpenShift) &&
 			customResource.Spec.IngressDomain == "" && acceptor.IngressHost == "" {
 			return &metav1.Condition{
 				Type:    brokerv1beta1.ValidConditionType,
@@ -404,7 +598,7 @@ func (r *ActiveMQArtemisReconcilerImpl) validateExposeModes(customResource *brok
 	}
 
 	for _, connector := range customResource.Spec.Connectors {
-		if connector.Expose && (connector.ExposeMode != nil && *connector.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !r.isOnOpenShift) &&
+		if connector.Expose && (connector.ExposeMode != nil && *connector.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !isOnOpenShift) &&
 			customResource.Spec.IngressDomain == "" && connector.IngressHost == "" {
 			return &metav1.Condition{
 				Type:    brokerv1beta1.ValidConditionType,
@@ -416,7 +610,7 @@ func (r *ActiveMQArtemisReconcilerImpl) validateExposeModes(customResource *brok
 	}
 
 	console := customResource.Spec.Console
-	if console.Expose && (console.ExposeMode != nil && *console.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !r.isOnOpenShift) &&
+	if console.Expose && (console.ExposeMode != nil && *console.ExposeMode == brokerv1beta1.ExposeModes.Ingress || !isOnOpenShift) &&
 		customResource.Spec.IngressDomain == "" && console.IngressHost == "" {
 		return &metav1.Condition{
 			Type:    brokerv1beta1.ValidConditionType,
@@ -429,7 +623,7 @@ func (r *ActiveMQArtemisReconcilerImpl) validateExposeModes(customResource *brok
 	return nil, false
 }
 
-func (r *ActiveMQArtemisReconcilerImpl) validateEnvVars(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+func validateEnvVars(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
 
 	internalVarNames := map[string]string{
 		debugArgsEnvVarName:      debugArgsEnvVarName,
@@ -458,11 +652,142 @@ func (r *ActiveMQArtemisReconcilerImpl) validateEnvVars(customResource *brokerv1
 	return nil, false
 }
 
-func (r *ActiveMQArtemisReconcilerImpl) validateStorage() (*metav1.Condition, bool) {
+func validateManagementRBAC(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	grants := customResource.Spec.DeploymentPlan.ManagementRBAC
+	if len(grants) == 0 {
+		return nil, false
+	}
+
+	invalid := func(message string) *metav1.Condition {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidManagementRBAC,
+			Message: message,
+		}
+	}
+
+	if !isManagementRBACActive(customResource) {
+		return invalid(".Spec.DeploymentPlan.ManagementRBAC needs .Spec.DeploymentPlan.ManagementRBACEnabled, the grants are ignored by a broker without management role based access control"), false
+	}
+
+	operatorRoleCanView := false
+	operatorRole := environments.ResolveBrokerRoleFromEnvs(customResource.Spec.Env, environments.RoleEnvVarDefaultValue)
+	restrictsBroker := false
+	for index, grant := range grants {
+		isBrokerScope := grant.Scope == nil || *grant.Scope == brokerv1beta1.ManagementRBACScopes.Broker
+		if grant.Role == "" {
+			return invalid(fmt.Sprintf(".Spec.DeploymentPlan.ManagementRBAC[%d] has no role", index)), false
+		}
+		if len(grant.Operations) == 0 {
+			return invalid(fmt.Sprintf(".Spec.DeploymentPlan.ManagementRBAC[%d] for role %q has no operations", index, grant.Role)), false
+		}
+		if isBrokerScope && grant.Match != "" {
+			return invalid(fmt.Sprintf(".Spec.DeploymentPlan.ManagementRBAC[%d] for role %q has a match, it only applies to the address and queue scopes", index, grant.Role)), false
+		}
+		if !isBrokerScope && grant.Match == "" {
+			return invalid(fmt.Sprintf(".Spec.DeploymentPlan.ManagementRBAC[%d] for role %q needs a match for the %s scope", index, grant.Role, *grant.Scope)), false
+		}
+		if isBrokerScope {
+			restrictsBroker = true
+			if grant.Role == operatorRole {
+				operatorRoleCanView = true
+			}
+		}
+	}
+
+	// in restricted mode the operator identity has its own getStatus grant, otherwise it
+	// connects as the admin user and broker scope grants take precedence over the defaults
+	if restrictsBroker && !common.IsRestricted(customResource) && !operatorRoleCanView {
+		return invalid(fmt.Sprintf(".Spec.DeploymentPlan.ManagementRBAC restricts the broker scope but role %q, used by the operator to read the broker status over jolokia, has no broker scope grant", operatorRole)), false
+	}
+	return nil, false
+}
+
+// restricted brokers always authorize the management operations
+func isManagementRBACActive(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	return customResource.Spec.DeploymentPlan.ManagementRBACEnabled || common.IsRestricted(customResource)
+}
+
+func validateCredentialRotation(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	rotation := customResource.Spec.CredentialRotation
+	if rotation == nil {
+		return nil, false
+	}
+
+	if common.IsRestricted(customResource) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidCredentialRotation,
+			Message: ".Spec.CredentialRotation is not supported with .Spec.Restricted, there are no generated credentials to rotate",
+		}, false
+	}
+	if rotation.Schedule != "" {
+		if _, err := cron.ParseStandard(rotation.Schedule); err != nil {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionFailedInvalidCredentialRotation,
+				Message: fmt.Sprintf(".Spec.CredentialRotation.Schedule %q is invalid, %v", rotation.Schedule, err),
+			}, false
+		}
+	}
+	return nil, false
+}
+
+// the source is resolved when the credentials are processed, admission only
+// checks that a single source is configured
+func validateAdminPasswordSource(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	if customResource.Spec.AdminPassword != "" || customResource.Spec.AdminPasswordFrom == nil {
+		return nil, false
+	}
+
+	if err := secretsource.NewResolver(nil).Validate(customResource.Spec.AdminPasswordFrom); err != nil {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedSecretSourceReason,
+			Message: fmt.Sprintf(".Spec.AdminPasswordFrom is invalid, %v", err),
+		}, false
+	}
+	return nil, false
+}
+
+// the key secret is read when processing, a missing secret is a deployment error
+func validatePasswordCodec(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	if customResource.Spec.PasswordCodec == nil {
+		return nil, false
+	}
+	// the operator masks with the default codec, a custom codec could not decode
+	// the admin password, which is always masked
+	if customResource.Spec.PasswordCodec.ClassName != "" {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidPasswordCodec,
+			Message: ".Spec.PasswordCodec.ClassName is not supported, the operator masks the admin, cluster and ssl passwords with the default codec, which a custom codec can not decode",
+		}, false
+	}
+	if customResource.Spec.PasswordCodec.KeySecretRef == nil {
+		return nil, false
+	}
+	if ref := customResource.Spec.PasswordCodec.KeySecretRef; ref.Name == "" || ref.Key == "" {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidPasswordCodec,
+			Message: ".Spec.PasswordCodec.KeySecretRef requires a name and a key",
+		}, false
+	}
+	return nil, false
+}
+
+func validateStorage(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
 
-	if r.customResource.Spec.DeploymentPlan.PersistenceEnabled {
-		if r.customResource.Spec.DeploymentPlan.Storage.Size != "" {
-			_, err := resource.ParseQuantity(r.customResource.Spec.DeploymentPlan.Storage.Size)
+	if customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		if customResource.Spec.DeploymentPlan.Storage.Size != "" {
+			_, err := resource.ParseQuantity(customResource.Spec.DeploymentPlan.Storage.Size)
 			if err != nil {
 				return &metav1.Condition{
 					Type:    brokerv1beta1.ValidConditionType,
@@ -806,6 +1131,7 @@ func GetDefaultLabels(cr *brokerv1beta1.ActiveMQArtemis) map[string]string {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ActiveMQArtemisReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemis")
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&brokerv1beta1.ActiveMQArtemis{}).
 		Owns(&appsv1.StatefulSet{}).
@@ -821,7 +1147,7 @@ func (r *ActiveMQArtemisReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	var err error
-	controller, err := builder.Build(r)
+	controller, err := builder.Build(metrics.InstrumentReconciler("ActiveMQArtemis", tracing.InstrumentReconciler("ActiveMQArtemis", r)))
 	if err == nil {
 		r.events = make(chan event.GenericEvent)
 		err = controller.Watch(
@@ -859,6 +1185,15 @@ func EqualCRStatus(s1, s2 *brokerv1beta1.ActiveMQArtemisStatus) bool {
 		len(s2.ExternalConfigs) != len(s1.ExternalConfigs) ||
 		externalConfigsModified(s2.ExternalConfigs, s1.ExternalConfigs) ||
 		!reflect.DeepEqual(s1.PodStatus, s2.PodStatus) ||
+		!reflect.DeepEqual(s1.CredentialRotation, s2.CredentialRotation) ||
+		!reflect.DeepEqual(s1.Plan, s2.Plan) ||
+		!reflect.DeepEqual(s1.Maintenance, s2.Maintenance) ||
+		!reflect.DeepEqual(s1.PropertiesPendingRestart, s2.PropertiesPendingRestart) ||
+		!reflect.DeepEqual(s1.VolumeExpansion, s2.VolumeExpansion) ||
+		!reflect.DeepEqual(s1.DiskUsage, s2.DiskUsage) ||
+		s1.ExpandedStorageSize != s2.ExpandedStorageSize ||
+		!reflect.DeepEqual(s1.BrokerGroups, s2.BrokerGroups) ||
+		!reflect.DeepEqual(s1.Zones, s2.Zones) ||
 		len(s1.Conditions) != len(s2.Conditions) ||
 		conditionsModified(s2.Conditions, s1.Conditions) {
 
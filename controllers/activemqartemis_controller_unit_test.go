@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
 	"github.com/golang/mock/gomock"
@@ -28,14 +29,21 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
 	artemis_client "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestValidate(t *testing.T) {
@@ -241,3 +249,842 @@ func TestJolokiaStatusCached(t *testing.T) {
 	assert.True(t, strings.Contains(valid.Error(), "AttributeNotFoundException"))
 
 }
+
+func TestValidateManagementRBAC(t *testing.T) {
+
+	addressScope := brokerv1beta1.ManagementRBACScopes.Address
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				ManagementRBACEnabled: true,
+				ManagementRBAC: []brokerv1beta1.ManagementRBACGrantType{
+					{
+						Role:       "ops",
+						Scope:      &addressScope,
+						Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.View},
+					},
+				},
+			},
+		},
+	}
+
+	condition, retry := validateManagementRBAC(cr)
+	assert.False(t, retry)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidManagementRBAC, condition.Reason)
+	assert.Contains(t, condition.Message, "needs a match")
+
+	cr.Spec.DeploymentPlan.ManagementRBAC[0].Match = "orders"
+	condition, _ = validateManagementRBAC(cr)
+	assert.Nil(t, condition)
+
+	// a broker scope grant hides the default getStatus permission from the operator role
+	cr.Spec.DeploymentPlan.ManagementRBAC = append(cr.Spec.DeploymentPlan.ManagementRBAC, brokerv1beta1.ManagementRBACGrantType{
+		Role:       "ops",
+		Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.Manage},
+	})
+	condition, _ = validateManagementRBAC(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "\"admin\"")
+
+	cr.Spec.Env = []corev1.EnvVar{{Name: "AMQ_ROLE", Value: "ops"}}
+	condition, _ = validateManagementRBAC(cr)
+	assert.Nil(t, condition)
+
+	// the grants are ignored unless the broker authorizes the management operations
+	cr.Spec.DeploymentPlan.ManagementRBACEnabled = false
+	condition, _ = validateManagementRBAC(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "ManagementRBACEnabled")
+
+	cr.Spec.Env = nil
+	restricted := true
+	cr.Spec.Restricted = &restricted
+	condition, _ = validateManagementRBAC(cr)
+	assert.Nil(t, condition)
+}
+
+func TestValidateCredentialRotation(t *testing.T) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			CredentialRotation: &brokerv1beta1.CredentialRotationType{Schedule: "every sunday"},
+		},
+	}
+
+	condition, retry := validateCredentialRotation(cr)
+	assert.False(t, retry)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidCredentialRotation, condition.Reason)
+	assert.Contains(t, condition.Message, "every sunday")
+
+	cr.Spec.CredentialRotation.Schedule = "0 3 * * 0"
+	condition, _ = validateCredentialRotation(cr)
+	assert.Nil(t, condition)
+
+	cr.Spec.Restricted = &[]bool{true}[0]
+	condition, _ = validateCredentialRotation(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Restricted")
+}
+
+func TestCredentialRotationDue(t *testing.T) {
+
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "a", CreationTimestamp: v1.Time{Time: created}},
+	}
+
+	_, due := credentialRotationDue(cr, created.Add(time.Hour))
+	assert.False(t, due)
+
+	cr.Annotations = map[string]string{common.RotateCredentialsAnnotation: "first"}
+	trigger, due := credentialRotationDue(cr, created.Add(time.Hour))
+	assert.True(t, due)
+	assert.Equal(t, "first", trigger)
+
+	// the same trigger value is acted on once
+	cr.Status.CredentialRotation.LastTrigger = "first"
+	_, due = credentialRotationDue(cr, created.Add(time.Hour))
+	assert.False(t, due)
+
+	// daily at 3am
+	cr.Spec.CredentialRotation = &brokerv1beta1.CredentialRotationType{Schedule: "0 3 * * *"}
+	next, scheduled := nextCredentialRotation(cr)
+	assert.True(t, scheduled)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC), next.UTC())
+
+	_, due = credentialRotationDue(cr, next.Add(-time.Minute))
+	assert.False(t, due)
+	trigger, due = credentialRotationDue(cr, next)
+	assert.True(t, due)
+	assert.Equal(t, "first", trigger)
+
+	cr.Status.CredentialRotation.LastRotationTime = &v1.Time{Time: next}
+	_, due = credentialRotationDue(cr, next.Add(time.Hour))
+	assert.False(t, due)
+}
+
+func TestValidateMaintenanceWindow(t *testing.T) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			MaintenanceWindow: &brokerv1beta1.MaintenanceWindowType{Schedule: "0 2 * * 6", Duration: v1.Duration{Duration: 2 * time.Hour}},
+		},
+	}
+	condition, retry := validateMaintenanceWindow(cr)
+	assert.False(t, retry)
+	assert.Nil(t, condition)
+
+	cr.Spec.MaintenanceWindow.TimeZone = "Mars/Olympus_Mons"
+	condition, _ = validateMaintenanceWindow(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidMaintenanceWindow, condition.Reason)
+	assert.Contains(t, condition.Message, "Mars/Olympus_Mons")
+
+	cr.Spec.MaintenanceWindow.TimeZone = "Europe/Paris"
+	cr.Spec.MaintenanceWindow.Duration = v1.Duration{}
+	condition, _ = validateMaintenanceWindow(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Duration")
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+
+	// saturdays from 2am to 4am in Paris, UTC+1 in winter
+	window, err := parseMaintenanceWindow(&brokerv1beta1.MaintenanceWindowType{Schedule: "0 2 * * 6", Duration: v1.Duration{Duration: 2 * time.Hour}, TimeZone: "Europe/Paris"})
+	assert.NoError(t, err)
+
+	saturday := time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)
+	assert.False(t, window.isOpen(saturday.Add(30*time.Minute)))
+	assert.True(t, window.isOpen(saturday.Add(time.Hour)))
+	assert.True(t, window.isOpen(saturday.Add(2*time.Hour+59*time.Minute)))
+	assert.False(t, window.isOpen(saturday.Add(3*time.Hour)))
+	assert.Equal(t, saturday.Add(time.Hour), window.next(saturday).UTC())
+	assert.Equal(t, saturday.Add(7*24*time.Hour+time.Hour), window.next(saturday.Add(time.Hour)).UTC())
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			MaintenanceWindow: &brokerv1beta1.MaintenanceWindowType{Schedule: "0 2 * * 6", Duration: v1.Duration{Duration: 2 * time.Hour}, TimeZone: "Europe/Paris"},
+		},
+	}
+	updateMaintenanceStatus(cr, nil, saturday)
+	assert.Equal(t, saturday.Add(time.Hour), cr.Status.Maintenance.NextWindow.UTC())
+	assert.Nil(t, cr.Status.Maintenance.PendingSince)
+	_, scheduled := nextScheduledReconcile(cr)
+	assert.False(t, scheduled)
+
+	// pending since the first reconcile that held a change
+	updateMaintenanceStatus(cr, []string{"spec.template.spec.containers[0].env"}, saturday)
+	updateMaintenanceStatus(cr, []string{"spec.template.spec.containers[0].env"}, saturday.Add(time.Minute))
+	assert.Equal(t, saturday, cr.Status.Maintenance.PendingSince.Time)
+	next, scheduled := nextScheduledReconcile(cr)
+	assert.True(t, scheduled)
+	assert.Equal(t, saturday.Add(time.Hour), next.UTC())
+
+	cr.Spec.MaintenanceWindow = nil
+	updateMaintenanceStatus(cr, nil, saturday)
+	assert.Nil(t, cr.Status.Maintenance)
+}
+
+func newCredentialRotationFixture(t *testing.T, failOrdinal string) (*ActiveMQArtemisReconcilerImpl, *brokerv1beta1.ActiveMQArtemis, client.Client, map[string][]string) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "a",
+			Namespace:   "test",
+			Annotations: map[string]string{common.RotateCredentialsAnnotation: "now"},
+		},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{Size: &[]int32{2}[0]},
+		},
+	}
+	namer := MakeNamers(cr)
+
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            namer.SecretsCredentialsNameBuilder.Name(),
+			Namespace:       cr.Namespace,
+			OwnerReferences: []v1.OwnerReference{{Kind: "ActiveMQArtemis", Name: cr.Name}},
+		},
+		Data: map[string][]byte{
+			"AMQ_USER":             []byte("old"),
+			"AMQ_PASSWORD":         []byte("oldpass"),
+			"AMQ_CLUSTER_USER":     []byte("cluster"),
+			"AMQ_CLUSTER_PASSWORD": []byte("oldcluster"),
+		},
+	}
+	client := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	r := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log, isOpenshift)
+	ri := NewActiveMQArtemisReconcilerImpl(cr, r)
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	calls := map[string][]string{}
+	for _, ordinal := range []string{"0", "1"} {
+		ordinal := ordinal
+		j := jolokia.NewMockIJolokia(mockCtrl)
+		j.EXPECT().Exec(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+			operation := strings.Split(strings.Split(body, `"operation":"`)[1], "(")[0]
+			calls[ordinal] = append(calls[ordinal], operation)
+			if ordinal == failOrdinal {
+				return &jolokia.ResponseData{Status: 500, Error: "boom"}, fmt.Errorf("boom")
+			}
+			return &jolokia.ResponseData{Status: 200}, nil
+		}).AnyTimes()
+		ri.jolokiaEndpoints = append(ri.jolokiaEndpoints, &jolokia_client.JkInfo{Artemis: artemis_client.GetArtemisWithJolokia(j, "a"), IP: "IP", Ordinal: ordinal})
+	}
+	return ri, cr, client, calls
+}
+
+func TestCredentialRotation(t *testing.T) {
+
+	ri, cr, client, calls := newCredentialRotationFixture(t, "")
+	namer := MakeNamers(cr)
+
+	assert.True(t, ri.ProcessCredentialRotation(cr, *namer, client))
+
+	assert.Equal(t, []string{"addUser"}, calls["0"])
+	assert.Equal(t, []string{"addUser"}, calls["1"])
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: namer.SecretsCredentialsNameBuilder.Name(), Namespace: cr.Namespace}, secret))
+	assert.NotEqual(t, "old", string(secret.Data["AMQ_USER"]))
+	assert.NotEqual(t, "oldpass", string(secret.Data["AMQ_PASSWORD"]))
+	assert.NotEqual(t, "cluster", string(secret.Data["AMQ_CLUSTER_USER"]))
+	assert.NotEqual(t, "oldcluster", string(secret.Data["AMQ_CLUSTER_PASSWORD"]))
+
+	assert.NotNil(t, cr.Status.CredentialRotation.LastRotationTime)
+	assert.Equal(t, "now", cr.Status.CredentialRotation.LastTrigger)
+	assert.Equal(t, "old", cr.Status.CredentialRotation.RetiredUser)
+	assert.True(t, meta.IsStatusConditionTrue(cr.Status.Conditions, brokerv1beta1.CredentialsRotatedConditionType))
+
+	// acted on, the old user is removed
+	assert.False(t, ri.ProcessCredentialRotation(cr, *namer, client))
+	assert.Equal(t, []string{"addUser", "removeUser"}, calls["0"])
+	assert.Equal(t, []string{"addUser", "removeUser"}, calls["1"])
+	assert.Empty(t, cr.Status.CredentialRotation.RetiredUser)
+
+	assert.False(t, ri.ProcessCredentialRotation(cr, *namer, client))
+	assert.Len(t, calls["0"], 2)
+}
+
+func TestCredentialRotationRetriesRemovalOfRetiredUser(t *testing.T) {
+
+	ri, cr, client, calls := newCredentialRotationFixture(t, "1")
+	namer := MakeNamers(cr)
+	cr.Annotations = nil
+	cr.Status.CredentialRotation.RetiredUser = "old"
+
+	assert.True(t, ri.ProcessCredentialRotation(cr, *namer, client))
+	assert.Equal(t, []string{"removeUser"}, calls["0"])
+	assert.Equal(t, []string{"removeUser"}, calls["1"])
+	assert.Equal(t, "old", cr.Status.CredentialRotation.RetiredUser)
+}
+
+func TestCredentialRotationLeavesSecretChecksum(t *testing.T) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "test"}}
+	namer := MakeNamers(cr)
+	checksumOf := func(data map[string]string) string {
+		containers := []corev1.Container{{Name: "broker", Env: []corev1.EnvVar{{Name: "TRIGGERED_ROLL_COUNT", Value: "0"}}}}
+		secret := &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: namer.SecretsCredentialsNameBuilder.Name()}, StringData: data}
+		trackSecretCheckSumInEnvVar([]client.Object{secret}, containers, rotatedCredentialsKeys(cr, *namer))
+		return environments.Retrieve(containers, "TRIGGERED_ROLL_COUNT").Value
+	}
+
+	before := checksumOf(map[string]string{"AMQ_USER": "old", "AMQ_PASSWORD": "oldpass", "AMQ_CLUSTER_USER": "cluster", "AMQ_CLUSTER_PASSWORD": "oldcluster", "OTHER": "x"})
+	assert.Equal(t, before, checksumOf(map[string]string{"AMQ_USER": "new", "AMQ_PASSWORD": "newpass", "AMQ_CLUSTER_USER": "newcluster", "AMQ_CLUSTER_PASSWORD": "newcluster", "OTHER": "x"}))
+	assert.NotEqual(t, before, checksumOf(map[string]string{"AMQ_USER": "old", "AMQ_PASSWORD": "oldpass", "AMQ_CLUSTER_USER": "cluster", "AMQ_CLUSTER_PASSWORD": "oldcluster", "OTHER": "y"}))
+
+	// the admin credentials of the spec roll the brokers when they change
+	cr.Spec.AdminUser = "joe"
+	assert.NotEqual(t, checksumOf(map[string]string{"AMQ_USER": "joe"}), checksumOf(map[string]string{"AMQ_USER": "joseph"}))
+}
+
+func TestCredentialRotationRollsBackNewUserOnFailure(t *testing.T) {
+
+	ri, cr, client, calls := newCredentialRotationFixture(t, "1")
+	namer := MakeNamers(cr)
+
+	assert.True(t, ri.ProcessCredentialRotation(cr, *namer, client))
+
+	assert.Equal(t, []string{"addUser", "removeUser"}, calls["0"])
+	assert.Equal(t, []string{"addUser"}, calls["1"])
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, client.Get(context.TODO(), types.NamespacedName{Name: namer.SecretsCredentialsNameBuilder.Name(), Namespace: cr.Namespace}, secret))
+	assert.Equal(t, "old", string(secret.Data["AMQ_USER"]))
+	assert.Equal(t, "oldcluster", string(secret.Data["AMQ_CLUSTER_PASSWORD"]))
+
+	assert.Nil(t, cr.Status.CredentialRotation.LastRotationTime)
+	condition := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.CredentialsRotatedConditionType)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.CredentialsRotatedConditionFailedReason, condition.Reason)
+	assert.Contains(t, condition.Message, "broker 1")
+}
+
+func TestValidateAdminPasswordSource(t *testing.T) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			AdminPasswordFrom: &brokerv1beta1.SecretSourceType{
+				Secret: &brokerv1beta1.SecretSourceSecretType{Name: "admin", Key: "password"},
+			},
+		},
+	}
+
+	// not resolved on validation, the secret does not need to exist
+	condition, retry := validateAdminPasswordSource(cr)
+	assert.False(t, retry)
+	assert.Nil(t, condition)
+
+	cr.Spec.AdminPasswordFrom.File = &brokerv1beta1.SecretSourceFileType{Path: "admin"}
+	condition, retry = validateAdminPasswordSource(cr)
+	assert.False(t, retry)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedSecretSourceReason, condition.Reason)
+}
+
+func TestValidatePasswordCodec(t *testing.T) {
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			PasswordCodec: &brokerv1beta1.PasswordCodecType{
+				KeySecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "codec"},
+					Key:                  "key",
+				},
+			},
+		},
+	}
+	condition, retry := validatePasswordCodec(cr)
+	assert.False(t, retry)
+	assert.Nil(t, condition)
+
+	masker, err := newPasswordMasker(cr.Spec.PasswordCodec, cr.Namespace, fake.NewClientBuilder().Build())
+	assert.Error(t, err)
+	assert.Nil(t, masker)
+
+	client := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "codec", Namespace: "test"},
+		Data:       map[string][]byte{"key": []byte("secret-key")},
+	}).Build()
+	masker, err = newPasswordMasker(cr.Spec.PasswordCodec, cr.Namespace, client)
+	assert.NoError(t, err)
+
+	password, empty := "password", ""
+	assert.NoError(t, masker.maskAll(&password, &empty, nil))
+	assert.True(t, codec.IsMasked(password))
+	assert.Equal(t, "", empty)
+	decoded, err := codec.Unmask(password, "secret-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "password", decoded)
+
+	container := &corev1.Container{}
+	passwordCodecBrokerConfig(cr.Spec.PasswordCodec, container)
+	assert.Equal(t, codec.KeyEnvVar, container.Env[0].Name)
+	assert.Equal(t, "codec", container.Env[0].ValueFrom.SecretKeyRef.Name)
+
+	// a custom codec could not decode the masked passwords
+	cr.Spec.PasswordCodec.ClassName = "org.example.Codec"
+	condition, retry = validatePasswordCodec(cr)
+	assert.False(t, retry)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidPasswordCodec, condition.Reason)
+	assert.Contains(t, condition.Message, "ClassName")
+	cr.Spec.PasswordCodec.ClassName = ""
+
+	// a key longer than the cipher accepts
+	client = fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: "codec", Namespace: "test"},
+		Data:       map[string][]byte{"key": []byte(strings.Repeat("k", 57))},
+	}).Build()
+	_, err = newPasswordMasker(cr.Spec.PasswordCodec, cr.Namespace, client)
+	assert.Error(t, err)
+
+	cr.Spec.PasswordCodec.KeySecretRef.Key = ""
+	condition, retry = validatePasswordCodec(cr)
+	assert.False(t, retry)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidPasswordCodec, condition.Reason)
+
+	// no codec, passwords are left as is
+	cr.Spec.PasswordCodec = nil
+	condition, _ = validatePasswordCodec(cr)
+	assert.Nil(t, condition)
+	masker, err = newPasswordMasker(cr.Spec.PasswordCodec, cr.Namespace, client)
+	assert.NoError(t, err)
+	password = "password"
+	assert.NoError(t, masker.maskAll(&password))
+	assert.Equal(t, "password", password)
+}
+
+func TestValidateDiskPressure(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{Spec: brokerv1beta1.ActiveMQArtemisSpec{
+		DiskPressure: &brokerv1beta1.DiskPressureType{DiskUsageThreshold: &[]int32{80}[0]},
+	}}
+	condition, _ := validateDiskPressure(cr)
+	assert.Nil(t, condition)
+
+	cr.Spec.DiskPressure.AddressMemoryUsageThreshold = &[]int32{120}[0]
+	condition, _ = validateDiskPressure(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidDiskPressure, condition.Reason)
+	assert.Contains(t, condition.Message, "AddressMemoryUsageThreshold")
+
+	cr.Spec.DiskPressure.AddressMemoryUsageThreshold = nil
+	cr.Spec.DiskPressure.AutoExpand = &brokerv1beta1.AutoExpandType{Increment: "1Gi", MaxSize: "10Gi"}
+	condition, _ = validateDiskPressure(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "PersistenceEnabled")
+
+	cr.Spec.DeploymentPlan.PersistenceEnabled = true
+	cr.Spec.DiskPressure.AutoExpand.Increment = "-1Gi"
+	condition, _ = validateDiskPressure(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Increment")
+}
+
+func TestProcessDiskUsage(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "a", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{Size: &[]int32{2}[0], PersistenceEnabled: true, Storage: brokerv1beta1.StorageType{Size: "2Gi"}},
+			DiskPressure: &brokerv1beta1.DiskPressureType{
+				AutoExpand: &brokerv1beta1.AutoExpandType{Increment: "1Gi", MaxSize: "3Gi"},
+			},
+		},
+	}
+	r := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log, false)
+	ri := NewActiveMQArtemisReconcilerImpl(cr, r)
+
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	attributes := map[string]map[string]string{
+		"0": {"DiskStoreUsage": "0.85", "MaxDiskUsage": "90", "AddressMemoryUsage": "1024", "GlobalMaxSize": "1.073741824e+09"},
+		"1": {"DiskStoreUsage": "0.1", "MaxDiskUsage": "90", "AddressMemoryUsage": "1.073741824e+09", "GlobalMaxSize": "1.073741824e+09"},
+	}
+	for _, ordinal := range []string{"0", "1"} {
+		ordinal := ordinal
+		j := jolokia.NewMockIJolokia(mockCtrl)
+		j.EXPECT().Read(gomock.Any()).DoAndReturn(func(path string) (*jolokia.ResponseData, error) {
+			value, found := attributes[ordinal][path[strings.LastIndex(path, "/")+1:]]
+			if !found {
+				return &jolokia.ResponseData{Status: 404, Error: "no such attribute"}, fmt.Errorf("no such attribute")
+			}
+			return &jolokia.ResponseData{Status: 200, Value: value}, nil
+		}).AnyTimes()
+		ri.jolokiaEndpoints = append(ri.jolokiaEndpoints, &jolokia_client.JkInfo{Artemis: artemis_client.GetArtemisWithJolokia(j, "a"), IP: "IP", Ordinal: ordinal})
+	}
+	client := fake.NewClientBuilder().Build()
+
+	assert.True(t, ri.ProcessDiskUsage(cr, client))
+	assert.Equal(t, []brokerv1beta1.BrokerDiskUsage{
+		{Ordinal: "0", DiskUsage: 85, MaxDiskUsage: 90, AddressMemoryUsage: "1Ki", GlobalMaxSize: "1Gi"},
+		{Ordinal: "1", DiskUsage: 10, MaxDiskUsage: 90, AddressMemoryUsage: "1Gi", GlobalMaxSize: "1Gi", Paging: true},
+	}, cr.Status.DiskUsage)
+	condition := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.DiskPressureConditionType)
+	assert.NotNil(t, condition)
+	assert.Equal(t, "journal disk of broker 0 above 80%, address memory of broker 1 above 90%", condition.Message)
+	assert.Equal(t, "3Gi", cr.Status.ExpandedStorageSize)
+	size := journalStorageSize(cr)
+	assert.Equal(t, "3Gi", size.String())
+
+	// at the max size, a broker that can not be read keeps its usage
+	delete(attributes["0"], "DiskStoreUsage")
+	assert.False(t, ri.ProcessDiskUsage(cr, client))
+	assert.Equal(t, int32(85), cr.Status.DiskUsage[0].DiskUsage)
+	assert.Equal(t, "3Gi", cr.Status.ExpandedStorageSize)
+
+	attributes["0"]["DiskStoreUsage"] = "0.5"
+	attributes["1"]["AddressMemoryUsage"] = "0"
+	cr.Spec.DeploymentPlan.Storage.Size = "4Gi"
+	assert.False(t, ri.ProcessDiskUsage(cr, client))
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.DiskPressureConditionType))
+	assert.Empty(t, cr.Status.ExpandedStorageSize)
+
+	// the size is not validated without persistence
+	cr.Spec.DiskPressure = nil
+	cr.Spec.DeploymentPlan.PersistenceEnabled = false
+	cr.Spec.DeploymentPlan.Storage.Size = "large"
+	cr.Status.ExpandedStorageSize = "8Gi"
+	assert.NotPanics(t, func() { assert.False(t, ri.ProcessDiskUsage(cr, client)) })
+	assert.Equal(t, "8Gi", cr.Status.ExpandedStorageSize)
+	size = journalStorageSize(cr)
+	assert.Equal(t, defaultJournalStorageSize, size.String())
+}
+
+func TestValidateOrdinalOverrides(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{Spec: brokerv1beta1.ActiveMQArtemisSpec{
+		DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			NodeSelector: map[string]string{"disk": "ssd"},
+			OrdinalOverrides: []brokerv1beta1.OrdinalOverrideType{{
+				Ordinal:      0,
+				Env:          []corev1.EnvVar{{Name: "JAVA_ARGS_APPEND", Value: "-Xmx4g"}},
+				NodeSelector: map[string]string{"disk": "hdd", "topology.kubernetes.io/zone": "a"},
+			}},
+		},
+	}}
+	condition, _ := validateOrdinalOverrides(cr)
+	assert.Nil(t, condition)
+
+	// the overrides are applied by the pod webhook
+	condition, _ = validatePodWebhook(cr, true)
+	assert.Nil(t, condition)
+	condition, _ = validatePodWebhook(cr, false)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidOrdinalOverrides, condition.Reason)
+	assert.Contains(t, condition.Message, "ENABLE_WEBHOOKS=true")
+
+	overrides := &cr.Spec.DeploymentPlan.OrdinalOverrides
+	*overrides = append(*overrides, brokerv1beta1.OrdinalOverrideType{Ordinal: 0})
+	condition, _ = validateOrdinalOverrides(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidOrdinalOverrides, condition.Reason)
+	assert.Contains(t, condition.Message, "more than one override")
+
+	(*overrides)[1] = brokerv1beta1.OrdinalOverrideType{Ordinal: 1, Env: []corev1.EnvVar{{Name: "JDK_JAVA_OPTIONS", Value: "x"}}}
+	condition, _ = validateOrdinalOverrides(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "JDK_JAVA_OPTIONS")
+
+	(*overrides)[1] = brokerv1beta1.OrdinalOverrideType{Ordinal: 1, Labels: map[string]string{"application": "other"}}
+	condition, _ = validateOrdinalOverrides(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "reserved label")
+}
+
+func TestValidateBrokerGroups(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "pools"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			PersistenceEnabled: true,
+			BrokerGroups: []brokerv1beta1.BrokerGroupType{
+				{Name: "large", Size: common.Int32ToPtr(2), Storage: &brokerv1beta1.BrokerGroupStorageType{Size: "20Gi"}},
+				{Name: "small"},
+			},
+		}},
+	}
+	condition, _ := validateBrokerGroups(cr)
+	assert.Nil(t, condition)
+
+	groups := cr.Spec.DeploymentPlan.BrokerGroups
+	groups[1].Name = "large"
+	condition, _ = validateBrokerGroups(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidBrokerGroups, condition.Reason)
+	assert.Contains(t, condition.Message, "not unique")
+
+	groups[1].Name = "7"
+	condition, _ = validateBrokerGroups(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "must not be a number")
+
+	groups[1].Name = "Small"
+	condition, _ = validateBrokerGroups(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "valid pod name")
+
+	groups[1].Name = "small"
+	groups[0].Storage.Size = "lots"
+	condition, _ = validateBrokerGroups(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "not a quantity")
+
+	groups[0].Storage.Size = "20Gi"
+	cr.Spec.DeploymentPlan.Clustered = common.NewFalse()
+	condition, _ = validateBrokerGroups(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "clustered")
+}
+
+func TestValidateZonePlacement(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "backup"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			ZonePlacement: &brokerv1beta1.ZonePlacementType{Zones: []string{"a", "b", "c"}, HAPairOf: "primary"},
+		}},
+	}
+	condition, _ := validateZonePlacement(cr)
+	assert.Nil(t, condition)
+	primary := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "primary"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			ZonePlacement: &brokerv1beta1.ZonePlacementType{Zones: []string{"a", "b", "c"}},
+		}},
+	}
+	assert.Equal(t, "b", placementZoneOf(cr, primary, 0))
+	assert.Equal(t, "a", placementZoneOf(cr, primary, 2))
+	// the zones of the pair are its own
+	primary.Spec.DeploymentPlan.ZonePlacement.Zones = []string{"b", "a"}
+	assert.Equal(t, "a", placementZoneOf(cr, primary, 0))
+	assert.Equal(t, "c", placementZoneOf(cr, primary, 2))
+	assert.Equal(t, "b", placementZoneOf(cr, primary, 3))
+
+	placement := cr.Spec.DeploymentPlan.ZonePlacement
+	placement.Zones = []string{"a", "a"}
+	condition, _ = validateZonePlacement(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidZonePlacement, condition.Reason)
+	assert.Contains(t, condition.Message, "not unique")
+
+	placement.Zones = []string{"a"}
+	condition, _ = validateZonePlacement(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "at least two zones")
+
+	placement.Zones = []string{"a", "b"}
+	placement.HAPairOf = "backup"
+	condition, _ = validateZonePlacement(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "another ActiveMQArtemis")
+
+	placement.HAPairOf = ""
+	cr.Spec.DeploymentPlan.OrdinalOverrides = []brokerv1beta1.OrdinalOverrideType{{Ordinal: 1, NodeSelector: map[string]string{"topology.kubernetes.io/zone": "c"}}}
+	condition, _ = validateZonePlacement(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "OrdinalOverrides[0]")
+	assert.Equal(t, "a", placementZoneOf(cr, nil, 0))
+}
+
+func TestValidateZonePairing(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "backup", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			ZonePlacement: &brokerv1beta1.ZonePlacementType{Zones: []string{"a", "b"}, HAPairOf: "primary"},
+		}},
+	}
+	primary := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: v1.ObjectMeta{Name: "primary", Namespace: "test"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).Build()
+
+	condition, retry := validateZonePairing(cr, fakeClient)
+	assert.NotNil(t, condition)
+	assert.True(t, retry)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidZonePlacement, condition.Reason)
+	assert.Contains(t, condition.Message, "is not an ActiveMQArtemis")
+
+	assert.NoError(t, fakeClient.Create(context.TODO(), primary))
+	condition, retry = validateZonePairing(cr, fakeClient)
+	assert.NotNil(t, condition)
+	assert.False(t, retry)
+	assert.Contains(t, condition.Message, "zones of its brokers are unknown")
+
+	primary.Spec.DeploymentPlan.ZonePlacement = &brokerv1beta1.ZonePlacementType{Zones: []string{"b", "a"}, HAPairOf: "backup"}
+	assert.NoError(t, fakeClient.Update(context.TODO(), primary))
+	condition, _ = validateZonePairing(cr, fakeClient)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "only one ActiveMQArtemis of a pair")
+
+	primary.Spec.DeploymentPlan.ZonePlacement = &brokerv1beta1.ZonePlacementType{Zones: []string{"b", "a"}, TopologyKey: "example.com/rack"}
+	assert.NoError(t, fakeClient.Update(context.TODO(), primary))
+	condition, _ = validateZonePairing(cr, fakeClient)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "example.com/rack")
+
+	primary.Spec.DeploymentPlan.ZonePlacement.TopologyKey = ""
+	assert.NoError(t, fakeClient.Update(context.TODO(), primary))
+	condition, _ = validateZonePairing(cr, fakeClient)
+	assert.Nil(t, condition)
+}
+
+func TestValidateContainers(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "side"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			JolokiaAgentEnabled: true,
+			Sidecars: []corev1.Container{
+				{Name: "log-shipper", Image: "shipper:1", Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9100}}},
+			},
+			InitContainers: []corev1.Container{{Name: "fetch-plugins", Image: "fetch:1"}},
+		}},
+	}
+	condition, _ := validateContainers(cr)
+	assert.Nil(t, condition)
+
+	sidecars := cr.Spec.DeploymentPlan.Sidecars
+	sidecars[0].Ports[0].ContainerPort = 8778
+	condition, _ = validateContainers(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidContainers, condition.Reason)
+	assert.Contains(t, condition.Message, "8778 is a port of the broker container")
+
+	sidecars[0].Ports[0] = corev1.ContainerPort{Name: "wconsj", ContainerPort: 9100}
+	condition, _ = validateContainers(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "wconsj")
+
+	sidecars[0].Ports[0] = corev1.ContainerPort{Name: "metrics", ContainerPort: 9100}
+	cr.Spec.DeploymentPlan.InitContainers[0].Name = "side-container-init"
+	condition, _ = validateContainers(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "init container of the operator")
+
+	cr.Spec.DeploymentPlan.InitContainers[0].Name = "log-shipper"
+	condition, _ = validateContainers(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Spec.DeploymentPlan.Sidecars[0]")
+
+	cr.Spec.DeploymentPlan.InitContainers[0] = corev1.Container{Name: "fetch-plugins"}
+	condition, _ = validateContainers(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Image")
+}
+
+func TestValidateJvm(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "jvm"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+			PersistenceEnabled: true,
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+			Jvm: &brokerv1beta1.JvmType{
+				HeapPercentage:        common.Int32ToPtr(50),
+				GarbageCollector:      "Z",
+				FlightRecorder:        &brokerv1beta1.FlightRecorderType{VolumeName: "jvm", MaxAge: "6h", MaxSize: "250Mi"},
+				HeapDumpOnOutOfMemory: true,
+			},
+		}},
+	}
+	condition, _ := validateJvm(cr)
+	assert.Nil(t, condition)
+
+	jvm := cr.Spec.DeploymentPlan.Jvm
+	jvm.HeapPercentage = common.Int32ToPtr(90)
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedInvalidJvm, condition.Reason)
+	assert.Contains(t, condition.Message, "HeapPercentage")
+
+	// the heap of the deployment plan is too large for the memory of an ordinal
+	jvm.HeapPercentage = common.Int32ToPtr(50)
+	cr.Spec.DeploymentPlan.OrdinalOverrides = []brokerv1beta1.OrdinalOverrideType{{
+		Ordinal:   1,
+		Resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}},
+	}}
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "Spec.DeploymentPlan.OrdinalOverrides[0].Resources")
+	cr.Spec.DeploymentPlan.OrdinalOverrides = nil
+
+	jvm.HeapPercentage = nil
+	jvm.InitialHeapSize = "1Gi"
+	jvm.MaxHeapSize = "512Mi"
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "InitialHeapSize")
+
+	jvm.InitialHeapSize = ""
+	jvm.MaxHeapSize = "1800Mi"
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "more than 80%")
+
+	jvm.MaxHeapSize = "1Gi"
+	jvm.GarbageCollector = "CMS"
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "GarbageCollector")
+
+	jvm.GarbageCollector = ""
+	jvm.FlightRecorder.VolumeName = "recordings"
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "VolumeName")
+
+	cr.Spec.DeploymentPlan.ExtraVolumes = []corev1.Volume{{Name: "recordings"}}
+	jvm.FlightRecorder.MaxAge = "six hours"
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "MaxAge")
+
+	jvm.FlightRecorder.MaxAge = "6h"
+	cr.Spec.DeploymentPlan.PersistenceEnabled = false
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "HeapDumpOnOutOfMemory")
+
+	cr.Spec.DeploymentPlan.PersistenceEnabled = true
+	jvm.ExtraArgs = []string{"-XX:+AlwaysPreTouch", "-Dbroker.label=a,b:c/d@e%f", "-Dx=1;touch /tmp/x"}
+	condition, _ = validateJvm(cr)
+	assert.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "ExtraArgs[2]")
+
+	for _, arg := range []string{"-Dx=a b", "-Dx=$(id)", "-Dx='a'", "-Dx=`id`", "-Dx=a|b", "-Dx=*"} {
+		jvm.ExtraArgs = []string{arg}
+		condition, _ = validateJvm(cr)
+		assert.NotNil(t, condition, arg)
+	}
+
+	jvm.ExtraArgs = jvm.ExtraArgs[:0]
+	condition, _ = validateJvm(cr)
+	assert.Nil(t, condition)
+}
+
+func TestRestrictedJvmOptions(t *testing.T) {
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: v1.ObjectMeta{Name: "jvm"},
+		Spec:       brokerv1beta1.ActiveMQArtemisSpec{Restricted: common.NewTrue()},
+	}
+	namers := *MakeNamers(cr)
+	assert.Equal(t, "-XX:InitialRAMPercentage=70.0 -XX:MaxRAMPercentage=70.0 -XX:AutoBoxCacheMax=20000 -XX:+PrintClassHistogram -XX:+UseG1GC -XX:+UseStringDeduplication -Djava.net.preferIPv4Stack=true", restrictedJvmOptionsOf(cr, namers))
+
+	cr.Spec.DeploymentPlan.PersistenceEnabled = true
+	cr.Spec.DeploymentPlan.Jvm = &brokerv1beta1.JvmType{
+		HeapPercentage:        common.Int32ToPtr(60),
+		GarbageCollector:      "Parallel",
+		GCLogging:             true,
+		HeapDumpOnOutOfMemory: true,
+		ExtraArgs:             []string{"-XX:+AlwaysPreTouch"},
+	}
+	assert.Equal(t, "-XX:InitialRAMPercentage=60.0 -XX:MaxRAMPercentage=60.0 -XX:AutoBoxCacheMax=20000 -XX:+PrintClassHistogram -XX:+UseParallelGC -Djava.net.preferIPv4Stack=true -Xlog:gc:stdout:time,uptime,level,tags -XX:+HeapDumpOnOutOfMemoryError -XX:HeapDumpPath=/app/ -XX:+AlwaysPreTouch", restrictedJvmOptionsOf(cr, namers))
+}
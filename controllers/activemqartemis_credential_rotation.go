@@ -0,0 +1,280 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/random"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const credentialsPropertiesKey = "aa_credentials.properties"
+
+// Rotation is due when the trigger annotation carries a value that was not acted
+// on yet, or when the schedule fired since the last rotation
+func credentialRotationDue(cr *brokerv1beta1.ActiveMQArtemis, now time.Time) (trigger string, due bool) {
+	trigger = cr.Status.CredentialRotation.LastTrigger
+	if value, present := cr.Annotations[common.RotateCredentialsAnnotation]; present && value != "" && value != trigger {
+		return value, true
+	}
+	if next, scheduled := nextCredentialRotation(cr); scheduled && !next.After(now) {
+		return trigger, true
+	}
+	return trigger, false
+}
+
+func nextCredentialRotation(cr *brokerv1beta1.ActiveMQArtemis) (time.Time, bool) {
+	if cr.Spec.CredentialRotation == nil || cr.Spec.CredentialRotation.Schedule == "" {
+		return time.Time{}, false
+	}
+	schedule, err := cron.ParseStandard(cr.Spec.CredentialRotation.Schedule)
+	if err != nil {
+		// reported by validation
+		return time.Time{}, false
+	}
+	last := cr.CreationTimestamp.Time
+	if cr.Status.CredentialRotation.LastRotationTime != nil {
+		last = cr.Status.CredentialRotation.LastRotationTime.Time
+	}
+	return schedule.Next(last), true
+}
+
+// The new admin user is added to every broker before the credentials secret is
+// updated, so the jolokia callers that source their credentials from the secret
+// accept both the old and the new user. The old user is removed on a later
+// reconcile, once the brokers accept the new one from the secret. The cluster
+// credentials are applied to all brokers through the broker properties reload,
+// the rotated keys are left out of the secret checksum so nothing rolls.
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentialRotation(cr *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client) (retry bool) {
+
+	if common.IsRestricted(cr) {
+		return false
+	}
+
+	retry = reconciler.removeRetiredUser(cr, client)
+
+	now := time.Now()
+	trigger, due := credentialRotationDue(cr, now)
+	if !due || retry {
+		// a rotation waits for the removal of the user of the previous one
+		return retry
+	}
+
+	reconciler.log.V(1).Info("Rotating credentials", "trigger", trigger)
+	rotated, retiredUser, err := reconciler.rotateCredentials(cr, namer, client)
+	if err != nil {
+		reconciler.log.V(1).Info("unable to rotate credentials, will retry", "error", err)
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    brokerv1beta1.CredentialsRotatedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.CredentialsRotatedConditionFailedReason,
+			Message: err.Error(),
+		})
+		return true
+	}
+
+	cr.Status.CredentialRotation.LastRotationTime = &metav1.Time{Time: now}
+	cr.Status.CredentialRotation.LastTrigger = trigger
+	cr.Status.CredentialRotation.RetiredUser = retiredUser
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:    brokerv1beta1.CredentialsRotatedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  brokerv1beta1.CredentialsRotatedConditionRotatedReason,
+		Message: fmt.Sprintf("rotated %v", rotated),
+	})
+	// the old admin user is removed on the next reconcile
+	return true
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) rotateCredentials(cr *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client) ([]string, string, error) {
+
+	secretName := namer.SecretsCredentialsNameBuilder.Name()
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: cr.Namespace}, secret); err != nil {
+		return nil, "", fmt.Errorf("unable to retrieve credentials secret %s, %v", secretName, err)
+	}
+	if !isOwnedByCR(secret, cr) {
+		return nil, "", fmt.Errorf("credentials secret %s is not owned by ActiveMQArtemis/%s and cannot be rotated", secretName, cr.Name)
+	}
+
+	rotatedData := map[string]string{
+		"AMQ_CLUSTER_USER":     random.GenerateRandomString(8),
+		"AMQ_CLUSTER_PASSWORD": random.GenerateRandomString(8),
+	}
+
+	var newAdminUser, retiredUser string
+	if generatedAdminCredentials(cr) {
+		newAdminUser = random.GenerateRandomString(8)
+		newAdminPassword := random.GenerateRandomString(8)
+		if err := reconciler.addUserToBrokers(cr, client, newAdminUser, newAdminPassword); err != nil {
+			return nil, "", err
+		}
+		rotatedData["AMQ_USER"] = newAdminUser
+		rotatedData["AMQ_PASSWORD"] = newAdminPassword
+		retiredUser = string(secret.Data["AMQ_USER"])
+	}
+
+	for _, k := range []string{"AMQ_CLUSTER_PASSWORD", "AMQ_PASSWORD"} {
+		if value, found := rotatedData[k]; found {
+			if err := reconciler.maskPasswords.maskAll(&value); err != nil {
+				if newAdminUser != "" {
+					reconciler.removeUserFromBrokers(newAdminUser)
+				}
+				return nil, "", fmt.Errorf("unable to mask %s, %v", k, err)
+			}
+			rotatedData[k] = value
+		}
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for k, v := range rotatedData {
+		secret.Data[k] = []byte(v)
+	}
+	if err := client.Update(context.TODO(), secret); err != nil {
+		if newAdminUser != "" {
+			reconciler.removeUserFromBrokers(newAdminUser)
+		}
+		return nil, "", fmt.Errorf("unable to update credentials secret %s, %v", secretName, err)
+	}
+	return sortedKeys(rotatedData), retiredUser, nil
+}
+
+// credentials provided in the spec are not ours to change
+func generatedAdminCredentials(cr *brokerv1beta1.ActiveMQArtemis) bool {
+	return cr.Spec.AdminUser == "" && cr.Spec.AdminPassword == "" && cr.Spec.AdminPasswordFrom == nil
+}
+
+// the keys of the credentials secret that a rotation changes on the running
+// brokers, they are left out of the checksum that rolls the brokers
+func rotatedCredentialsKeys(cr *brokerv1beta1.ActiveMQArtemis, namer common.Namers) map[string][]string {
+	if common.IsRestricted(cr) {
+		return nil
+	}
+	keys := []string{"AMQ_CLUSTER_PASSWORD", "AMQ_CLUSTER_USER"}
+	if generatedAdminCredentials(cr) {
+		keys = append(keys, "AMQ_PASSWORD", "AMQ_USER")
+	}
+	return map[string][]string{namer.SecretsCredentialsNameBuilder.Name(): keys}
+}
+
+// the endpoints of a reconcile that follows the rotation use the new user of the
+// credentials secret, a broker that accepts it no longer needs the old one. A
+// broker that restarted since has no old user to remove
+func (reconciler *ActiveMQArtemisReconcilerImpl) removeRetiredUser(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) (retry bool) {
+	user := cr.Status.CredentialRotation.RetiredUser
+	if user == "" {
+		return false
+	}
+
+	reconciler.resolveJolokiaEndpoints(cr, client)
+	// the brokers of the groups included
+	if brokers := len(common.BrokerOrdinals(cr)); len(reconciler.jolokiaEndpoints) != brokers {
+		reconciler.log.V(1).Info("waiting for all brokers to remove retired user", "expected", brokers, "found", len(reconciler.jolokiaEndpoints))
+		return true
+	}
+	removed := true
+	for _, jk := range reconciler.jolokiaEndpoints {
+		if _, err := jk.Artemis.RemoveUser(user); err != nil && !strings.Contains(err.Error(), "does not exist") {
+			reconciler.log.V(1).Info("unable to remove retired user, will retry", "ordinal", jk.Ordinal, "error", err)
+			removed = false
+		}
+	}
+	if removed {
+		cr.Status.CredentialRotation.RetiredUser = ""
+	}
+	return !removed
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) addUserToBrokers(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, user string, password string) error {
+
+	reconciler.resolveJolokiaEndpoints(cr, client)
+
+	// a broker that misses the new user would reject the operator till it rolls
+	if brokers := len(common.BrokerOrdinals(cr)); len(reconciler.jolokiaEndpoints) == 0 || len(reconciler.jolokiaEndpoints) != brokers {
+		return fmt.Errorf("waiting for all %d brokers to be available over jolokia, found %d", brokers, len(reconciler.jolokiaEndpoints))
+	}
+
+	roles := environments.ResolveBrokerRoleFromEnvs(cr.Spec.Env, environments.RoleEnvVarDefaultValue)
+	for i, jk := range reconciler.jolokiaEndpoints {
+		if _, err := jk.Artemis.AddUser(user, password, roles); err != nil {
+			for _, added := range reconciler.jolokiaEndpoints[:i] {
+				added.Artemis.RemoveUser(user)
+			}
+			return fmt.Errorf("unable to add rotated admin user to broker %s, %v", jk.Ordinal, err)
+		}
+	}
+	return nil
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) removeUserFromBrokers(user string) {
+	for _, jk := range reconciler.jolokiaEndpoints {
+		if _, err := jk.Artemis.RemoveUser(user); err != nil {
+			reconciler.log.V(1).Info("unable to remove user", "ordinal", jk.Ordinal, "error", err)
+		}
+	}
+}
+
+// once rotated, the cluster credentials are applied through the broker properties
+// so that running brokers pick up the new cluster password without a restart
+func (reconciler *ActiveMQArtemisReconcilerImpl) credentialsProperties(cr *brokerv1beta1.ActiveMQArtemis, namer common.Namers) (string, bool) {
+	if cr.Status.CredentialRotation.LastRotationTime == nil || common.IsRestricted(cr) {
+		return "", false
+	}
+	obj := reconciler.cloneOfDeployed(reflect.TypeOf(corev1.Secret{}), namer.SecretsCredentialsNameBuilder.Name())
+	if obj == nil {
+		return "", false
+	}
+	secret := obj.(*corev1.Secret)
+	clusterUser, hasUser := secret.Data["AMQ_CLUSTER_USER"]
+	clusterPassword, hasPassword := secret.Data["AMQ_CLUSTER_PASSWORD"]
+	if !hasUser || !hasPassword {
+		return "", false
+	}
+	password := string(clusterPassword)
+	if err := reconciler.maskPasswords.maskAll(&password); err != nil {
+		reconciler.log.Error(err, "unable to mask cluster password")
+		return "", false
+	}
+	props := newPropsWithHeader()
+	fmt.Fprintf(props, "clusterUser=%s\n", clusterUser)
+	fmt.Fprintf(props, "clusterPassword=%s\n", password)
+	return props.String(), true
+}
+
+func isOwnedByCR(obj metav1.Object, cr *brokerv1beta1.ActiveMQArtemis) bool {
+	for _, or := range obj.GetOwnerReferences() {
+		if or.Kind == "ActiveMQArtemis" && or.Name == cr.Name {
+			return true
+		}
+	}
+	return false
+}
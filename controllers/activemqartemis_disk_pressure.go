@@ -0,0 +1,254 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	mgmt "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/artemis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultDiskUsageThreshold          = 80
+	defaultAddressMemoryUsageThreshold = 90
+	defaultDiskUsageCheckPeriod        = time.Minute
+	defaultJournalStorageSize          = "2Gi"
+)
+
+func validateDiskPressure(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	spec := customResource.Spec.DiskPressure
+	if spec == nil {
+		return nil, false
+	}
+	invalid := func(message string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidDiskPressure,
+			Message: fmt.Sprintf(message, args...),
+		}, false
+	}
+
+	for field, threshold := range map[string]*int32{"DiskUsageThreshold": spec.DiskUsageThreshold, "AddressMemoryUsageThreshold": spec.AddressMemoryUsageThreshold} {
+		if threshold != nil && (*threshold < 1 || *threshold > 100) {
+			return invalid(".Spec.DiskPressure.%s %d must be a percentage between 1 and 100", field, *threshold)
+		}
+	}
+	if spec.CheckPeriod != nil && spec.CheckPeriod.Duration <= 0 {
+		return invalid(".Spec.DiskPressure.CheckPeriod %q must be positive", spec.CheckPeriod.Duration)
+	}
+	if spec.AutoExpand != nil {
+		if !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+			return invalid(".Spec.DiskPressure.AutoExpand needs .Spec.DeploymentPlan.PersistenceEnabled")
+		}
+		increment, err := resource.ParseQuantity(spec.AutoExpand.Increment)
+		if err != nil || increment.Sign() <= 0 {
+			return invalid(".Spec.DiskPressure.AutoExpand.Increment %q must be a positive quantity", spec.AutoExpand.Increment)
+		}
+		if _, err := resource.ParseQuantity(spec.AutoExpand.MaxSize); err != nil {
+			return invalid(".Spec.DiskPressure.AutoExpand.MaxSize %q is invalid, %v", spec.AutoExpand.MaxSize, err)
+		}
+	}
+	return nil, false
+}
+
+func thresholdOrDefault(threshold *int32, defaultThreshold int32) int32 {
+	if threshold == nil {
+		return defaultThreshold
+	}
+	return *threshold
+}
+
+// the usage is checked on a reconcile, there is no event when it changes
+func nextDiskUsageCheck(cr *brokerv1beta1.ActiveMQArtemis) (time.Time, bool) {
+	if cr.Spec.DiskPressure == nil {
+		return time.Time{}, false
+	}
+	period := defaultDiskUsageCheckPeriod
+	if cr.Spec.DiskPressure.CheckPeriod != nil && cr.Spec.DiskPressure.CheckPeriod.Duration > 0 {
+		period = cr.Spec.DiskPressure.CheckPeriod.Duration
+	}
+	return time.Now().Add(period), true
+}
+
+// journalStorageSize is the size of the journal volume claims, the spec size
+// or the larger size of an automatic expansion. The spec size is only
+// validated with persistenceEnabled, the default stands in for one that does
+// not parse.
+func journalStorageSize(customResource *brokerv1beta1.ActiveMQArtemis) resource.Quantity {
+	size := resource.MustParse(defaultJournalStorageSize)
+	if specSize, err := resource.ParseQuantity(customResource.Spec.DeploymentPlan.Storage.Size); err == nil {
+		size = specSize
+	}
+	if !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		return size
+	}
+	if expanded, err := resource.ParseQuantity(customResource.Status.ExpandedStorageSize); err == nil && expanded.Cmp(size) > 0 {
+		return expanded
+	}
+	return size
+}
+
+// ProcessDiskUsage reports the journal disk and address memory usage of each
+// broker and sets the DiskPressure condition while a broker passes a threshold.
+// A broker that can not be read keeps its last reported usage.
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessDiskUsage(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) (retry bool) {
+	// the spec caught up with an automatic expansion
+	if cr.Spec.DeploymentPlan.PersistenceEnabled && cr.Status.ExpandedStorageSize != "" {
+		if size := journalStorageSize(cr); size.String() != cr.Status.ExpandedStorageSize {
+			cr.Status.ExpandedStorageSize = ""
+		}
+	}
+
+	if cr.Spec.DiskPressure == nil {
+		cr.Status.DiskUsage = nil
+		meta.RemoveStatusCondition(&cr.Status.Conditions, brokerv1beta1.DiskPressureConditionType)
+		return false
+	}
+	diskThreshold := thresholdOrDefault(cr.Spec.DiskPressure.DiskUsageThreshold, defaultDiskUsageThreshold)
+	memoryThreshold := thresholdOrDefault(cr.Spec.DiskPressure.AddressMemoryUsageThreshold, defaultAddressMemoryUsageThreshold)
+
+	reconciler.resolveJolokiaEndpoints(cr, client)
+
+	var usage []brokerv1beta1.BrokerDiskUsage
+	var pressure []string
+	diskPressure := false
+	for _, jk := range reconciler.jolokiaEndpoints {
+		brokerUsage, err := readDiskUsage(jk.Artemis)
+		if err != nil {
+			reconciler.log.V(1).Info("error reading the disk usage with Jolokia", "IP", jk.IP, "Ordinal", jk.Ordinal, "error", err)
+			if previous := lastDiskUsageOf(cr, jk.Ordinal); previous != nil {
+				usage = append(usage, *previous)
+			}
+		} else {
+			brokerUsage.Ordinal = jk.Ordinal
+			usage = append(usage, brokerUsage)
+		}
+	}
+
+	for _, brokerUsage := range usage {
+		if brokerUsage.DiskUsage >= diskThreshold {
+			pressure = append(pressure, fmt.Sprintf("journal disk of broker %s above %d%%", brokerUsage.Ordinal, diskThreshold))
+			diskPressure = true
+		}
+		if percent, found := addressMemoryUsagePercent(brokerUsage); found && percent >= memoryThreshold {
+			pressure = append(pressure, fmt.Sprintf("address memory of broker %s above %d%%", brokerUsage.Ordinal, memoryThreshold))
+		}
+	}
+
+	cr.Status.DiskUsage = usage
+	if len(pressure) > 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    brokerv1beta1.DiskPressureConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  brokerv1beta1.DiskPressureThresholdReason,
+			Message: strings.Join(pressure, ", "),
+		})
+	} else {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, brokerv1beta1.DiskPressureConditionType)
+	}
+
+	return diskPressure && reconciler.autoExpandJournal(cr)
+}
+
+func readDiskUsage(artemis *mgmt.Artemis) (brokerv1beta1.BrokerDiskUsage, error) {
+	values := map[string]float64{}
+	for _, attribute := range []string{"DiskStoreUsage", "MaxDiskUsage", "AddressMemoryUsage", "GlobalMaxSize"} {
+		value, err := artemis.GetAttribute(attribute)
+		if err != nil {
+			return brokerv1beta1.BrokerDiskUsage{}, err
+		}
+		if values[attribute], err = strconv.ParseFloat(value, 64); err != nil {
+			return brokerv1beta1.BrokerDiskUsage{}, fmt.Errorf("unable to parse %s %q, %v", attribute, value, err)
+		}
+	}
+
+	usage := brokerv1beta1.BrokerDiskUsage{
+		// a fraction of the disk
+		DiskUsage:          int32(math.Round(values["DiskStoreUsage"] * 100)),
+		MaxDiskUsage:       int32(values["MaxDiskUsage"]),
+		AddressMemoryUsage: resource.NewQuantity(int64(values["AddressMemoryUsage"]), resource.BinarySI).String(),
+	}
+	if values["GlobalMaxSize"] > 0 {
+		usage.GlobalMaxSize = resource.NewQuantity(int64(values["GlobalMaxSize"]), resource.BinarySI).String()
+		usage.Paging = values["AddressMemoryUsage"] >= values["GlobalMaxSize"]
+	}
+	return usage, nil
+}
+
+func addressMemoryUsagePercent(usage brokerv1beta1.BrokerDiskUsage) (int32, bool) {
+	globalMaxSize, err := resource.ParseQuantity(usage.GlobalMaxSize)
+	if err != nil || globalMaxSize.Sign() <= 0 {
+		return 0, false
+	}
+	memoryUsage, err := resource.ParseQuantity(usage.AddressMemoryUsage)
+	if err != nil {
+		return 0, false
+	}
+	return int32(math.Round(float64(memoryUsage.Value()) * 100 / float64(globalMaxSize.Value()))), true
+}
+
+func lastDiskUsageOf(cr *brokerv1beta1.ActiveMQArtemis, ordinal string) *brokerv1beta1.BrokerDiskUsage {
+	for index := range cr.Status.DiskUsage {
+		if cr.Status.DiskUsage[index].Ordinal == ordinal {
+			return &cr.Status.DiskUsage[index]
+		}
+	}
+	return nil
+}
+
+// autoExpandJournal raises the size of the journal volume claims by the
+// increment, the next reconcile expands the claims. It waits for the claims
+// of a previous expansion and never goes past the max size.
+func (reconciler *ActiveMQArtemisReconcilerImpl) autoExpandJournal(cr *brokerv1beta1.ActiveMQArtemis) bool {
+	autoExpand := cr.Spec.DiskPressure.AutoExpand
+	if autoExpand == nil || !cr.Spec.DeploymentPlan.PersistenceEnabled || len(cr.Status.VolumeExpansion) > 0 || isPlanRequested(cr) {
+		return false
+	}
+	increment, err := resource.ParseQuantity(autoExpand.Increment)
+	if err != nil {
+		return false
+	}
+	maxSize, err := resource.ParseQuantity(autoExpand.MaxSize)
+	if err != nil {
+		return false
+	}
+
+	current := journalStorageSize(cr)
+	if current.Cmp(maxSize) >= 0 {
+		reconciler.log.V(1).Info("journal volumes are at the max size of the auto expansion", "size", current.String())
+		return false
+	}
+	next := current.DeepCopy()
+	next.Add(increment)
+	if next.Cmp(maxSize) > 0 {
+		next = maxSize
+	}
+	cr.Status.ExpandedStorageSize = next.String()
+	recordEvent(reconciler.recorder, cr, corev1.EventTypeNormal, EventReasonJournalAutoExpanded, MessageJournalAutoExpanded, current.String(), next.String())
+	return true
+}
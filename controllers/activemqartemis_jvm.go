@@ -0,0 +1,345 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the heap of the restricted brokers when spec.deploymentPlan.jvm has none
+	defaultRestrictedHeapPercentage = 70
+	// the share of the memory limit the heap can take, the rest is for the
+	// direct memory, the metaspace and the threads of the broker
+	maxHeapPercentageOfMemoryLimit = 80
+
+	flightRecorderMountPath = "/amq/jfr"
+	flightRecorderSubPath   = "jfr"
+)
+
+// the jvm options are expanded by the shell of the broker command, an extra
+// arg must be a single word without whitespace, quotes or shell metacharacters
+const jvmArgPunctuation = "-_.,:=+/@%"
+
+var jvmArgPattern = regexp.MustCompile(`^[A-Za-z0-9` + regexp.QuoteMeta(jvmArgPunctuation) + `]+$`)
+
+var garbageCollectors = map[string]string{
+	"G1":         "-XX:+UseG1GC",
+	"Parallel":   "-XX:+UseParallelGC",
+	"Serial":     "-XX:+UseSerialGC",
+	"Z":          "-XX:+UseZGC",
+	"Shenandoah": "-XX:+UseShenandoahGC",
+}
+
+func validateJvm(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	jvm := customResource.Spec.DeploymentPlan.Jvm
+	if jvm == nil {
+		return nil, false
+	}
+	invalid := func(format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidJvm,
+			Message: fmt.Sprintf(format, args...),
+		}, false
+	}
+
+	planMemoryLimit, planHasMemoryLimit := memoryLimitOf(&customResource.Spec.DeploymentPlan.Resources)
+	var maxHeap *resource.Quantity
+	if jvm.HeapPercentage != nil {
+		if *jvm.HeapPercentage < 1 || *jvm.HeapPercentage > maxHeapPercentageOfMemoryLimit {
+			return invalid("Spec.DeploymentPlan.Jvm.HeapPercentage %d must be between 1 and %d", *jvm.HeapPercentage, maxHeapPercentageOfMemoryLimit)
+		}
+		if jvm.InitialHeapSize != "" || jvm.MaxHeapSize != "" {
+			return invalid("Spec.DeploymentPlan.Jvm.HeapPercentage conflicts with Spec.DeploymentPlan.Jvm.InitialHeapSize and Spec.DeploymentPlan.Jvm.MaxHeapSize")
+		}
+		if !common.IsRestricted(customResource) {
+			// the heap is computed from the memory limit of the deployment plan
+			if !planHasMemoryLimit {
+				return invalid("Spec.DeploymentPlan.Jvm.HeapPercentage needs a memory limit in Spec.DeploymentPlan.Resources")
+			}
+			maxHeap = heapOfMemoryLimit(planMemoryLimit, *jvm.HeapPercentage)
+		}
+	}
+	var initialHeap *resource.Quantity
+	if jvm.InitialHeapSize != "" {
+		quantity, err := resource.ParseQuantity(jvm.InitialHeapSize)
+		if err != nil {
+			return invalid("Spec.DeploymentPlan.Jvm.InitialHeapSize %s is not a quantity, %v", jvm.InitialHeapSize, err)
+		}
+		initialHeap = &quantity
+	}
+	if jvm.MaxHeapSize != "" {
+		quantity, err := resource.ParseQuantity(jvm.MaxHeapSize)
+		if err != nil {
+			return invalid("Spec.DeploymentPlan.Jvm.MaxHeapSize %s is not a quantity, %v", jvm.MaxHeapSize, err)
+		}
+		maxHeap = &quantity
+	}
+	for field, size := range map[string]*resource.Quantity{"InitialHeapSize": initialHeap, "MaxHeapSize": maxHeap} {
+		if size != nil && size.Value() < 1024*1024 {
+			return invalid("Spec.DeploymentPlan.Jvm.%s %s must be at least 1Mi", field, size.String())
+		}
+	}
+	if initialHeap != nil && maxHeap != nil && initialHeap.Cmp(*maxHeap) > 0 {
+		return invalid("Spec.DeploymentPlan.Jvm.InitialHeapSize %s must not be greater than the max heap %s", initialHeap.String(), maxHeap.String())
+	}
+
+	// the heap must leave room in the memory limit of each broker
+	limitedHeap := maxHeap
+	if limitedHeap == nil {
+		limitedHeap = initialHeap
+	}
+	if limitedHeap != nil {
+		checkLimit := func(field string, resources *corev1.ResourceRequirements) (*metav1.Condition, bool) {
+			if resources == nil {
+				return nil, false
+			}
+			if limit, found := memoryLimitOf(resources); found {
+				if room := heapOfMemoryLimit(limit, maxHeapPercentageOfMemoryLimit); limitedHeap.Cmp(*room) > 0 {
+					return invalid("the heap %s of Spec.DeploymentPlan.Jvm is more than %d%% of the memory limit %s of %s", limitedHeap.String(), maxHeapPercentageOfMemoryLimit, limit.String(), field)
+				}
+			}
+			return nil, false
+		}
+		if condition, retry := checkLimit("Spec.DeploymentPlan.Resources", &customResource.Spec.DeploymentPlan.Resources); condition != nil {
+			return condition, retry
+		}
+		for index, group := range customResource.Spec.DeploymentPlan.BrokerGroups {
+			if condition, retry := checkLimit(fmt.Sprintf("Spec.DeploymentPlan.BrokerGroups[%d].Resources", index), group.Resources); condition != nil {
+				return condition, retry
+			}
+		}
+		for index, override := range customResource.Spec.DeploymentPlan.OrdinalOverrides {
+			if condition, retry := checkLimit(fmt.Sprintf("Spec.DeploymentPlan.OrdinalOverrides[%d].Resources", index), override.Resources); condition != nil {
+				return condition, retry
+			}
+		}
+	}
+
+	if _, found := garbageCollectors[jvm.GarbageCollector]; jvm.GarbageCollector != "" && !found {
+		return invalid("Spec.DeploymentPlan.Jvm.GarbageCollector %s is not one of G1, Parallel, Serial, Z or Shenandoah", jvm.GarbageCollector)
+	}
+
+	if recorder := jvm.FlightRecorder; recorder != nil {
+		if recorder.VolumeName == "" {
+			return invalid("Spec.DeploymentPlan.Jvm.FlightRecorder.VolumeName must be set")
+		}
+		if !isBrokerVolume(customResource, recorder.VolumeName) {
+			return invalid("Spec.DeploymentPlan.Jvm.FlightRecorder.VolumeName %s is not the data volume or a volume of Spec.DeploymentPlan.ExtraVolumes or Spec.DeploymentPlan.ExtraVolumeClaimTemplates", recorder.VolumeName)
+		}
+		if recorder.MaxAge != "" {
+			if _, err := time.ParseDuration(recorder.MaxAge); err != nil {
+				return invalid("Spec.DeploymentPlan.Jvm.FlightRecorder.MaxAge %s is not a duration, %v", recorder.MaxAge, err)
+			}
+		}
+		if recorder.MaxSize != "" {
+			if _, err := resource.ParseQuantity(recorder.MaxSize); err != nil {
+				return invalid("Spec.DeploymentPlan.Jvm.FlightRecorder.MaxSize %s is not a quantity, %v", recorder.MaxSize, err)
+			}
+		}
+		if recorder.Settings != "" && recorder.Settings != "default" && recorder.Settings != "profile" {
+			return invalid("Spec.DeploymentPlan.Jvm.FlightRecorder.Settings %s is not one of default or profile", recorder.Settings)
+		}
+	}
+
+	if jvm.HeapDumpOnOutOfMemory && !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		return invalid("Spec.DeploymentPlan.Jvm.HeapDumpOnOutOfMemory needs Spec.DeploymentPlan.PersistenceEnabled, the heap dumps are written to the data volume")
+	}
+
+	for index, arg := range jvm.ExtraArgs {
+		if strings.TrimSpace(arg) == "" {
+			return invalid("Spec.DeploymentPlan.Jvm.ExtraArgs[%d] must not be empty", index)
+		}
+		if !jvmArgPattern.MatchString(arg) {
+			return invalid("Spec.DeploymentPlan.Jvm.ExtraArgs[%d] %q must be a single argument of letters, digits and %s", index, arg, jvmArgPunctuation)
+		}
+	}
+	return nil, false
+}
+
+func memoryLimitOf(resources *corev1.ResourceRequirements) (resource.Quantity, bool) {
+	limit, found := resources.Limits[corev1.ResourceMemory]
+	return limit, found && !limit.IsZero()
+}
+
+func heapOfMemoryLimit(limit resource.Quantity, percentage int32) *resource.Quantity {
+	return resource.NewQuantity(limit.Value()*int64(percentage)/100, resource.BinarySI)
+}
+
+// isBrokerVolume is true for the data volume and the extra volumes of the
+// broker pods
+func isBrokerVolume(customResource *brokerv1beta1.ActiveMQArtemis, name string) bool {
+	if customResource.Spec.DeploymentPlan.PersistenceEnabled && name == customResource.Name {
+		return true
+	}
+	for _, volume := range customResource.Spec.DeploymentPlan.ExtraVolumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	for _, template := range customResource.Spec.DeploymentPlan.ExtraVolumeClaimTemplates {
+		if template.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// the sizes of the JVM options are in mebibytes
+func jvmSizeOf(quantity resource.Quantity) string {
+	return fmt.Sprintf("%dm", quantity.Value()/(1024*1024))
+}
+
+// heapOptionsOf sizes the heap as a percentage of the memory of the container
+// or in absolute sizes. The options of the restricted brokers come with their
+// own command line, the others are appended to those of artemis.profile and
+// must override its -Xms and -Xmx.
+func heapOptionsOf(customResource *brokerv1beta1.ActiveMQArtemis) []string {
+	restricted := common.IsRestricted(customResource)
+	jvm := customResource.Spec.DeploymentPlan.Jvm
+	if jvm == nil || (jvm.HeapPercentage == nil && jvm.InitialHeapSize == "" && jvm.MaxHeapSize == "") {
+		if restricted {
+			return []string{fmt.Sprintf("-XX:InitialRAMPercentage=%d.0 -XX:MaxRAMPercentage=%d.0", defaultRestrictedHeapPercentage, defaultRestrictedHeapPercentage)}
+		}
+		return nil
+	}
+
+	var options []string
+	if jvm.HeapPercentage != nil {
+		if restricted {
+			return []string{fmt.Sprintf("-XX:InitialRAMPercentage=%d.0 -XX:MaxRAMPercentage=%d.0", *jvm.HeapPercentage, *jvm.HeapPercentage)}
+		}
+		if limit, found := memoryLimitOf(&customResource.Spec.DeploymentPlan.Resources); found {
+			heap := jvmSizeOf(*heapOfMemoryLimit(limit, *jvm.HeapPercentage))
+			options = append(options, "-Xms"+heap, "-Xmx"+heap)
+		}
+		return options
+	}
+	if quantity, err := resource.ParseQuantity(jvm.InitialHeapSize); err == nil {
+		options = append(options, "-Xms"+jvmSizeOf(quantity))
+	}
+	if quantity, err := resource.ParseQuantity(jvm.MaxHeapSize); err == nil {
+		options = append(options, "-Xmx"+jvmSizeOf(quantity))
+	}
+	return options
+}
+
+// garbageCollectorOptionsOf selects the collector, artemis.profile selects
+// G1 for the brokers that are not restricted
+func garbageCollectorOptionsOf(customResource *brokerv1beta1.ActiveMQArtemis) []string {
+	restricted := common.IsRestricted(customResource)
+	collector := ""
+	if jvm := customResource.Spec.DeploymentPlan.Jvm; jvm != nil {
+		collector = jvm.GarbageCollector
+	}
+	if collector == "" || collector == "G1" {
+		if restricted {
+			return []string{"-XX:+UseG1GC -XX:+UseStringDeduplication"}
+		}
+		return nil
+	}
+	if restricted {
+		return []string{garbageCollectors[collector]}
+	}
+	return []string{"-XX:-UseG1GC", garbageCollectors[collector]}
+}
+
+func diagnosticOptionsOf(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers) []string {
+	jvm := customResource.Spec.DeploymentPlan.Jvm
+	if jvm == nil {
+		return nil
+	}
+	var options []string
+	if jvm.GCLogging {
+		options = append(options, "-Xlog:gc:stdout:time,uptime,level,tags")
+	}
+	if recorder := jvm.FlightRecorder; recorder != nil {
+		recording := "-XX:StartFlightRecording=name=continuous,disk=true,dumponexit=true,filename=" + flightRecorderMountPath + "/"
+		if recorder.MaxAge != "" {
+			if maxAge, err := time.ParseDuration(recorder.MaxAge); err == nil {
+				recording += fmt.Sprintf(",maxage=%ds", int64(maxAge.Seconds()))
+			}
+		}
+		if recorder.MaxSize != "" {
+			if maxSize, err := resource.ParseQuantity(recorder.MaxSize); err == nil {
+				recording += ",maxsize=" + jvmSizeOf(maxSize)
+			}
+		}
+		if recorder.Settings != "" {
+			recording += ",settings=" + recorder.Settings
+		}
+		options = append(options, recording)
+	}
+	if jvm.HeapDumpOnOutOfMemory {
+		options = append(options, "-XX:+HeapDumpOnOutOfMemoryError", "-XX:HeapDumpPath="+getDataMountPath(customResource, namer)+"/")
+	}
+	return append(options, jvm.ExtraArgs...)
+}
+
+// restrictedJvmOptionsOf is the jvm options of the command line of the
+// restricted brokers
+func restrictedJvmOptionsOf(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers) string {
+	options := heapOptionsOf(customResource)
+	options = append(options, "-XX:AutoBoxCacheMax=20000", "-XX:+PrintClassHistogram")
+	options = append(options, garbageCollectorOptionsOf(customResource)...)
+	options = append(options, "-Djava.net.preferIPv4Stack=true")
+	options = append(options, diagnosticOptionsOf(customResource, namer)...)
+	return strings.Join(options, " ")
+}
+
+// applyJvmToTemplate mounts the volume of the flight recordings on the broker
+// container and appends the jvm options of the brokers that are not
+// restricted to JAVA_ARGS_APPEND, after the options of artemis.profile
+func applyJvmToTemplate(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, template *corev1.PodTemplateSpec) {
+	jvm := customResource.Spec.DeploymentPlan.Jvm
+	if jvm == nil || len(template.Spec.Containers) == 0 {
+		return
+	}
+	broker := &template.Spec.Containers[0]
+	if jvm.FlightRecorder != nil {
+		broker.VolumeMounts = append(broker.VolumeMounts, corev1.VolumeMount{
+			Name:      jvm.FlightRecorder.VolumeName,
+			MountPath: flightRecorderMountPath,
+			SubPath:   flightRecorderSubPath,
+		})
+	}
+	if common.IsRestricted(customResource) {
+		return
+	}
+	var options []string
+	options = append(options, heapOptionsOf(customResource)...)
+	options = append(options, garbageCollectorOptionsOf(customResource)...)
+	options = append(options, diagnosticOptionsOf(customResource, namer)...)
+	if len(options) > 0 {
+		environments.CreateOrAppend(template.Spec.Containers[:1], &corev1.EnvVar{
+			Name:  javaArgsAppendEnvVarName,
+			Value: strings.Join(options, " "),
+		})
+	}
+}
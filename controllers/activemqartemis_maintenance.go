@@ -0,0 +1,213 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// the time zone of a window must not depend on the zoneinfo of the operator image
+	_ "time/tzdata"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type maintenanceWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+func parseMaintenanceWindow(spec *brokerv1beta1.MaintenanceWindowType) (*maintenanceWindow, error) {
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf(".Spec.MaintenanceWindow.Schedule %q is invalid, %v", spec.Schedule, err)
+	}
+	if spec.Duration.Duration <= 0 {
+		return nil, fmt.Errorf(".Spec.MaintenanceWindow.Duration %q must be positive", spec.Duration.Duration)
+	}
+	location := time.UTC
+	if spec.TimeZone != "" {
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf(".Spec.MaintenanceWindow.TimeZone %q is invalid, %v", spec.TimeZone, err)
+		}
+	}
+	return &maintenanceWindow{schedule: schedule, duration: spec.Duration.Duration, location: location}, nil
+}
+
+// a window is open when it started no longer than its duration ago
+func (w *maintenanceWindow) isOpen(now time.Time) bool {
+	return !w.schedule.Next(now.Add(-w.duration).In(w.location)).After(now)
+}
+
+func (w *maintenanceWindow) next(now time.Time) time.Time {
+	return w.schedule.Next(now.In(w.location))
+}
+
+func validateMaintenanceWindow(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	if customResource.Spec.MaintenanceWindow == nil {
+		return nil, false
+	}
+	if _, err := parseMaintenanceWindow(customResource.Spec.MaintenanceWindow); err != nil {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidMaintenanceWindow,
+			Message: err.Error(),
+		}, false
+	}
+	return nil, false
+}
+
+func isRolloutForced(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	if val, present := customResource.Annotations[common.RolloutNowAnnotation]; present {
+		if boolVal, err := strconv.ParseBool(val); err == nil {
+			return boolVal
+		}
+	}
+	return false
+}
+
+// the env vars that carry a checksum of the credentials and acceptors secrets
+// and the security config, a change of them is not held for a window
+var urgentTemplateEnvVars = []string{"TRIGGERED_ROLL_COUNT", "SECURITY_CFG_YAML"}
+
+// urgentTemplateChanges lists the changes of an image, a secret checksum or the
+// security config, that roll the brokers without waiting for a window
+func urgentTemplateChanges(deployed *corev1.PodTemplateSpec, requested *corev1.PodTemplateSpec) []string {
+	var urgent []string
+	for _, containers := range [][2][]corev1.Container{
+		{deployed.Spec.InitContainers, requested.Spec.InitContainers},
+		{deployed.Spec.Containers, requested.Spec.Containers},
+	} {
+		for _, container := range containers[1] {
+			deployedContainer := containerNamed(containers[0], container.Name)
+			if deployedContainer == nil {
+				continue
+			}
+			if deployedContainer.Image != container.Image {
+				urgent = append(urgent, container.Name+".image")
+			}
+			for _, name := range urgentTemplateEnvVars {
+				if envValueOf(deployedContainer.Env, name) != envValueOf(container.Env, name) {
+					urgent = append(urgent, container.Name+".env."+name)
+				}
+			}
+		}
+	}
+	return urgent
+}
+
+func containerNamed(containers []corev1.Container, name string) *corev1.Container {
+	for index := range containers {
+		if containers[index].Name == name {
+			return &containers[index]
+		}
+	}
+	return nil
+}
+
+func envValueOf(env []corev1.EnvVar, name string) string {
+	for _, envVar := range env {
+		if envVar.Name == name {
+			return envVar.Value
+		}
+	}
+	return ""
+}
+
+// holdForMaintenanceWindow keeps the deployed pod template outside of a window,
+// the other changes to the StatefulSet, like the replicas, still go out. A
+// template with an urgent change is not held, its other changes go out with it.
+// It returns true when a change to the pod template is held.
+func (reconciler *ActiveMQArtemisReconcilerImpl) holdForMaintenanceWindow(customResource *brokerv1beta1.ActiveMQArtemis, deployed *appsv1.StatefulSet, requested *appsv1.StatefulSet, now time.Time) bool {
+	if deployed == nil || customResource.Spec.MaintenanceWindow == nil || isRolloutForced(customResource) {
+		return false
+	}
+	window, err := parseMaintenanceWindow(customResource.Spec.MaintenanceWindow)
+	if err != nil || window.isOpen(now) {
+		return false
+	}
+	if equality.Semantic.DeepEqual(deployed.Spec.Template, requested.Spec.Template) {
+		return false
+	}
+	if urgent := urgentTemplateChanges(&deployed.Spec.Template, &requested.Spec.Template); len(urgent) > 0 {
+		reconciler.log.V(1).Info("rolling out urgent pod template changes outside of the maintenance window", "changes", urgent)
+		return false
+	}
+	for _, field := range changedFields(deployed, requested) {
+		if strings.HasPrefix(field, "spec.template") {
+			reconciler.pendingRollout = append(reconciler.pendingRollout, field)
+		}
+	}
+	reconciler.log.V(1).Info("holding pod template changes for the maintenance window", "changes", reconciler.pendingRollout)
+	requested.Spec.Template = *deployed.Spec.Template.DeepCopy()
+	return true
+}
+
+func updateMaintenanceStatus(customResource *brokerv1beta1.ActiveMQArtemis, pending []string, now time.Time) {
+	if customResource.Spec.MaintenanceWindow == nil {
+		customResource.Status.Maintenance = nil
+		return
+	}
+	window, err := parseMaintenanceWindow(customResource.Spec.MaintenanceWindow)
+	if err != nil {
+		// reported by validation
+		return
+	}
+
+	status := &brokerv1beta1.MaintenanceStatus{
+		NextWindow:     &metav1.Time{Time: window.next(now).Local()},
+		PendingChanges: pending,
+	}
+	if len(pending) > 0 {
+		status.PendingSince = &metav1.Time{Time: now}
+		if previous := customResource.Status.Maintenance; previous != nil && previous.PendingSince != nil {
+			status.PendingSince = previous.PendingSince
+		}
+	}
+	customResource.Status.Maintenance = status
+}
+
+// pending changes go out on a reconcile at the start of the next window
+func nextMaintenanceWindow(cr *brokerv1beta1.ActiveMQArtemis) (time.Time, bool) {
+	status := cr.Status.Maintenance
+	if status == nil || len(status.PendingChanges) == 0 || status.NextWindow == nil {
+		return time.Time{}, false
+	}
+	return status.NextWindow.Time, true
+}
+
+// the earliest of the schedules that need a reconcile without a change to the CR
+func nextScheduledReconcile(cr *brokerv1beta1.ActiveMQArtemis) (time.Time, bool) {
+	next, scheduled := nextCredentialRotation(cr)
+	if window, pending := nextMaintenanceWindow(cr); pending && (!scheduled || window.Before(next)) {
+		next, scheduled = window, true
+	}
+	if check, monitored := nextDiskUsageCheck(cr); monitored && (!scheduled || check.Before(next)) {
+		next, scheduled = check, true
+	}
+	return next, scheduled
+}
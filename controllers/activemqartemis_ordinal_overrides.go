@@ -0,0 +1,301 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/adler32"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// the gate of the pods created by a previous version of the operator, which
+	// applied the override of their ordinal before it removed the gate
+	ordinalOverridesSchedulingGate = "arkmq.org/ordinal-overrides"
+
+	// the pod template annotation that rolls the brokers when the overrides change
+	ordinalOverridesChecksumAnnotation = "arkmq.org/ordinal-overrides-checksum"
+
+	// the pod annotation set by the pod webhook once it applied the override and
+	// the placement zone of the ordinal of the pod
+	ordinalOverridesAppliedAnnotation = "arkmq.org/ordinal-overrides-applied"
+)
+
+// the labels of the StatefulSet of the pod, an override must not change them
+var statefulSetPodLabels = map[string]bool{
+	appsv1.StatefulSetPodNameLabel:        true,
+	appsv1.PodIndexLabel:                  true,
+	appsv1.ControllerRevisionHashLabelKey: true,
+}
+
+func validateOrdinalOverrides(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	invalid := func(format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidOrdinalOverrides,
+			Message: fmt.Sprintf(format, args...),
+		}, false
+	}
+
+	internalEnv := map[string]bool{
+		debugArgsEnvVarName:      true,
+		javaOptsEnvVarName:       true,
+		jdkJavaOptionsEnvVarName: true,
+	}
+
+	ordinals := map[int32]bool{}
+	for index, override := range customResource.Spec.DeploymentPlan.OrdinalOverrides {
+		field := fmt.Sprintf("Spec.DeploymentPlan.OrdinalOverrides[%d]", index)
+		if override.Ordinal < 0 {
+			return invalid("%s.Ordinal %d must not be negative", field, override.Ordinal)
+		}
+		if ordinals[override.Ordinal] {
+			return invalid("%s.Ordinal %d has more than one override", field, override.Ordinal)
+		}
+		ordinals[override.Ordinal] = true
+
+		for _, envVar := range override.Env {
+			if internalEnv[envVar.Name] {
+				return invalid("%s.Env %s can not be overridden, it is set by the operator", field, envVar.Name)
+			}
+		}
+		for key := range override.Labels {
+			if key == selectors.LabelAppKey || key == selectors.LabelResourceKey || statefulSetPodLabels[key] {
+				return invalid("'%s' is a reserved label, it is not allowed in %s.Labels", key, field)
+			}
+		}
+	}
+	return nil, false
+}
+
+// validatePodWebhook checks that the pod webhook is set up, it applies the
+// overrides and the placement zones to the pods when they are created
+func validatePodWebhook(customResource *brokerv1beta1.ActiveMQArtemis, podWebhookEnabled bool) (*metav1.Condition, bool) {
+	if podWebhookEnabled {
+		return nil, false
+	}
+	invalid := func(reason string, field string) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: fmt.Sprintf("%s needs the pod webhook of the operator, set ENABLE_WEBHOOKS=true", field),
+		}, false
+	}
+	if len(customResource.Spec.DeploymentPlan.OrdinalOverrides) > 0 {
+		return invalid(brokerv1beta1.ValidConditionFailedInvalidOrdinalOverrides, "Spec.DeploymentPlan.OrdinalOverrides")
+	}
+	if customResource.Spec.DeploymentPlan.ZonePlacement != nil {
+		return invalid(brokerv1beta1.ValidConditionFailedInvalidZonePlacement, "Spec.DeploymentPlan.ZonePlacement")
+	}
+	return nil, false
+}
+
+func ordinalOverrideOf(customResource *brokerv1beta1.ActiveMQArtemis, ordinal int32) *brokerv1beta1.OrdinalOverrideType {
+	for index := range customResource.Spec.DeploymentPlan.OrdinalOverrides {
+		if customResource.Spec.DeploymentPlan.OrdinalOverrides[index].Ordinal == ordinal {
+			return &customResource.Spec.DeploymentPlan.OrdinalOverrides[index]
+		}
+	}
+	return nil
+}
+
+// isOrdinalMutated is true when the pod of the ordinal has an override or a
+// placement zone to get from the pod webhook
+func isOrdinalMutated(customResource *brokerv1beta1.ActiveMQArtemis, ordinal int32) bool {
+	return ordinalOverrideOf(customResource, ordinal) != nil || customResource.Spec.DeploymentPlan.ZonePlacement != nil
+}
+
+// applyOrdinalOverridesToTemplate records a checksum of the overrides, the
+// pod webhook applies them to the pods as they are created
+func applyOrdinalOverridesToTemplate(customResource *brokerv1beta1.ActiveMQArtemis, template *corev1.PodTemplateSpec) {
+	// the template is built over the deployed one
+	template.Spec.SchedulingGates = withoutSchedulingGate(template.Spec.SchedulingGates, ordinalOverridesSchedulingGate)
+	overrides := customResource.Spec.DeploymentPlan.OrdinalOverrides
+	if len(overrides) == 0 {
+		return
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	serialized, _ := json.Marshal(overrides)
+	digest := adler32.New()
+	digest.Write(serialized)
+	template.Annotations[ordinalOverridesChecksumAnnotation] = hex.EncodeToString(digest.Sum(nil))
+}
+
+// applyOrdinalOverrideToPod applies the override and the placement zone of
+// its ordinal to a pod that is being created, false when it has neither
+func applyOrdinalOverrideToPod(customResource *brokerv1beta1.ActiveMQArtemis, pair *brokerv1beta1.ActiveMQArtemis, ordinal int32, pod *corev1.Pod) bool {
+	if !isOrdinalMutated(customResource, ordinal) || len(pod.Spec.Containers) == 0 {
+		return false
+	}
+	if override := ordinalOverrideOf(customResource, ordinal); override != nil {
+		container := &pod.Spec.Containers[0]
+		if override.Resources != nil {
+			container.Resources = *override.Resources
+		}
+		for _, envVar := range override.Env {
+			replaced := false
+			for index := range container.Env {
+				if container.Env[index].Name == envVar.Name {
+					container.Env[index] = envVar
+					replaced = true
+				}
+			}
+			if !replaced {
+				container.Env = append(container.Env, envVar)
+			}
+		}
+		if len(override.Labels) > 0 && pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		for key, value := range override.Labels {
+			pod.Labels[key] = value
+		}
+		if len(override.Annotations) > 0 && pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		for key, value := range override.Annotations {
+			pod.Annotations[key] = value
+		}
+		if len(override.NodeSelector) > 0 && pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = map[string]string{}
+		}
+		for key, value := range override.NodeSelector {
+			pod.Spec.NodeSelector[key] = value
+		}
+		for _, toleration := range override.Tolerations {
+			if !hasToleration(pod.Spec.Tolerations, toleration) {
+				pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+			}
+		}
+	}
+	applyZonePlacementToPod(customResource, pair, ordinal, pod)
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[ordinalOverridesAppliedAnnotation] = "true"
+	return true
+}
+
+// ProcessOrdinalOverrides is the fallback of the pod webhook, which fails open
+// so that the brokers are created while the operator is down. A pod that was
+// created without the override or the placement zone of its ordinal is deleted,
+// one at a time once the other brokers are ready, and the StatefulSet creates
+// it again through the webhook.
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessOrdinalOverrides(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client) (retry bool) {
+	var notApplied []*corev1.Pod
+	var unready []string
+	for ordinal := int32(0); ordinal < common.GetDeploymentSize(customResource); ordinal++ {
+		pod := &corev1.Pod{}
+		podName := namer.SsNameBuilder.Name() + "-" + strconv.Itoa(int(ordinal))
+		if !retrieveResource(podName, customResource.Namespace, pod, client) || pod.DeletionTimestamp != nil {
+			unready = append(unready, podName)
+			continue
+		}
+		// a pod gated by a previous version of the operator is not running yet
+		if hasSchedulingGate(pod, ordinalOverridesSchedulingGate) {
+			if err := client.Delete(context.TODO(), pod); err != nil && !apierrors.IsNotFound(err) {
+				reconciler.log.V(1).Info("unable to delete the gated pod, will retry", "pod", podName, "error", err)
+			}
+			unready = append(unready, podName)
+			retry = true
+			continue
+		}
+		if !isPodReady(pod) {
+			unready = append(unready, podName)
+		}
+		if isOrdinalMutated(customResource, ordinal) && pod.Annotations[ordinalOverridesAppliedAnnotation] == "" {
+			notApplied = append(notApplied, pod)
+		}
+	}
+
+	if len(notApplied) > 0 {
+		var names []string
+		for _, pod := range notApplied {
+			names = append(names, pod.Name)
+		}
+		meta.SetStatusCondition(&customResource.Status.Conditions, metav1.Condition{
+			Type:    brokerv1beta1.OrdinalOverridesAppliedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.OrdinalOverridesAppliedConditionNotAppliedReason,
+			Message: fmt.Sprintf("pods %s were created without the pod webhook, they are recreated one at a time", strings.Join(names, ", ")),
+		})
+		// a pod is only deleted when the other brokers are ready
+		for _, pod := range notApplied {
+			if common.PauseOf(customResource).StatefulSet {
+				break
+			}
+			if len(unready) == 0 || len(unready) == 1 && unready[0] == pod.Name {
+				recordEvent(reconciler.recorder, customResource, corev1.EventTypeWarning, EventReasonOverridesNotApplied, MessageOverridesNotApplied, pod.Name)
+				if err := client.Delete(context.TODO(), pod); err != nil && !apierrors.IsNotFound(err) {
+					reconciler.log.V(1).Info("unable to delete the pod created without its override, will retry", "pod", pod.Name, "error", err)
+				}
+				break
+			}
+		}
+		return true
+	}
+
+	if len(customResource.Spec.DeploymentPlan.OrdinalOverrides) == 0 && customResource.Spec.DeploymentPlan.ZonePlacement == nil {
+		meta.RemoveStatusCondition(&customResource.Status.Conditions, brokerv1beta1.OrdinalOverridesAppliedConditionType)
+	} else if !retry {
+		meta.SetStatusCondition(&customResource.Status.Conditions, metav1.Condition{
+			Type:   brokerv1beta1.OrdinalOverridesAppliedConditionType,
+			Status: metav1.ConditionTrue,
+			Reason: brokerv1beta1.OrdinalOverridesAppliedConditionAppliedReason,
+		})
+	}
+	return retry
+}
+
+func hasSchedulingGate(pod *corev1.Pod, name string) bool {
+	for _, gate := range pod.Spec.SchedulingGates {
+		if gate.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, existing := range tolerations {
+		if equality.Semantic.DeepEqual(existing, toleration) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
+	ss "github.com/arkmq-org/activemq-artemis-operator/pkg/resources/statefulsets"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isPausedResource tells if ProcessResources must leave a resource as deployed,
+// the headless and ping services are needed by the pods so they are not exposure
+func isPausedResource(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, kind reflect.Type, name string) bool {
+	pause := common.PauseOf(customResource)
+	switch kind {
+	case reflect.TypeOf(appsv1.StatefulSet{}):
+		return pause.StatefulSet
+	case reflect.TypeOf(corev1.Service{}):
+		return pause.Exposure && name != namer.SvcHeadlessNameBuilder.Name() && name != namer.SvcPingNameBuilder.Name()
+	case reflect.TypeOf(netv1.Ingress{}), reflect.TypeOf(routev1.Route{}):
+		return pause.Exposure
+	}
+	return false
+}
+
+// deployedBrokerProperties requests the deployed broker properties resource
+// unchanged, so that paused properties keep the mount and content of the pods
+func (reconciler *ActiveMQArtemisReconcilerImpl) deployedBrokerProperties(customResource *brokerv1beta1.ActiveMQArtemis) (string, bool, map[string]string, bool) {
+	for _, obj := range reconciler.deployed[reflect.TypeOf(corev1.ConfigMap{})] {
+		if strings.HasPrefix(obj.GetName(), customResource.Name+"-props-") {
+			existing := obj.DeepCopyObject().(*corev1.ConfigMap)
+			reconciler.trackDesired(existing)
+			return existing.Name, false, existing.Data, true
+		}
+	}
+
+	obj := reconciler.cloneOfDeployed(reflect.TypeOf(corev1.Secret{}), getPropertiesResourceNsName(customResource).Name)
+	if obj == nil {
+		return "", false, nil, false
+	}
+	existing := mergeSecretStringDataToData(obj.(*corev1.Secret))
+	reconciler.trackDesired(existing)
+
+	data := make(map[string]string, len(existing.Data))
+	for k, v := range existing.Data {
+		data[k] = string(v)
+	}
+	return existing.Name, true, data, true
+}
+
+// the annotation of the StatefulSet with the security config its pod template
+// was built with, replayed while address and security are paused
+const securityConfigAnnotation = "arkmq.org/security-config"
+
+// appliedSecurityConfig is the security config of a pod template, the commands
+// and env vars that the handler of a security CR added to the init container
+type appliedSecurityConfig struct {
+	CRName   string          `json:"crName,omitempty"`
+	Commands []string        `json:"commands,omitempty"`
+	Env      []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// brokerConfigHandlerFor returns the security config handler of a CR, when
+// address and security are paused it is the one recorded on the deployed
+// StatefulSet, so that a restart of the operator does not pick up a newer
+// security CR
+func (reconciler *ActiveMQArtemisReconcilerImpl) brokerConfigHandlerFor(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers) common.ActiveMQArtemisConfigHandler {
+	key := types.NamespacedName{Namespace: customResource.Namespace, Name: customResource.Name}
+	if !common.PauseOf(customResource).AddressAndSecurity {
+		return GetBrokerConfigHandler(key)
+	}
+	deployed, _ := reconciler.getFromDeployed(reflect.TypeOf(appsv1.StatefulSet{}), namer.SsNameBuilder.Name()).(*appsv1.StatefulSet)
+	applied, recorded := appliedSecurityConfigOf(deployed)
+	if !recorded {
+		// nothing to hold yet, or a StatefulSet deployed before the config was recorded
+		return GetBrokerConfigHandler(key)
+	}
+	if applied.CRName == "" {
+		return nil
+	}
+	return applied
+}
+
+func appliedSecurityConfigOf(statefulSet *appsv1.StatefulSet) (*appliedSecurityConfig, bool) {
+	if statefulSet == nil {
+		return nil, false
+	}
+	value, found := statefulSet.Annotations[securityConfigAnnotation]
+	if !found {
+		return nil, false
+	}
+	applied := &appliedSecurityConfig{}
+	if err := json.Unmarshal([]byte(value), applied); err != nil {
+		return nil, false
+	}
+	return applied, true
+}
+
+// recordSecurityConfig keeps the config of the handler that built the pod
+// template, it is annotated on the StatefulSet so that it does not roll the pods
+func (reconciler *ActiveMQArtemisReconcilerImpl) recordSecurityConfig(handler common.ActiveMQArtemisConfigHandler, commands []string, initContainers []corev1.Container) {
+	reconciler.appliedSecurity = appliedSecurityConfig{}
+	if handler == nil || len(commands) == 0 {
+		return
+	}
+	reconciler.appliedSecurity = appliedSecurityConfig{CRName: handler.GetCRName(), Commands: commands}
+	for _, name := range []string{"SECURITY_CFG_YAML", "YACFG_PROFILE_VERSION", "YACFG_PROFILE_NAME"} {
+		if envVar := environments.Retrieve(initContainers, name); envVar != nil {
+			reconciler.appliedSecurity.Env = append(reconciler.appliedSecurity.Env, *envVar)
+		}
+	}
+}
+
+// annotateSecurityConfig records the applied security config, an empty one is only
+// recorded while paused so that no security CR is picked up until the pause ends
+func annotateSecurityConfig(customResource *brokerv1beta1.ActiveMQArtemis, statefulSet *appsv1.StatefulSet, applied appliedSecurityConfig) {
+	if applied.CRName == "" && !common.PauseOf(customResource).AddressAndSecurity {
+		delete(statefulSet.Annotations, securityConfigAnnotation)
+		return
+	}
+	value, _ := json.Marshal(applied)
+	if statefulSet.Annotations == nil {
+		statefulSet.Annotations = map[string]string{}
+	}
+	statefulSet.Annotations[securityConfigAnnotation] = string(value)
+}
+
+func (h *appliedSecurityConfig) GetCRName() string {
+	return h.CRName
+}
+
+func (h *appliedSecurityConfig) IsApplicableFor(brokerNamespacedName types.NamespacedName) bool {
+	return true
+}
+
+func (h *appliedSecurityConfig) Config(initContainers []corev1.Container, outputDirRoot string, yacfgProfileVersion string, yacfgProfileName string) []string {
+	for index := range h.Env {
+		environments.Create(initContainers, &h.Env[index])
+	}
+	return h.Commands
+}
+
+// withoutPausedAddressTargets drops the StatefulSets of the CRs that pause
+// address and security, a StatefulSet without a CR is kept
+func withoutPausedAddressTargets(client rtclient.Client, ssInfos []ss.StatefulSetInfo, log logr.Logger) []ss.StatefulSetInfo {
+	var result []ss.StatefulSetInfo
+	for _, ssInfo := range ssInfos {
+		cr := &brokerv1beta1.ActiveMQArtemis{}
+		crName := types.NamespacedName{Namespace: ssInfo.NamespacedName.Namespace, Name: namer.SSToCr(ssInfo.NamespacedName.Name)}
+		if err := client.Get(context.TODO(), crName, cr); err == nil && common.PauseOf(cr).AddressAndSecurity {
+			log.V(1).Info("skipping brokers of a CR that pauses address and security", "CR", crName)
+			continue
+		}
+		result = append(result, ssInfo)
+	}
+	return result
+}
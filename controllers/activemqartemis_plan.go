@@ -0,0 +1,332 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func isPlanRequested(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	if val, present := customResource.Annotations[common.PlanAnnotation]; present {
+		if boolVal, err := strconv.ParseBool(val); err == nil {
+			return boolVal
+		}
+	}
+	return false
+}
+
+// Plan runs Process against the deployed resources and returns the changes that
+// it would make. The steps of Process that act on their own, like the drainer
+// of message migration, record a planned change instead, and the client of
+// Process refuses any write that is not guarded.
+func (reconciler *ActiveMQArtemisReconcilerImpl) Plan(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme) (*brokerv1beta1.PlanStatus, error) {
+	plan := &brokerv1beta1.PlanStatus{ObservedGeneration: customResource.Generation}
+	reconciler.plan = plan
+	defer func() { reconciler.plan = nil }()
+
+	if err := reconciler.Process(customResource, namer, &planClient{Client: client}, scheme); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) planning() bool {
+	return reconciler.plan != nil
+}
+
+var errPlanWrite = errors.New("a plan does not write to the cluster")
+
+// planClient reads from the cluster and refuses every write
+type planClient struct {
+	rtclient.Client
+}
+
+func (c *planClient) Create(ctx context.Context, obj rtclient.Object, opts ...rtclient.CreateOption) error {
+	return errPlanWrite
+}
+
+func (c *planClient) Update(ctx context.Context, obj rtclient.Object, opts ...rtclient.UpdateOption) error {
+	return errPlanWrite
+}
+
+func (c *planClient) Patch(ctx context.Context, obj rtclient.Object, patch rtclient.Patch, opts ...rtclient.PatchOption) error {
+	return errPlanWrite
+}
+
+func (c *planClient) Delete(ctx context.Context, obj rtclient.Object, opts ...rtclient.DeleteOption) error {
+	return errPlanWrite
+}
+
+func (c *planClient) DeleteAllOf(ctx context.Context, obj rtclient.Object, opts ...rtclient.DeleteAllOfOption) error {
+	return errPlanWrite
+}
+
+func (c *planClient) Status() rtclient.SubResourceWriter {
+	return &planSubResourceClient{}
+}
+
+func (c *planClient) SubResource(subResource string) rtclient.SubResourceClient {
+	return &planSubResourceClient{reader: c.Client.SubResource(subResource)}
+}
+
+type planSubResourceClient struct {
+	reader rtclient.SubResourceReader
+}
+
+func (c *planSubResourceClient) Get(ctx context.Context, obj rtclient.Object, subResource rtclient.Object, opts ...rtclient.SubResourceGetOption) error {
+	if c.reader == nil {
+		return errPlanWrite
+	}
+	return c.reader.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *planSubResourceClient) Create(ctx context.Context, obj rtclient.Object, subResource rtclient.Object, opts ...rtclient.SubResourceCreateOption) error {
+	return errPlanWrite
+}
+
+func (c *planSubResourceClient) Update(ctx context.Context, obj rtclient.Object, opts ...rtclient.SubResourceUpdateOption) error {
+	return errPlanWrite
+}
+
+func (c *planSubResourceClient) Patch(ctx context.Context, obj rtclient.Object, patch rtclient.Patch, opts ...rtclient.SubResourcePatchOption) error {
+	return errPlanWrite
+}
+
+// recordPlan reports the deltas left once the paused resources are filtered
+// out, with the pod templates held for the maintenance window and the
+// StatefulSets recreated for the expansion of their volume claims
+func (reconciler *ActiveMQArtemisReconcilerImpl) recordPlan(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, deltas map[reflect.Type]compare.ResourceDelta) {
+	propertiesSecretName := getPropertiesResourceNsName(customResource).Name
+
+	for _, resourceType := range getOrderedTypeList() {
+		delta, ok := deltas[resourceType]
+		if !ok {
+			continue
+		}
+		for _, added := range sortedByName(delta.Added) {
+			reconciler.addPlannedChange(resourceType, added, brokerv1beta1.PlanActionCreate, nil)
+			if added.GetName() == propertiesSecretName {
+				reconciler.addPlannedPropertyChanges(nil, added.(*corev1.Secret))
+			}
+		}
+		for _, updated := range sortedByName(delta.Updated) {
+			deployed := reconciler.getFromDeployed(resourceType, updated.GetName())
+			held := false
+			if isStatefulSetType(resourceType) {
+				deployedStatefulSet, _ := deployed.(*appsv1.StatefulSet)
+				held = reconciler.holdForMaintenanceWindow(customResource, deployedStatefulSet, updated.(*appsv1.StatefulSet), time.Now())
+				if reconciler.expandVolumeClaims(customResource, client, deployedStatefulSet, updated.(*appsv1.StatefulSet), held) {
+					continue
+				}
+			}
+			fields := changedFields(deployed, updated)
+			if held && len(fields) == 0 {
+				// only the held pod template differs
+				continue
+			}
+			reconciler.addPlannedChange(resourceType, updated, brokerv1beta1.PlanActionUpdate, fields)
+
+			switch resourceType {
+			case reflect.TypeOf(appsv1.StatefulSet{}):
+				for _, field := range fields {
+					if strings.HasPrefix(field, "spec.template.") {
+						reconciler.plan.RollsPods = true
+					}
+				}
+			case reflect.TypeOf(corev1.Secret{}):
+				if updated.GetName() == propertiesSecretName && deployed != nil {
+					reconciler.addPlannedPropertyChanges(deployed.(*corev1.Secret), updated.(*corev1.Secret))
+				}
+			}
+		}
+		for _, removed := range sortedByName(delta.Removed) {
+			reconciler.addPlannedChange(resourceType, removed, brokerv1beta1.PlanActionDelete, nil)
+		}
+	}
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) addPlannedChange(resourceType reflect.Type, obj rtclient.Object, action brokerv1beta1.PlanAction, fields []string) {
+	reconciler.plan.Resources = append(reconciler.plan.Resources, brokerv1beta1.PlannedResourceChange{
+		Kind:   resourceType.Name(),
+		Name:   obj.GetName(),
+		Action: action,
+		Fields: fields,
+	})
+}
+
+func sortedByName(objs []rtclient.Object) []rtclient.Object {
+	sorted := append([]rtclient.Object{}, objs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
+	return sorted
+}
+
+// the paths of the labels, annotations and spec, or secret data, that differ
+func changedFields(deployed, requested rtclient.Object) []string {
+	if deployed == nil {
+		return nil
+	}
+	var fields []string
+	if deployedSecret, ok := deployed.(*corev1.Secret); ok {
+		deployedData := mergeSecretStringDataToData(deployedSecret).Data
+		requestedData := mergeSecretStringDataToData(requested.(*corev1.Secret)).Data
+		diffPaths("metadata", contentForDiff(deployed, "metadata"), contentForDiff(requested, "metadata"), &fields)
+		for _, key := range sortedKeysOf(unionOf(deployedData, requestedData)) {
+			if string(deployedData[key]) != string(requestedData[key]) {
+				fields = append(fields, pathOf("data", key))
+			}
+		}
+		return fields
+	}
+	for _, field := range []string{"metadata", "spec", "data"} {
+		diffPaths(field, contentForDiff(deployed, field), contentForDiff(requested, field), &fields)
+	}
+	return fields
+}
+
+// the json content of a top level field, only labels and annotations of the metadata
+func contentForDiff(obj rtclient.Object, field string) interface{} {
+	if field == "metadata" {
+		return map[string]interface{}{
+			"labels":      toInterfaceMap(obj.GetLabels()),
+			"annotations": toInterfaceMap(obj.GetAnnotations()),
+		}
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil
+	}
+	return content[field]
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func unionOf[V any](a, b map[string]V) map[string]bool {
+	union := map[string]bool{}
+	for k := range a {
+		union[k] = true
+	}
+	for k := range b {
+		union[k] = true
+	}
+	return union
+}
+
+func diffPaths(path string, deployed, requested interface{}, paths *[]string) {
+	switch d := deployed.(type) {
+	case map[string]interface{}:
+		if r, ok := requested.(map[string]interface{}); ok {
+			for _, key := range sortedKeysOf(unionOf(d, r)) {
+				diffPaths(pathOf(path, key), d[key], r[key], paths)
+			}
+			return
+		}
+	case []interface{}:
+		// an insertion shifts every following entry, report the whole list
+		if r, ok := requested.([]interface{}); ok && len(d) == len(r) {
+			for i := range d {
+				diffPaths(fmt.Sprintf("%s[%d]", path, i), d[i], r[i], paths)
+			}
+			return
+		}
+	}
+	if !equality.Semantic.DeepEqual(deployed, requested) {
+		*paths = append(*paths, path)
+	}
+}
+
+func pathOf(parent, key string) string {
+	if strings.ContainsAny(key, "./") {
+		return fmt.Sprintf("%s[%q]", parent, key)
+	}
+	return parent + "." + key
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) addPlannedPropertyChanges(deployed, requested *corev1.Secret) {
+	var deployedData map[string][]byte
+	if deployed != nil {
+		deployedData = mergeSecretStringDataToData(deployed).Data
+	}
+	requestedData := mergeSecretStringDataToData(requested).Data
+
+	for _, file := range sortedKeysOf(unionOf(deployedData, requestedData)) {
+		deployedProps := parseProperties(string(deployedData[file]))
+		requestedProps := parseProperties(string(requestedData[file]))
+		for _, key := range sortedKeysOf(unionOf(deployedProps, requestedProps)) {
+			deployedValue, wasDeployed := deployedProps[key]
+			requestedValue, isRequested := requestedProps[key]
+			var action brokerv1beta1.PlanAction
+			switch {
+			case !wasDeployed:
+				action = brokerv1beta1.PlanActionCreate
+			case !isRequested:
+				action = brokerv1beta1.PlanActionDelete
+			case deployedValue != requestedValue:
+				action = brokerv1beta1.PlanActionUpdate
+			default:
+				continue
+			}
+			reconciler.plan.BrokerProperties = append(reconciler.plan.BrokerProperties, brokerv1beta1.PlannedPropertyChange{
+				File:   file,
+				Key:    key,
+				Action: action,
+			})
+		}
+	}
+}
+
+// key value pairs of a properties file, the last value of a repeated key wins as it does on the broker
+func parseProperties(contents string) map[string]string {
+	props := map[string]string{}
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		props[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return props
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/hex"
+	"hash/adler32"
+	"reflect"
+	"sort"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// the pod template annotation that rolls the brokers when a property that is
+// only read on start changes
+const restartPropertiesChecksumAnnotation = "arkmq.org/restart-properties-checksum"
+
+// the configuration the broker applies on a reload of the properties, by the
+// first segment of the key. Any other key takes effect on restart only
+var reloadablePropertyPrefixes = map[string]bool{
+	"addressSettings":       true,
+	"securityRoles":         true,
+	"addressConfigurations": true,
+	"divertConfigurations":  true,
+	"bridgeConfigurations":  true,
+	"AMQPConnections":       true,
+}
+
+func isReloadableProperty(key string) bool {
+	prefix, _, _ := strings.Cut(key, ".")
+	return reloadablePropertyPrefixes[prefix]
+}
+
+// restart required properties of the files generated from spec.brokerProperties,
+// the keys of an ordinal file are prefixed with its ordinal like in the spec
+func restartRequiredProperties(data map[string]string) map[string]string {
+	properties := map[string]string{}
+	for file, contents := range data {
+		if !strings.HasSuffix(file, BrokerPropertiesName) {
+			continue
+		}
+		ordinalPrefix := strings.TrimSuffix(file, BrokerPropertiesName)
+		for key, value := range parseProperties(contents) {
+			if !isReloadableProperty(key) {
+				properties[ordinalPrefix+key] = value
+			}
+		}
+	}
+	return properties
+}
+
+func restartPropertiesChecksum(properties map[string]string) string {
+	digest := adler32.New()
+	for _, key := range sortedKeysOf(properties) {
+		digest.Write([]byte(key + "=" + properties[key] + "\n"))
+	}
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// trackRestartRequiredProperties annotates the pod template with a checksum of
+// the restart required properties, so that only their changes roll the brokers.
+// A template without the annotation only gets it on a change, an upgrade of the
+// operator does not roll the brokers.
+func (reconciler *ActiveMQArtemisReconcilerImpl) trackRestartRequiredProperties(customResource *brokerv1beta1.ActiveMQArtemis, deployed map[string][]byte, requested map[string]string, template *corev1.PodTemplateSpec) {
+	after := restartRequiredProperties(requested)
+	if deployed != nil {
+		deployedData := map[string]string{}
+		for file, contents := range deployed {
+			deployedData[file] = string(contents)
+		}
+		before := restartRequiredProperties(deployedData)
+		for _, key := range sortedKeysOf(unionOf(before, after)) {
+			if deployedValue, found := before[key]; !found || deployedValue != after[key] {
+				reconciler.restartProperties = append(reconciler.restartProperties, key)
+			}
+		}
+	}
+
+	_, annotated := template.Annotations[restartPropertiesChecksumAnnotation]
+	if annotated || len(reconciler.restartProperties) > 0 || len(customResource.Status.PropertiesPendingRestart) > 0 {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[restartPropertiesChecksumAnnotation] = restartPropertiesChecksum(after)
+	}
+	reconciler.restartChecksum = template.Annotations[restartPropertiesChecksumAnnotation]
+}
+
+// updatePropertiesPendingRestart reports the changed restart required keys
+// until the StatefulSet with their checksum is rolled out
+func (reconciler *ActiveMQArtemisReconcilerImpl) updatePropertiesPendingRestart(customResource *brokerv1beta1.ActiveMQArtemis) {
+	pending := map[string]bool{}
+	for _, key := range customResource.Status.PropertiesPendingRestart {
+		pending[key] = true
+	}
+	for _, key := range reconciler.restartProperties {
+		pending[key] = true
+	}
+
+	deployed, _ := reconciler.getFromDeployed(reflect.TypeOf(appsv1.StatefulSet{}), namer.CrToSS(customResource.Name)).(*appsv1.StatefulSet)
+	if deployed != nil && len(reconciler.restartProperties) == 0 &&
+		deployed.Spec.Template.Annotations[restartPropertiesChecksumAnnotation] == reconciler.restartChecksum && isRolledOut(deployed) {
+		pending = nil
+	}
+
+	customResource.Status.PropertiesPendingRestart = nil
+	for key := range pending {
+		customResource.Status.PropertiesPendingRestart = append(customResource.Status.PropertiesPendingRestart, key)
+	}
+	sort.Strings(customResource.Status.PropertiesPendingRestart)
+}
+
+// every pod runs the current revision of the template
+func isRolledOut(statefulSet *appsv1.StatefulSet) bool {
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdatedReplicas == replicasOf(statefulSet) &&
+		statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision
+}
@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"hash/adler32"
 	"regexp"
+	"slices"
 	"sort"
+	"time"
 	"unicode"
 
 	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
@@ -25,18 +27,22 @@ import (
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/serviceports"
 	ss "github.com/arkmq-org/activemq-artemis-operator/pkg/resources/statefulsets"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/certutil"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/cr2jinja2"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/random"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/secretsource"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 	"github.com/arkmq-org/activemq-artemis-operator/version"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -67,6 +73,7 @@ import (
 	"os"
 
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -117,8 +124,28 @@ type ActiveMQArtemisReconcilerImpl struct {
 	customResource     *brokerv1beta1.ActiveMQArtemis
 	scheme             *runtime.Scheme
 	isOnOpenShift      bool
+	// the pod webhook applies the ordinal overrides and placement zones
+	podWebhookEnabled  bool
 	jolokiaEndpoints   []*jolokia_client.JkInfo
 	cachedBrokerStatus map[string]any
+	recorder           record.EventRecorder
+	// built from spec.passwordCodec at the start of each Process
+	maskPasswords passwordMasker
+	// set while planning, ProcessResources records the deltas instead of applying
+	// them and the steps that act on their own are skipped
+	plan *brokerv1beta1.PlanStatus
+	// holds the current span, the spans started by traceStep are its children
+	ctx context.Context
+	// the pod template fields held back until the maintenance window
+	pendingRollout []string
+	// the changed broker properties that take effect on restart, and the checksum
+	// of the requested restart properties in the pod template
+	restartProperties []string
+	restartChecksum   string
+	// the claims that could not grow to the size of their template
+	volumeExpansion []brokerv1beta1.VolumeExpansionStatus
+	// the security config of the requested pod template
+	appliedSecurity appliedSecurityConfig
 }
 
 func NewActiveMQArtemisReconcilerImpl(customResource *brokerv1beta1.ActiveMQArtemis, parent *ActiveMQArtemisReconciler) *ActiveMQArtemisReconcilerImpl {
@@ -128,14 +155,35 @@ func NewActiveMQArtemisReconcilerImpl(customResource *brokerv1beta1.ActiveMQArte
 		scheme:             parent.Scheme,
 		requestedResources: make(map[reflect.Type]map[string]rtclient.Object),
 		isOnOpenShift:      parent.isOnOpenShift,
+		podWebhookEnabled:  parent.podWebhookEnabled,
 		cachedBrokerStatus: make(map[string]any),
+		recorder:           parent.recorder,
+		ctx:                context.TODO(),
 	}
 }
 
+// traceStep starts a span under the current one, the spans started until end
+// is called are its children
+func (reconciler *ActiveMQArtemisReconcilerImpl) traceStep(name string, attributes ...attribute.KeyValue) (end func(error)) {
+	parent := reconciler.ctx
+	ctx, span := tracing.Start(parent, name, attributes...)
+	reconciler.ctx = ctx
+	return func(err error) {
+		tracing.End(span, err)
+		reconciler.ctx = parent
+	}
+}
+
+func (reconciler *ActiveMQArtemisReconcilerImpl) currentContext() context.Context {
+	return reconciler.ctx
+}
+
 type ValueInfo struct {
-	Value    string
-	AutoGen  bool
-	Internal bool //if true put this value to the internal secret
+	Value     string
+	AutoGen   bool
+	Internal  bool //if true put this value to the internal secret
+	Projected bool //if true the value is read from a file of the secret rather than an env var
+	Password  bool //if true the value is masked with spec.passwordCodec
 }
 
 type ActiveMQArtemisIReconciler interface {
@@ -148,13 +196,21 @@ type ActiveMQArtemisIReconciler interface {
 	ProcessResources(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint8
 }
 
-func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme) error {
+func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme) (err error) {
+	end := reconciler.traceStep("Process")
+	defer func() { end(err) }()
 
 	reconciler.log.V(1).Info("Reconciler Processing...", "Operator version", version.Version, "ActiveMQArtemis release", customResource.Spec.Version)
 	reconciler.log.V(2).Info("Reconciler Processing...", "CRD.Name", customResource.Name, "CRD ver", customResource.ObjectMeta.ResourceVersion, "CRD Gen", customResource.ObjectMeta.Generation)
 
 	reconciler.CurrentDeployedResources(customResource, client)
 
+	reconciler.maskPasswords, err = newPasswordMasker(customResource.Spec.PasswordCodec, customResource.Namespace, client)
+	if err != nil {
+		reconciler.log.Error(err, "Error processing password codec")
+		return err
+	}
+
 	// currentStateful Set is a clone of what exists if already deployed
 	// what follows should transform the resources using the crd
 	// if the transformation results in some change, process resources will respect that
@@ -167,7 +223,12 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv
 
 	reconciler.ProcessDeploymentPlan(customResource, namer, client, scheme, desiredStatefulSet)
 
-	reconciler.ProcessCredentials(customResource, namer, client, scheme, desiredStatefulSet)
+	err = reconciler.ProcessCredentials(customResource, namer, client, scheme, desiredStatefulSet)
+
+	if err != nil {
+		reconciler.log.Error(err, "error processing credentials")
+		return err
+	}
 
 	err = reconciler.ProcessAcceptorsAndConnectors(customResource, namer, client, scheme, desiredStatefulSet)
 
@@ -185,7 +246,9 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv
 
 	// mods to env var values sourced from secrets are not detected by process resources
 	// track updates in trigger env var that has a total checksum
-	trackSecretCheckSumInEnvVar(common.ToResourceList(reconciler.requestedResources), desiredStatefulSet.Spec.Template.Spec.Containers)
+	trackSecretCheckSumInEnvVar(common.ToResourceList(reconciler.requestedResources), desiredStatefulSet.Spec.Template.Spec.Containers, rotatedCredentialsKeys(customResource, namer))
+
+	reconciler.ProcessBrokerGroups(customResource, desiredStatefulSet)
 
 	reconciler.trackDesired(desiredStatefulSet)
 
@@ -205,7 +268,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) Process(customResource *brokerv
 	return err
 }
 
-func trackSecretCheckSumInEnvVar(requestedResources []rtclient.Object, container []corev1.Container) {
+func trackSecretCheckSumInEnvVar(requestedResources []rtclient.Object, container []corev1.Container, ignoredKeys map[string][]string) {
 	// the requestedResources need to be sorted because they are extracted
 	// from a map and adler32 depends on the prder of the bytes
 	sort.Slice(requestedResources, func(i, j int) bool {
@@ -221,11 +284,15 @@ func trackSecretCheckSumInEnvVar(requestedResources []rtclient.Object, container
 				// note use of StringData to match MakeSecret for the initial create case
 				if len(secret.StringData) > 0 {
 					for _, k := range sortedKeys(secret.StringData) {
-						digest.Write([]byte(secret.StringData[k]))
+						if !slices.Contains(ignoredKeys[secret.Name], k) {
+							digest.Write([]byte(secret.StringData[k]))
+						}
 					}
 				} else {
 					for _, k := range sortedKeysStringKeyByteValue(secret.Data) {
-						digest.Write(secret.Data[k])
+						if !slices.Contains(ignoredKeys[secret.Name], k) {
+							digest.Write(secret.Data[k])
+						}
 					}
 				}
 			}
@@ -317,10 +384,10 @@ func isClustered(customResource *brokerv1beta1.ActiveMQArtemis) bool {
 	return true
 }
 
-func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) {
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) error {
 
 	if common.IsRestricted(customResource) {
-		return
+		return nil
 	}
 	reconciler.log.V(1).Info("ProcessCredentials")
 
@@ -349,6 +416,14 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResour
 	envVars["AMQ_USER"] = adminUser
 
 	adminPassword.Value = customResource.Spec.AdminPassword
+	if isAdminPasswordProjected(customResource) {
+		value, err := secretsource.NewResolver(client).Resolve(context.TODO(), customResource.Spec.AdminPasswordFrom, customResource.Namespace)
+		if err != nil {
+			return fmt.Errorf("unable to resolve .Spec.AdminPasswordFrom, %v", err)
+		}
+		adminPassword.Value = value
+		adminPassword.Projected = true
+	}
 	if adminPassword.Value == "" {
 		if amqPasswordEnvVar := environments.Retrieve(currentStatefulSet.Spec.Template.Spec.Containers, "AMQ_PASSWORD"); nil != amqPasswordEnvVar {
 			adminPassword.Value = amqPasswordEnvVar.Value
@@ -358,6 +433,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResour
 			adminPassword.AutoGen = true
 		}
 	}
+	adminPassword.Password = true
 	envVars["AMQ_PASSWORD"] = adminPassword
 
 	envVars["AMQ_CLUSTER_USER"] = ValueInfo{
@@ -365,11 +441,23 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessCredentials(customResour
 		AutoGen: true,
 	}
 	envVars["AMQ_CLUSTER_PASSWORD"] = ValueInfo{
-		Value:   random.GenerateRandomString(8),
-		AutoGen: true,
+		Value:    random.GenerateRandomString(8),
+		AutoGen:  true,
+		Password: true,
+	}
+
+	for k, v := range envVars {
+		if !v.Password {
+			continue
+		}
+		if err := reconciler.maskPasswords.maskAll(&v.Value); err != nil {
+			return fmt.Errorf("unable to mask %s, %v", k, err)
+		}
+		envVars[k] = v
 	}
 
 	reconciler.sourceEnvVarFromSecret(customResource, namer, currentStatefulSet, &envVars, secretName, client)
+	return nil
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessDeploymentPlan(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) {
@@ -498,7 +586,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) appendSystemPropertiesForConsol
 		Name:  javaArgsAppendEnvVarName,
 		Value: systemArgs,
 	}
-	environments.CreateOrAppend(currentSS.Spec.Template.Spec.Containers, &consoleProps)
+	environments.CreateOrAppend(operatorContainersOf(currentSS.Spec.Template.Spec.Containers, reconciler.customResource.Spec.DeploymentPlan.Sidecars), &consoleProps)
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) syncMessageMigration(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, client rtclient.Client, scheme *runtime.Scheme) {
@@ -552,6 +640,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) syncMessageMigration(customReso
 		reconciler.log.V(2).Info("we need scaledown for this cr", "crName", customResource.Name, "scheme", scheme)
 		if err = resources.Retrieve(namespacedName, client, scaledown); err != nil {
 			// err means not found so create
+			if reconciler.planning() {
+				reconciler.addPlannedChange(reflect.TypeOf(brokerv1beta1.ActiveMQArtemisScaledown{}), scaledown, brokerv1beta1.PlanActionCreate, nil)
+				return
+			}
 			reconciler.log.V(2).Info("Creating builtin drainer CR ", "scaledown", scaledown)
 			if retrieveError = resources.Create(customResource, client, scheme, scaledown); retrieveError == nil {
 				reconciler.log.V(2).Info("drainer created successfully", "drainer", scaledown)
@@ -561,6 +653,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) syncMessageMigration(customReso
 		}
 	} else {
 		if err = resources.Retrieve(namespacedName, client, scaledown); err == nil {
+			if reconciler.planning() {
+				reconciler.addPlannedChange(reflect.TypeOf(brokerv1beta1.ActiveMQArtemisScaledown{}), scaledown, brokerv1beta1.PlanActionDelete, nil)
+				return
+			}
 			//	ReleaseController(customResource.Name)
 			// err means not found so delete
 			resources.Delete(client, scaledown)
@@ -621,6 +717,15 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) sourceEnvVarFromSecret(customRe
 					secretDefinition.Data[k] = []byte((*envVars)[k].Value)
 				}
 			}
+			// a password generated before spec.passwordCodec was set keeps its value
+			if envVar.Password && !codec.IsMasked(string(secretDefinition.Data[k])) {
+				value := string(secretDefinition.Data[k])
+				if err := reconciler.maskPasswords.maskAll(&value); err != nil {
+					log.Error(err, "unable to mask "+k)
+				} else {
+					secretDefinition.Data[k] = []byte(value)
+				}
+			}
 		}
 		//if operator doesn't own it, don't track
 		if len(secretDefinition.OwnerReferences) > 0 {
@@ -677,6 +782,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) sourceEnvVarFromSecret(customRe
 
 	for _, envVarName := range sortedKeys {
 		envVarInfo := (*envVars)[envVarName]
+		if envVarInfo.Projected {
+			// the secret volume of the pod template provides it
+			continue
+		}
 		secretNameToUse := secretName
 		if envVarInfo.Internal {
 			secretNameToUse = internalSecretName
@@ -696,16 +805,18 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) sourceEnvVarFromSecret(customRe
 			Value:     "",
 			ValueFrom: envVarSource,
 		}
-		if retrievedEnvVar := environments.Retrieve(currentStatefulSet.Spec.Template.Spec.Containers, envVarName); nil == retrievedEnvVar {
+		brokerContainers := operatorContainersOf(currentStatefulSet.Spec.Template.Spec.Containers, customResource.Spec.DeploymentPlan.Sidecars)
+		if retrievedEnvVar := environments.Retrieve(brokerContainers, envVarName); nil == retrievedEnvVar {
 			log.V(2).Info("containers: failed to retrieve " + envVarName + " creating")
-			environments.Create(currentStatefulSet.Spec.Template.Spec.Containers, envVarDefinition)
+			environments.Create(brokerContainers, envVarDefinition)
 		}
 
 		//custom init container
-		if len(currentStatefulSet.Spec.Template.Spec.InitContainers) > 0 {
-			if retrievedEnvVar := environments.Retrieve(currentStatefulSet.Spec.Template.Spec.InitContainers, envVarName); nil == retrievedEnvVar {
+		initContainers := operatorContainersOf(currentStatefulSet.Spec.Template.Spec.InitContainers, customResource.Spec.DeploymentPlan.InitContainers)
+		if len(initContainers) > 0 {
+			if retrievedEnvVar := environments.Retrieve(initContainers, envVarName); nil == retrievedEnvVar {
 				log.V(2).Info("init_containers: failed to retrieve " + envVarName + " creating")
-				environments.Create(currentStatefulSet.Spec.Template.Spec.InitContainers, envVarDefinition)
+				environments.Create(initContainers, envVarDefinition)
 			}
 		}
 	}
@@ -901,9 +1012,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureAcceptorsExposure(cust
 		Name:      customResource.Name,
 		Namespace: customResource.Namespace,
 	}
-	deploymentSize := common.GetDeploymentSize(customResource)
-	for i := int32(0); i < deploymentSize; i++ {
-		ordinalString := strconv.Itoa(int(i))
+	for _, ordinalString := range common.BrokerOrdinals(customResource) {
 		var serviceRoutelabels = make(map[string]string)
 		for k, v := range originalLabels {
 			serviceRoutelabels[k] = v
@@ -1170,9 +1279,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConnectorsExposure(cus
 		Name:      customResource.Name,
 		Namespace: customResource.Namespace,
 	}
-	deploymentSize := common.GetDeploymentSize(customResource)
-	for i := int32(0); i < deploymentSize; i++ {
-		ordinalString := strconv.Itoa(int(i))
+	for _, ordinalString := range common.BrokerOrdinals(customResource) {
 		var serviceRoutelabels = make(map[string]string)
 		for k, v := range originalLabels {
 			serviceRoutelabels[k] = v
@@ -1212,9 +1319,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) configureConsoleExposure(custom
 	}
 	targetPort := int32(8161)
 	portNumber := int32(8162)
-	deploymentSize := common.GetDeploymentSize(customResource)
-	for i := int32(0); i < deploymentSize; i++ {
-		ordinalString := strconv.Itoa(int(i))
+	for _, ordinalString := range common.BrokerOrdinals(customResource) {
 		var serviceRoutelabels = make(map[string]string)
 		for k, v := range originalLabels {
 			serviceRoutelabels[k] = v
@@ -1313,6 +1418,9 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) generateCommonSSLFlags(customRe
 		return nil, "", err
 	}
 
+	if err := reconciler.maskPasswords.maskAll(sslArgs.KeyStorePassword, sslArgs.TrustStorePassword); err != nil {
+		return nil, "", err
+	}
 	sslFlags := sslArgs.ToFlags()
 
 	return sslArgs, sslFlags, nil
@@ -1434,7 +1542,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) CurrentDeployedResources(custom
 	reqLogger := reconciler.log.WithValues("ActiveMQArtemis Name", customResource.Name)
 
 	var err error
-	if customResource.Spec.DeploymentPlan.PersistenceEnabled {
+	if customResource.Spec.DeploymentPlan.PersistenceEnabled && !reconciler.planning() {
 		reconciler.checkExistingPersistentVolumes(customResource, client)
 	}
 
@@ -1460,6 +1568,8 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) CurrentDeployedResources(custom
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme) (err error) {
+	end := reconciler.traceStep("ProcessResources")
+	defer func() { end(err) }()
 
 	reqLogger := reconciler.log.WithValues("ActiveMQArtemis Name", customResource.Name)
 
@@ -1482,6 +1592,15 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource
 
 	var compositeError []error
 	deltas := comparator.Compare(reconciler.deployed, requested)
+	namer := MakeNamers(customResource)
+	for resourceType, delta := range deltas {
+		deltas[resourceType] = reconciler.withoutPaused(customResource, *namer, resourceType, delta)
+	}
+	if reconciler.planning() {
+		reconciler.recordPlan(customResource, client, deltas)
+		return nil
+	}
+	metrics.SetResources(types.NamespacedName{Namespace: customResource.Namespace, Name: customResource.Name}, countOfRequested(reconciler), countOfDeployed(reconciler))
 	for _, resourceType := range getOrderedTypeList() {
 		delta, ok := deltas[resourceType]
 		if !ok {
@@ -1492,11 +1611,30 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource
 
 		for index := range delta.Added {
 			resourceToAdd := delta.Added[index]
+			if isStatefulSetType(resourceType) {
+				// the claims of a restore must exist before the StatefulSet creates them empty
+				if err := reconciler.restoreVolumeClaims(customResource, client, resourceToAdd.(*appsv1.StatefulSet)); err != nil {
+					trackError(&compositeError, err)
+					continue
+				}
+			}
 			trackError(&compositeError, reconciler.createResource(customResource, client, scheme, resourceToAdd, resourceType))
 		}
 		for index := range delta.Updated {
 			resourceToUpdate := delta.Updated[index]
-			trackError(&compositeError, reconciler.updateResource(client, resourceToUpdate, resourceType))
+			if isStatefulSetType(resourceType) {
+				deployed, _ := reconciler.getFromDeployed(resourceType, resourceToUpdate.GetName()).(*appsv1.StatefulSet)
+				held := reconciler.holdForMaintenanceWindow(customResource, deployed, resourceToUpdate.(*appsv1.StatefulSet), time.Now())
+				if reconciler.expandVolumeClaims(customResource, client, deployed, resourceToUpdate.(*appsv1.StatefulSet), held) {
+					continue
+				}
+			}
+			if err := reconciler.updateResource(client, resourceToUpdate, resourceType); err != nil {
+				trackError(&compositeError, err)
+			} else if isStatefulSetType(resourceType) {
+				deployed, _ := reconciler.getFromDeployed(resourceType, resourceToUpdate.GetName()).(*appsv1.StatefulSet)
+				reconciler.recordStatefulSetEvents(customResource, deployed, resourceToUpdate.(*appsv1.StatefulSet))
+			}
 		}
 		for index := range delta.Removed {
 			resourceToRemove := delta.Removed[index]
@@ -1504,6 +1642,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource
 		}
 	}
 
+	updateMaintenanceStatus(customResource, reconciler.pendingRollout, time.Now())
+	reconciler.updatePropertiesPendingRestart(customResource)
+	reconciler.updateVolumeExpansionStatus(customResource, client)
+
 	if len(compositeError) == 0 {
 		return nil
 	} else {
@@ -1513,6 +1655,21 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessResources(customResource
 	}
 }
 
+func (reconciler *ActiveMQArtemisReconcilerImpl) withoutPaused(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, kind reflect.Type, delta compare.ResourceDelta) compare.ResourceDelta {
+	keep := func(objs []rtclient.Object) []rtclient.Object {
+		var kept []rtclient.Object
+		for _, obj := range objs {
+			if isPausedResource(customResource, namer, kind, obj.GetName()) {
+				reconciler.log.V(1).Info("leaving paused resource as deployed", "kind", kind.Name(), "name", obj.GetName())
+				continue
+			}
+			kept = append(kept, obj)
+		}
+		return kept
+	}
+	return compare.ResourceDelta{Added: keep(delta.Added), Updated: keep(delta.Updated), Removed: keep(delta.Removed)}
+}
+
 func countOfRequested(reconciler *ActiveMQArtemisReconcilerImpl) (total int) {
 	for _, v := range reconciler.requestedResources {
 		total += len(v)
@@ -1603,18 +1760,27 @@ func trackError(compositeError *[]error, err error) {
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) createResource(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme, requested rtclient.Object, kind reflect.Type) error {
 	reconciler.log.V(1).Info("Adding delta resources, i.e. creating ", "name ", requested.GetName(), "of kind ", kind)
-	return reconciler.createRequestedResource(customResource, client, scheme, requested, kind)
+	end := reconciler.traceStep("create", tracing.ResourceKindKey.String(kind.Name()), tracing.ResourceNameKey.String(requested.GetName()))
+	err := reconciler.createRequestedResource(customResource, client, scheme, requested, kind)
+	end(err)
+	return err
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) updateResource(client rtclient.Client, requested rtclient.Object, kind reflect.Type) error {
 	reconciler.log.V(1).Info("Updating delta resources, i.e. updating ", "name ", requested.GetName(), "of kind ", kind)
-	return reconciler.updateRequestedResource(client, requested, kind)
+	end := reconciler.traceStep("update", tracing.ResourceKindKey.String(kind.Name()), tracing.ResourceNameKey.String(requested.GetName()))
+	err := reconciler.updateRequestedResource(client, requested, kind)
+	end(err)
+	return err
 
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) deleteResource(client rtclient.Client, requested rtclient.Object, kind reflect.Type) error {
 	reconciler.log.V(1).Info("Deleting delta resources, i.e. removing ", "name ", requested.GetName(), "of kind ", kind)
-	return reconciler.deleteRequestedResource(client, requested, kind)
+	end := reconciler.traceStep("delete", tracing.ResourceKindKey.String(kind.Name()), tracing.ResourceNameKey.String(requested.GetName()))
+	err := reconciler.deleteRequestedResource(client, requested, kind)
+	end(err)
+	return err
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) createRequestedResource(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, scheme *runtime.Scheme, requested rtclient.Object, kind reflect.Type) error {
@@ -1690,9 +1856,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) checkExistingService(cr *broker
 }
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) checkExistingPersistentVolumes(instance *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) {
-	var i int32
-	for i = 0; i < common.GetDeploymentSize(instance); i++ {
-		ordinalString := strconv.Itoa(int(i))
+	for _, ordinalString := range common.BrokerOrdinals(instance) {
 		pvcKey := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-" + namer.CrToSS(instance.Name) + "-" + ordinalString}
 		pvc := &corev1.PersistentVolumeClaim{}
 		err := client.Get(context.TODO(), pvcKey, pvc)
@@ -1949,6 +2113,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 
 	terminationGracePeriodSeconds := int64(60)
 	respectExistingJavaOpts := isExistingDeploymentWithJavaOpts(current) // check before we mutate
+	brokerConfigHandler := reconciler.brokerConfigHandlerFor(customResource, namer)
 
 	// custom labels provided in CR applied only to the pod template spec
 	// note: work with a clone of the default labels to not modify defaults
@@ -1985,7 +2150,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 
 	configMapsToMount := customResource.Spec.DeploymentPlan.ExtraMounts.ConfigMaps
 	secretsToMount := customResource.Spec.DeploymentPlan.ExtraMounts.Secrets
-	brokerPropertiesResourceName, isSecret, brokerPropertiesMapData, serr := reconciler.addResourceForBrokerProperties(customResource, namer)
+	brokerPropertiesResourceName, isSecret, brokerPropertiesMapData, serr := reconciler.addResourceForBrokerProperties(customResource, namer, pts)
 	if serr != nil {
 		return nil, serr
 	}
@@ -2192,7 +2357,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 		additionalSystemPropsForRestricted = append(additionalSystemPropsForRestricted, "-Djava.io.tmpdir=/app/tmp")
 
 		// jvm options
-		additionalSystemPropsForRestricted = append(additionalSystemPropsForRestricted, "-XX:InitialRAMPercentage=70.0 -XX:MaxRAMPercentage=70.0 -XX:AutoBoxCacheMax=20000 -XX:+PrintClassHistogram -XX:+UseG1GC -XX:+UseStringDeduplication -Djava.net.preferIPv4Stack=true")
+		additionalSystemPropsForRestricted = append(additionalSystemPropsForRestricted, restrictedJvmOptionsOf(customResource, namer))
 
 		if customResource.Spec.DeploymentPlan.LivenessProbe == nil {
 			container.LivenessProbe = &corev1.Probe{
@@ -2218,6 +2383,14 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 			container.LivenessProbe = reconciler.configureLivenessProbe(container, customResource.Spec.DeploymentPlan.LivenessProbe)
 		}
 	}
+
+	if len(customResource.Spec.DeploymentPlan.ManagementRBAC) > 0 && isManagementRBACActive(customResource) {
+		brokerPropertiesMapData["aa_management_rbac.properties"] = managementRBACProperties(customResource.Spec.DeploymentPlan.ManagementRBAC)
+	}
+	if credentialsProps, rotated := reconciler.credentialsProperties(customResource, namer); rotated {
+		brokerPropertiesMapData[credentialsPropertiesKey] = credentialsProps
+	}
+	passwordCodecBrokerConfig(customResource.Spec.PasswordCodec, container)
 	extraVolumes, extraVolumeMounts, err := reconciler.createExtraConfigmapsAndSecretsVolumeMounts(configMapsToMount, secretsToMount, brokerPropertiesResourceName, brokerPropertiesMapData, client)
 	if err != nil {
 		return nil, err
@@ -2452,7 +2625,6 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 
 	//provide a way to configuration after launch.sh
 	var brokerHandlerCmds []string = []string{}
-	brokerConfigHandler := GetBrokerConfigHandler(namespacedName)
 	if brokerConfigHandler != nil {
 		reqLogger.V(1).Info("there is a config handler")
 		handlerCmds := brokerConfigHandler.Config(podSpec.InitContainers, initCfgRootDir+"/security", yacfgProfileVersion, yacfgProfileName)
@@ -2471,6 +2643,8 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 		}
 	}
 
+	reconciler.recordSecurityConfig(brokerConfigHandler, brokerHandlerCmds, podSpec.InitContainers)
+
 	var strBuilder strings.Builder
 
 	isFirst := true
@@ -2541,6 +2715,16 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PodTemplateSpecForCR(customReso
 		}
 	}
 
+	applyJvmToTemplate(customResource, namer, pts)
+
+	applyAdminPasswordFileToTemplate(customResource, namer, pts)
+
+	applyContainersToTemplate(customResource, pts)
+
+	applyOrdinalOverridesToTemplate(customResource, pts)
+
+	applyZonePlacementToTemplate(customResource, namer, pts)
+
 	reqLogger.V(2).Info("Final Init spec", "Detail", podSpec.InitContainers)
 
 	return pts, nil
@@ -2583,6 +2767,74 @@ func getLoginConfigEnvVarName(customResource *brokerv1beta1.ActiveMQArtemis) str
 	return jdkJavaOptionsEnvVarName
 }
 
+// edit permission on these operations, in addition to view, makes up each operation group
+var managementOperationGroupOperations = map[brokerv1beta1.ManagementOperationGroup][]string{
+	brokerv1beta1.ManagementOperationGroups.Browse: {"browse"},
+	brokerv1beta1.ManagementOperationGroups.Send:   {"sendMessage"},
+	brokerv1beta1.ManagementOperationGroups.Manage: {"pause", "resume", "removeMessage", "removeMessages", "removeAllMessages", "moveMessage", "moveMessages", "retryMessage", "retryMessages", "expireMessage", "expireMessages", "sendMessagesToDeadLetterAddress", "resetMessageCounter", "resetAllGroups", "resetGroup"},
+	brokerv1beta1.ManagementOperationGroups.Admin:  {"#"},
+}
+
+func managementRBACPrefix(grant *brokerv1beta1.ManagementRBACGrantType) string {
+	if grant.Scope != nil && *grant.Scope != brokerv1beta1.ManagementRBACScopes.Broker {
+		return fmt.Sprintf("mops.%s.%s", *grant.Scope, grant.Match)
+	}
+	return "mops.broker"
+}
+
+func managementRBACProperties(grants []brokerv1beta1.ManagementRBACGrantType) string {
+	// match -> role -> permission, a role on a match gets each permission once
+	securityRoles := map[string]map[string]map[string]bool{}
+	grant := func(match string, role string, permission string) {
+		if _, found := securityRoles[match]; !found {
+			securityRoles[match] = map[string]map[string]bool{}
+		}
+		if _, found := securityRoles[match][role]; !found {
+			securityRoles[match][role] = map[string]bool{}
+		}
+		securityRoles[match][role][permission] = true
+	}
+
+	for i := range grants {
+		prefix := managementRBACPrefix(&grants[i])
+		role := grants[i].Role
+		// any operation on the match, a match ending in # already covers them
+		view := prefix + ".#"
+		if strings.HasSuffix(prefix, "#") {
+			view = prefix
+		}
+		for _, group := range grants[i].Operations {
+			grant(view, role, "view")
+			if prefix == "mops.broker" {
+				// the console needs to find the mbeans it renders
+				grant("mops.mbeanserver.queryMBeans", role, "view")
+			}
+			for _, operation := range managementOperationGroupOperations[group] {
+				grant(prefix+"."+operation, role, "edit")
+			}
+		}
+	}
+
+	rbac := newPropsWithHeader()
+	for _, match := range sortedKeysOf(securityRoles) {
+		for _, role := range sortedKeysOf(securityRoles[match]) {
+			for _, permission := range sortedKeysOf(securityRoles[match][role]) {
+				fmt.Fprintf(rbac, "securityRoles.\"%s\".%s.%s=true\n", match, role, permission)
+			}
+		}
+	}
+	return rbac.String()
+}
+
+func sortedKeysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func newPropsWithHeader() *bytes.Buffer {
 	return newBufferWithHeader("#")
 }
@@ -2794,7 +3046,16 @@ func getPropertiesResourceNsName(artemis *brokerv1beta1.ActiveMQArtemis) types.N
 	}
 }
 
-func (reconciler *ActiveMQArtemisReconcilerImpl) addResourceForBrokerProperties(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers) (string, bool, map[string]string, error) {
+func (reconciler *ActiveMQArtemisReconcilerImpl) addResourceForBrokerProperties(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, template *corev1.PodTemplateSpec) (string, bool, map[string]string, error) {
+
+	reconciler.restartChecksum = template.Annotations[restartPropertiesChecksumAnnotation]
+
+	if common.PauseOf(customResource).BrokerProperties {
+		if name, mutable, data, found := reconciler.deployedBrokerProperties(customResource); found {
+			reconciler.log.V(1).Info("Broker properties paused, requesting deployed resource", "name", name)
+			return name, mutable, data, nil
+		}
+	}
 
 	// fetch and do idempotent transform based on CR
 
@@ -2826,6 +3087,12 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) addResourceForBrokerProperties(
 
 	data := BrokerPropertiesData(customResource.Spec.BrokerProperties)
 
+	var deployedData map[string][]byte
+	if desired != nil {
+		deployedData = mergeSecretStringDataToData(desired).Data
+	}
+	reconciler.trackRestartRequiredProperties(customResource, deployedData, data, template)
+
 	if desired == nil {
 		reconciler.log.V(1).Info("desired brokerprop secret nil, create new one", "name", resourceName.Name)
 		secret := secrets.MakeSecret(resourceName, data, namer.LabelBuilder.Labels())
@@ -3068,7 +3335,8 @@ var brokerPropertyWithOrdinalRegex *regexp.Regexp
 
 func ParseBrokerPropertyWithOrdinal(property string) []string {
 	if brokerPropertyWithOrdinalRegex == nil {
-		brokerPropertyWithOrdinalRegex = regexp.MustCompile("^(" + regexp.QuoteMeta(OrdinalPrefix) + "[0-9]+)" + regexp.QuoteMeta(OrdinalPrefixSep) + "(.*)$")
+		// the ordinal of a broker of a group is <group>-<ordinal>
+		brokerPropertyWithOrdinalRegex = regexp.MustCompile("^(" + regexp.QuoteMeta(OrdinalPrefix) + "(?:[a-z0-9](?:[-a-z0-9]*[a-z0-9])?-)?[0-9]+)" + regexp.QuoteMeta(OrdinalPrefixSep) + "(.*)$")
 	}
 	return brokerPropertyWithOrdinalRegex.FindStringSubmatch(property)
 }
@@ -3090,6 +3358,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) StatefulSetForCR(customResource
 		return nil, err
 	}
 	currentStateFullSet.Spec.Template = *podTemplateSpec
+	annotateSecurityConfig(customResource, currentStateFullSet, reconciler.appliedSecurity)
 
 	currentStateFullSet.Spec.VolumeClaimTemplates = reconciler.PersistentVolumeClaimArrayForCR(customResource, namer, currentStateFullSet.Spec)
 
@@ -3102,10 +3371,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PersistentVolumeClaimArrayForCR
 	pvcArray := make([]corev1.PersistentVolumeClaim, 0)
 
 	if customResource.Spec.DeploymentPlan.PersistenceEnabled {
-		capacity := "2Gi"
-		if customResource.Spec.DeploymentPlan.Storage.Size != "" {
-			capacity = customResource.Spec.DeploymentPlan.Storage.Size
-		}
+		capacity := journalStorageSize(customResource)
 
 		tempateClaim := &brokerv1beta1.VolumeClaimTemplate{
 			ObjectMeta: brokerv1beta1.ObjectMeta{
@@ -3116,7 +3382,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) PersistentVolumeClaimArrayForCR
 				AccessModes: []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
 				Resources: corev1.VolumeResourceRequirements{
 					Requests: corev1.ResourceList{
-						corev1.ResourceName(corev1.ResourceStorage): resource.MustParse(capacity),
+						corev1.ResourceName(corev1.ResourceStorage): capacity,
 					},
 				},
 			},
@@ -3273,6 +3539,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessBrokerStatus(cr *brokerv
 	meta.SetStatusCondition(&cr.Status.Conditions, condition)
 
 	err = reconciler.AssertBrokerPropertiesStatus(cr, client, scheme)
+	metrics.BrokerPropertiesSynced(types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}, err == nil)
 	if err == nil {
 		condition = metav1.Condition{
 			Type:   brokerv1beta1.ConfigAppliedConditionType,
@@ -3300,6 +3567,10 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessBrokerStatus(cr *brokerv
 
 		meta.SetStatusCondition(&cr.Status.Conditions, condition)
 	}
+
+	if reconciler.ProcessDiskUsage(cr, client) {
+		retry = true
+	}
 	return retry
 }
 
@@ -3507,6 +3778,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) GetAndCacheBrokerStatus(jk *jol
 
 	if err != nil {
 		reconciler.log.V(1).Info("error getting broker status with Jolokia", "IP", jk.IP, "Ordinal", jk.Ordinal, "error", err)
+		metrics.JolokiaStatusCheckFailed(types.NamespacedName{Namespace: reconciler.customResource.Namespace, Name: reconciler.customResource.Name}, jk.Ordinal)
 		artemisError := NewArtemisStatusError(err, true)
 		reconciler.cachedBrokerStatus[jk.Ordinal] = artemisError
 		return nil, artemisError
@@ -3517,6 +3789,7 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) GetAndCacheBrokerStatus(jk *jol
 	brokerStatus, err := unmarshallStatus(currentJson)
 	if err != nil {
 		reconciler.log.Error(err, "unable to unmarshall broker status", "json", currentJson)
+		metrics.JolokiaStatusCheckFailed(types.NamespacedName{Namespace: reconciler.customResource.Namespace, Name: reconciler.customResource.Name}, jk.Ordinal)
 		artemisError := NewArtemisStatusError(err, false)
 		reconciler.cachedBrokerStatus[jk.Ordinal] = artemisError
 		return nil, artemisError
@@ -3531,23 +3804,29 @@ func (reconciler *ActiveMQArtemisReconcilerImpl) GetAndCacheBrokerStatus(jk *jol
 
 func (reconciler *ActiveMQArtemisReconcilerImpl) resolveJolokiaEndpoints(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) {
 	if reconciler.jolokiaEndpoints == nil {
-		if common.IsRestricted(cr) {
-			reconciler.jolokiaEndpoints = jolokia_client.GetMinimalJolokiaAgents(cr, client)
-		} else {
-			resource := types.NamespacedName{
-				Name:      cr.Name,
-				Namespace: cr.Namespace,
-			}
-			reconciler.jolokiaEndpoints = jolokia_client.GetBrokers(resource, []ss.StatefulSetInfo{
-				{
-					NamespacedName: types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace},
-					Replicas:       cr.Status.DeploymentPlanSize, // this means we wait till the pod status is good before trying the jolokia endpoint
-					Labels:         nil,
-				}}, client)
-		}
+		reconciler.jolokiaEndpoints = brokerJolokiaEndpoints(cr, client)
+		jolokia_client.Traced(reconciler.currentContext, reconciler.jolokiaEndpoints, tracing.CR(cr.Namespace, cr.Name)...)
 	}
 }
 
+func brokerJolokiaEndpoints(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) []*jolokia_client.JkInfo {
+	if common.IsRestricted(cr) {
+		return jolokia_client.GetMinimalJolokiaAgents(cr, client)
+	}
+	resource := types.NamespacedName{
+		Name:      cr.Name,
+		Namespace: cr.Namespace,
+	}
+	brokers := jolokia_client.GetBrokers(resource, []ss.StatefulSetInfo{
+		{
+			NamespacedName: types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace},
+			Replicas:       cr.Status.DeploymentPlanSize, // this means we wait till the pod status is good before trying the jolokia endpoint
+			Labels:         nil,
+		}}, client)
+	// the pods of the groups share the headless service of the deployment plan
+	return append(brokers, jolokia_client.GetBrokersOfOrdinals(cr.Name, cr.Namespace, common.DeployedGroupOrdinals(cr), client)...)
+}
+
 func (reconciler *ActiveMQArtemisReconcilerImpl) checkProjectionStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, secretProjection *projection, extractStatus func(BrokerStatus *brokerStatus, FileName string) (propertiesStatus, bool)) ArtemisError {
 	reqLogger := ctrl.Log.WithValues("ActiveMQArtemis Name", cr.Name)
 
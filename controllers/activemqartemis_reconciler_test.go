@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/RHsyseng/operator-utils/pkg/olm"
 	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
@@ -14,14 +18,27 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	utilpointer "k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -1529,6 +1546,15 @@ func TestParseBrokerPropertyWithOrdinal(t *testing.T) {
 
 	matches = ParseBrokerPropertyWithOrdinal("broker-a.maxDiskUsage")
 	assert.Equal(t, 0, len(matches))
+
+	// the ordinal of a broker of a group
+	matches = ParseBrokerPropertyWithOrdinal("broker-large-1.maxDiskUsage=97")
+	assert.Equal(t, 3, len(matches))
+	assert.Equal(t, "broker-large-1", matches[1])
+	assert.Equal(t, "maxDiskUsage=97", matches[2])
+
+	matches = ParseBrokerPropertyWithOrdinal("broker-large.maxDiskUsage")
+	assert.Equal(t, 0, len(matches))
 }
 
 func TestBrokerPropertiesData(t *testing.T) {
@@ -1591,3 +1617,1337 @@ func TestBrokerPropertiesDataWithAndWithoutOrdinal(t *testing.T) {
 	assert.True(t, strings.Contains(data[broker999BrokerPropertiesName], "maxDiskUsage=99"))
 	assert.True(t, strings.Contains(data[broker999BrokerPropertiesName], "minDiskFree=7"))
 }
+
+func TestManagementRBACProperties(t *testing.T) {
+
+	queueScope := brokerv1beta1.ManagementRBACScopes.Queue
+	props := managementRBACProperties([]brokerv1beta1.ManagementRBACGrantType{
+		{
+			Role:       "ops",
+			Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.View},
+		},
+		{
+			Role:       "support",
+			Scope:      &queueScope,
+			Match:      "orders",
+			Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.Browse, brokerv1beta1.ManagementOperationGroups.Send},
+		},
+		{
+			Role:       "admin",
+			Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.Admin},
+		},
+		{
+			Role:       "audit",
+			Scope:      &queueScope,
+			Match:      "invoices.#",
+			Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.Browse},
+		},
+		{
+			Role:       "audit",
+			Scope:      &queueScope,
+			Match:      "payments.*",
+			Operations: []brokerv1beta1.ManagementOperationGroup{brokerv1beta1.ManagementOperationGroups.Browse},
+		},
+	})
+
+	assert.True(t, strings.HasPrefix(props, "# generated by crd\n#\n"))
+	assert.Contains(t, props, "securityRoles.\"mops.broker.#\".ops.view=true\n")
+	assert.Contains(t, props, "securityRoles.\"mops.mbeanserver.queryMBeans\".ops.view=true\n")
+	assert.NotContains(t, props, ".ops.edit=true")
+
+	assert.Contains(t, props, "securityRoles.\"mops.queue.orders.#\".support.view=true\n")
+	assert.Contains(t, props, "securityRoles.\"mops.queue.orders.browse\".support.edit=true\n")
+	assert.Contains(t, props, "securityRoles.\"mops.queue.orders.sendMessage\".support.edit=true\n")
+	assert.Equal(t, 1, strings.Count(props, "securityRoles.\"mops.queue.orders.#\".support.view=true"))
+
+	assert.Contains(t, props, "securityRoles.\"mops.broker.#\".admin.edit=true\n")
+	assert.Contains(t, props, "securityRoles.\"mops.broker.#\".admin.view=true\n")
+
+	assert.Contains(t, props, "securityRoles.\"mops.queue.invoices.#\".audit.view=true\n")
+	assert.Contains(t, props, "securityRoles.\"mops.queue.invoices.#.browse\".audit.edit=true\n")
+	assert.NotContains(t, props, "invoices.#.#")
+	assert.Contains(t, props, "securityRoles.\"mops.queue.payments.*.#\".audit.view=true\n")
+}
+
+func TestPlan(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "planned", Namespace: "test", UID: "planned-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			BrokerProperties: []string{"a=1", "b=2"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestPlan"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	// nothing to do once applied
+	plan, err := NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Resources)
+	assert.Empty(t, plan.BrokerProperties)
+	assert.False(t, plan.RollsPods)
+
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	deployedSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+
+	cr.Generation = 2
+	cr.Spec.BrokerProperties = []string{"a=1", "b=3", "c=4"}
+	cr.Spec.Env = []v1.EnvVar{{Name: "PLANNED", Value: "yes"}}
+	plan, err = NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(2), plan.ObservedGeneration)
+	assert.True(t, plan.RollsPods)
+	assert.Equal(t, []brokerv1beta1.PlannedPropertyChange{
+		{File: BrokerPropertiesName, Key: "b", Action: brokerv1beta1.PlanActionUpdate},
+		{File: BrokerPropertiesName, Key: "c", Action: brokerv1beta1.PlanActionCreate},
+	}, plan.BrokerProperties)
+
+	var ssChange, propsChange *brokerv1beta1.PlannedResourceChange
+	for i, change := range plan.Resources {
+		switch {
+		case change.Kind == "StatefulSet" && change.Name == ssKey.Name:
+			ssChange = &plan.Resources[i]
+		case change.Kind == "Secret" && change.Name == cr.Name+"-props":
+			propsChange = &plan.Resources[i]
+		}
+	}
+	if assert.NotNil(t, ssChange) {
+		assert.Equal(t, brokerv1beta1.PlanActionUpdate, ssChange.Action)
+		assert.Contains(t, ssChange.Fields, "spec.template.spec.containers[0].env")
+	}
+	if assert.NotNil(t, propsChange) {
+		assert.Equal(t, []string{`data["broker.properties"]`}, propsChange.Fields)
+	}
+
+	// and nothing was written
+	current := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, current))
+	assert.Equal(t, deployedSS.ResourceVersion, current.ResourceVersion)
+
+	plannedStatefulSet := func(plan *brokerv1beta1.PlanStatus) bool {
+		for _, change := range plan.Resources {
+			if change.Kind == "StatefulSet" {
+				return true
+			}
+		}
+		return false
+	}
+
+	// a pod template held for the maintenance window is not planned
+	// a window that is closed unless the test runs in the first minute of a leap day
+	cr.Spec.MaintenanceWindow = &brokerv1beta1.MaintenanceWindowType{Schedule: "0 0 29 2 *", Duration: metav1.Duration{Duration: time.Minute}}
+	plan, err = NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+	assert.False(t, plan.RollsPods)
+	assert.False(t, plannedStatefulSet(plan))
+	assert.NotEmpty(t, plan.BrokerProperties)
+
+	// nor a paused StatefulSet
+	cr.Spec.MaintenanceWindow = nil
+	cr.Spec.Pause = &brokerv1beta1.PauseType{StatefulSet: true}
+	plan, err = NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+	assert.False(t, plan.RollsPods)
+	assert.False(t, plannedStatefulSet(plan))
+}
+
+func TestPlanWritesNothing(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "planned", Namespace: "test", UID: "planned-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:                &[]int32{2}[0],
+				PersistenceEnabled:  true,
+				PodDisruptionBudget: &policyv1.PodDisruptionBudgetSpec{},
+			},
+		},
+	}
+	// a claim that process would release from the CR
+	claim := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            cr.Name + "-" + namer.CrToSS(cr.Name) + "-0",
+			Namespace:       cr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ActiveMQArtemis", Name: cr.Name, UID: cr.UID}},
+		},
+	}
+
+	writes := []string{}
+	record := func(verb string, obj client.Object) {
+		writes = append(writes, fmt.Sprintf("%s %T %s", verb, obj, obj.GetName()))
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, claim).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			record("create", obj)
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			record("update", obj)
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			record("patch", obj)
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			record("delete", obj)
+			return c.Delete(ctx, obj, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			record("patch "+subResourceName, obj)
+			return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestPlanWritesNothing"), false)
+
+	plan, err := NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+	assert.Empty(t, writes)
+
+	planned := map[string]brokerv1beta1.PlanAction{}
+	for _, change := range plan.Resources {
+		planned[change.Kind+"/"+change.Name] = change.Action
+	}
+	assert.Equal(t, brokerv1beta1.PlanActionCreate, planned["StatefulSet/"+namer.CrToSS(cr.Name)])
+	assert.Equal(t, brokerv1beta1.PlanActionCreate, planned["PodDisruptionBudget/"+cr.Name+"-pdb"])
+	// the drainer of message migration is planned, not started
+	assert.Equal(t, brokerv1beta1.PlanActionCreate, planned["ActiveMQArtemisScaledown/"+cr.Name])
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, &brokerv1beta1.ActiveMQArtemisScaledown{})))
+
+	current := &v1.PersistentVolumeClaim{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}, current))
+	assert.Len(t, current.OwnerReferences, 1)
+}
+
+// the api server moves the string data of a secret to its data, the fake client does not
+func storeStringDataAsData(t *testing.T, fakeClient client.Client) {
+	secretList := &v1.SecretList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), secretList))
+	for i := range secretList.Items {
+		secret := mergeSecretStringDataToData(&secretList.Items[i])
+		secret.StringData = nil
+		assert.NoError(t, fakeClient.Update(context.TODO(), secret))
+	}
+}
+
+func TestPause(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "paused", Namespace: "test", UID: "paused-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			BrokerProperties: []string{"a=1"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestPause"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	deployedSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+	propsKey := getPropertiesResourceNsName(cr)
+	deployedProps := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), propsKey, deployedProps))
+
+	cr.Spec.Pause = &brokerv1beta1.PauseType{StatefulSet: true, Exposure: true, BrokerProperties: true}
+	cr.Spec.Env = []v1.EnvVar{{Name: "PAUSED", Value: "yes"}}
+	cr.Spec.BrokerProperties = []string{"a=2"}
+	cr.Spec.Acceptors = []brokerv1beta1.AcceptorType{{Name: "amqp", Port: 5672, Expose: true}}
+	cr.Spec.IngressDomain = "paused.io"
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	pausedSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, pausedSS))
+	assert.Equal(t, deployedSS.ResourceVersion, pausedSS.ResourceVersion)
+	pausedProps := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), propsKey, pausedProps))
+	assert.Equal(t, deployedProps.Data, pausedProps.Data)
+	services := &v1.ServiceList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), services))
+	for _, service := range services.Items {
+		assert.NotContains(t, service.Name, "amqp")
+	}
+	ingresses := &netv1.IngressList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), ingresses))
+	assert.Empty(t, ingresses.Items)
+
+	common.UpdatePausedStatus(cr)
+	paused := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.ReconcilePausedType)
+	assert.NotNil(t, paused)
+	assert.Equal(t, "Reconcile paused for statefulSet, exposure, brokerProperties", paused.Message)
+
+	// resuming applies what was held back
+	cr.Spec.Pause = nil
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, pausedSS))
+	assert.NotEqual(t, deployedSS.ResourceVersion, pausedSS.ResourceVersion)
+	assert.NoError(t, fakeClient.Get(context.TODO(), propsKey, pausedProps))
+	assert.Contains(t, string(pausedProps.Data[BrokerPropertiesName]), "a=2\n")
+	assert.NoError(t, fakeClient.List(context.TODO(), ingresses))
+	assert.Len(t, ingresses.Items, 1)
+
+	common.UpdatePausedStatus(cr)
+	assert.Nil(t, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.ReconcilePausedType))
+}
+
+type namedConfigHandler string
+
+func (h namedConfigHandler) GetCRName() string {
+	return string(h)
+}
+
+func (h namedConfigHandler) IsApplicableFor(brokerNamespacedName types.NamespacedName) bool {
+	return true
+}
+
+func (h namedConfigHandler) Config(initContainers []v1.Container, outputDirRoot string, yacfgProfileVersion string, yacfgProfileName string) []string {
+	environments.Create(initContainers, &v1.EnvVar{Name: "SECURITY_CFG_YAML", Value: string(h)})
+	return []string{"cp /etc/secret-security-" + string(h) + "-volume/Data " + outputDirRoot, "/opt/amq-broker/script/cfg/config-security.sh"}
+}
+
+func TestPausedAddressAndSecurityKeepsConfigHandler(t *testing.T) {
+	securityKey := types.NamespacedName{Namespace: "test", Name: "security"}
+	defer delete(namespaceToConfigHandler, securityKey)
+	cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: "paused", Namespace: "test"}}
+
+	statefulSet := func(current *appsv1.StatefulSet) *appsv1.StatefulSet {
+		outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("test"), false)
+		reconciler := NewActiveMQArtemisReconcilerImpl(cr, outer)
+		reconciler.deployed = map[reflect.Type][]client.Object{}
+		if current != nil {
+			reconciler.addToDeployed(reflect.TypeOf(appsv1.StatefulSet{}), current)
+			current = current.DeepCopy()
+		}
+		requested, err := reconciler.StatefulSetForCR(cr, *MakeNamers(cr), current, nil)
+		assert.NoError(t, err)
+		return requested
+	}
+	securityOf := func(ss *appsv1.StatefulSet) string {
+		return environments.Retrieve(ss.Spec.Template.Spec.InitContainers, "SECURITY_CFG_YAML").Value
+	}
+
+	// pausing before the first deployment holds the handler of that time
+	namespaceToConfigHandler[securityKey] = namedConfigHandler("before")
+	cr.Spec.Pause = &brokerv1beta1.PauseType{AddressAndSecurity: true}
+	deployed := statefulSet(nil)
+	assert.Equal(t, "before", securityOf(deployed))
+	assert.Contains(t, deployed.Annotations[securityConfigAnnotation], `"crName":"before"`)
+
+	// the held handler is read back from the annotation of the deployed StatefulSet, not from memory
+	namespaceToConfigHandler[securityKey] = namedConfigHandler("after")
+	held := statefulSet(deployed)
+	assert.Equal(t, "before", securityOf(held))
+	assert.Equal(t, deployed.Spec.Template.Spec.InitContainers[0].Args, held.Spec.Template.Spec.InitContainers[0].Args)
+	assert.Equal(t, deployed.Spec.Template.Spec.Volumes, held.Spec.Template.Spec.Volumes)
+	assert.Equal(t, deployed.Annotations, held.Annotations)
+
+	// not from the args of the init container
+	tampered := held.DeepCopy()
+	tampered.Spec.Template.Spec.InitContainers[0].Args = []string{"-c", "true"}
+	assert.Equal(t, "before", securityOf(statefulSet(tampered)))
+
+	cr.Spec.Pause = nil
+	resumed := statefulSet(held)
+	assert.Equal(t, "after", securityOf(resumed))
+	assert.Contains(t, resumed.Spec.Template.Spec.InitContainers[0].Args[1], "/etc/secret-security-after-volume/Data")
+	assert.Contains(t, resumed.Annotations[securityConfigAnnotation], `"crName":"after"`)
+
+	// no security CR at the time of the pause, none is picked up later
+	delete(namespaceToConfigHandler, securityKey)
+	cr.Spec.Pause = &brokerv1beta1.PauseType{AddressAndSecurity: true}
+	deployed = statefulSet(nil)
+	namespaceToConfigHandler[securityKey] = namedConfigHandler("after")
+	assert.Nil(t, environments.Retrieve(statefulSet(deployed).Spec.Template.Spec.InitContainers, "SECURITY_CFG_YAML"))
+
+	// a StatefulSet deployed before the config was recorded takes the current handler
+	delete(deployed.Annotations, securityConfigAnnotation)
+	assert.Equal(t, "after", securityOf(statefulSet(deployed)))
+
+	// nothing to record without a security CR or a pause
+	delete(namespaceToConfigHandler, securityKey)
+	cr.Spec.Pause = nil
+	assert.NotContains(t, statefulSet(deployed).Annotations, securityConfigAnnotation)
+}
+
+func TestMaintenanceWindowHoldsPodTemplate(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "maintained", Namespace: "test", UID: "maintained-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			BrokerProperties: []string{"a=1"},
+			// a window that is closed unless the test runs in the first minute of a leap day
+			MaintenanceWindow: &brokerv1beta1.MaintenanceWindowType{Schedule: "0 0 29 2 *", Duration: metav1.Duration{Duration: time.Minute}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestMaintenanceWindow"), false)
+
+	// the first deployment does not wait
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.Empty(t, cr.Status.Maintenance.PendingChanges)
+
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	deployedSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+
+	cr.Spec.Env = []v1.EnvVar{{Name: "MAINTAINED", Value: "yes"}}
+	cr.Spec.DeploymentPlan.Size = utilpointer.Int32(2)
+	cr.Spec.BrokerProperties = []string{"a=2"}
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	heldSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, heldSS))
+	assert.Equal(t, deployedSS.Spec.Template, heldSS.Spec.Template)
+	assert.Equal(t, int32(2), *heldSS.Spec.Replicas)
+	props := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), getPropertiesResourceNsName(cr), props))
+	assert.Contains(t, string(props.Data[BrokerPropertiesName]), "a=2\n")
+
+	assert.NotEmpty(t, cr.Status.Maintenance.PendingChanges)
+	for _, change := range cr.Status.Maintenance.PendingChanges {
+		assert.True(t, strings.HasPrefix(change, "spec.template."), change)
+	}
+	assert.NotNil(t, cr.Status.Maintenance.PendingSince)
+	assert.Equal(t, time.February, cr.Status.Maintenance.NextWindow.Month())
+
+	cr.Annotations = map[string]string{common.RolloutNowAnnotation: "true"}
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, heldSS))
+	assert.NotEqual(t, deployedSS.Spec.Template, heldSS.Spec.Template)
+	assert.Empty(t, cr.Status.Maintenance.PendingChanges)
+	assert.Nil(t, cr.Status.Maintenance.PendingSince)
+
+	// a new image goes out with the tuning changes, without waiting for the window
+	cr.Annotations = nil
+	cr.Spec.Env = []v1.EnvVar{{Name: "MAINTAINED", Value: "still"}}
+	cr.Spec.DeploymentPlan.Image = "quay.io/arkmq-org/activemq-artemis-broker-kubernetes:patched"
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	rolledSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, rolledSS))
+	assert.Equal(t, cr.Spec.DeploymentPlan.Image, rolledSS.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "still", environments.Retrieve(rolledSS.Spec.Template.Spec.Containers, "MAINTAINED").Value)
+	assert.Empty(t, cr.Status.Maintenance.PendingChanges)
+}
+
+func TestUrgentTemplateChanges(t *testing.T) {
+	template := func(image string, env ...v1.EnvVar) *v1.PodTemplateSpec {
+		return &v1.PodTemplateSpec{Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init", Image: "init", Env: env}},
+			Containers:     []v1.Container{{Name: "broker", Image: image, Env: env}},
+		}}
+	}
+	deployed := template("broker", v1.EnvVar{Name: "TRIGGERED_ROLL_COUNT", Value: "1"}, v1.EnvVar{Name: "TUNING", Value: "1"})
+
+	assert.Empty(t, urgentTemplateChanges(deployed, template("broker", v1.EnvVar{Name: "TRIGGERED_ROLL_COUNT", Value: "1"}, v1.EnvVar{Name: "TUNING", Value: "2"})))
+	assert.Equal(t, []string{"broker.image"}, urgentTemplateChanges(deployed, template("patched", v1.EnvVar{Name: "TRIGGERED_ROLL_COUNT", Value: "1"})))
+	assert.Equal(t, []string{"init.env.TRIGGERED_ROLL_COUNT", "broker.env.TRIGGERED_ROLL_COUNT"}, urgentTemplateChanges(deployed, template("broker", v1.EnvVar{Name: "TRIGGERED_ROLL_COUNT", Value: "2"})))
+	assert.Equal(t, []string{"init.env.SECURITY_CFG_YAML", "broker.env.SECURITY_CFG_YAML"}, urgentTemplateChanges(deployed, template("broker", v1.EnvVar{Name: "TRIGGERED_ROLL_COUNT", Value: "1"}, v1.EnvVar{Name: "SECURITY_CFG_YAML", Value: "secured"})))
+}
+
+func TestRestartRequiredProperties(t *testing.T) {
+	data := BrokerPropertiesData([]string{
+		"addressSettings.#.maxDeliveryAttempts=3",
+		"securityRoles.\"a.b\".admin.send=true",
+		"globalMaxSize=1G",
+		"broker-1.criticalAnalyzer=false",
+	})
+	assert.Equal(t, map[string]string{
+		"globalMaxSize":             "1G",
+		"broker-1.criticalAnalyzer": "false",
+	}, restartRequiredProperties(data))
+}
+
+func TestRestartRequiredPropertiesRollBrokers(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "restarted", Namespace: "test", UID: "restarted-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			BrokerProperties: []string{"addressSettings.#.maxDeliveryAttempts=3", "globalMaxSize=1G"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestRestartRequiredProperties"), false)
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	deployedSS := &appsv1.StatefulSet{}
+	process := func() {
+		assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+		storeStringDataAsData(t, fakeClient)
+		assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+	}
+
+	process()
+	assert.NotContains(t, deployedSS.Spec.Template.Annotations, restartPropertiesChecksumAnnotation)
+	template := deployedSS.Spec.Template.DeepCopy()
+
+	// reloaded by the brokers
+	cr.Spec.BrokerProperties = []string{"addressSettings.#.maxDeliveryAttempts=5", "globalMaxSize=1G"}
+	process()
+	assert.Equal(t, *template, deployedSS.Spec.Template)
+	assert.Empty(t, cr.Status.PropertiesPendingRestart)
+
+	cr.Spec.BrokerProperties = []string{"addressSettings.#.maxDeliveryAttempts=5", "globalMaxSize=2G"}
+	process()
+	assert.Contains(t, deployedSS.Spec.Template.Annotations, restartPropertiesChecksumAnnotation)
+	assert.Equal(t, []string{"globalMaxSize"}, cr.Status.PropertiesPendingRestart)
+
+	// until the brokers run the new template
+	process()
+	assert.Equal(t, []string{"globalMaxSize"}, cr.Status.PropertiesPendingRestart)
+
+	deployedSS.Status = appsv1.StatefulSetStatus{UpdatedReplicas: 1, CurrentRevision: "2", UpdateRevision: "2"}
+	assert.NoError(t, fakeClient.Status().Update(context.TODO(), deployedSS))
+	process()
+	assert.Empty(t, cr.Status.PropertiesPendingRestart)
+}
+
+func TestVolumeExpansion(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "expanded", Namespace: "test", UID: "expanded-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				PersistenceEnabled: true,
+				Storage:            brokerv1beta1.StorageType{Size: "1Gi", StorageClassName: "fixed"},
+			},
+		},
+	}
+	claim := func(className string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "expanded-expanded-ss-0", Namespace: "test"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: &className,
+				Resources:        v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+			Status: v1.PersistentVolumeClaimStatus{
+				Phase:    v1.ClaimBound,
+				Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		}
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: utilpointer.Bool(true)},
+		claim("fixed"),
+	).WithStatusSubresource(&v1.PersistentVolumeClaim{}).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestVolumeExpansion"), false)
+
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	claimKey := types.NamespacedName{Name: "expanded-expanded-ss-0", Namespace: cr.Namespace}
+	templateSize := func() string {
+		deployedSS := &appsv1.StatefulSet{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+		return deployedSS.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String()
+	}
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.Equal(t, "1Gi", templateSize())
+	assert.Empty(t, cr.Status.VolumeExpansion)
+
+	// the class of the claim does not allow expansion, the template stays
+	cr.Spec.DeploymentPlan.Storage.Size = "2Gi"
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.Equal(t, "1Gi", templateSize())
+	assert.Equal(t, []brokerv1beta1.VolumeExpansionStatus{{
+		Name: claimKey.Name, RequestedSize: "2Gi", Capacity: "1Gi", State: VolumeExpansionUnsupported,
+		Message: "storage class fixed does not allow volume expansion",
+	}}, cr.Status.VolumeExpansion)
+	assert.False(t, isVolumeExpansionInProgress(cr))
+
+	deployedClaim := &v1.PersistentVolumeClaim{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), claimKey, deployedClaim))
+	deployedClaim.Spec.StorageClassName = utilpointer.String("expandable")
+	assert.NoError(t, fakeClient.Update(context.TODO(), deployedClaim))
+
+	// a plan reports the expansion without doing it
+	plan, err := NewActiveMQArtemisReconcilerImpl(cr, outer).Plan(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.NoError(t, err)
+	assert.Contains(t, plan.Resources, brokerv1beta1.PlannedResourceChange{Kind: "PersistentVolumeClaim", Name: claimKey.Name, Action: brokerv1beta1.PlanActionUpdate, Fields: []string{"spec.resources.requests.storage"}})
+	assert.Contains(t, plan.Resources, brokerv1beta1.PlannedResourceChange{Kind: "StatefulSet", Name: ssKey.Name, Action: brokerv1beta1.PlanActionDelete})
+	assert.Contains(t, plan.Resources, brokerv1beta1.PlannedResourceChange{Kind: "StatefulSet", Name: ssKey.Name, Action: brokerv1beta1.PlanActionCreate})
+	assert.Equal(t, "1Gi", templateSize())
+
+	// the claim grows and the statefulset is deleted to take the new template
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.NoError(t, fakeClient.Get(context.TODO(), claimKey, deployedClaim))
+	assert.Equal(t, "2Gi", deployedClaim.Spec.Resources.Requests.Storage().String())
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), ssKey, &appsv1.StatefulSet{})))
+	assert.Equal(t, []brokerv1beta1.VolumeExpansionStatus{{
+		Name: claimKey.Name, RequestedSize: "2Gi", Capacity: "1Gi", State: VolumeExpansionPending,
+	}}, cr.Status.VolumeExpansion)
+	assert.True(t, isVolumeExpansionInProgress(cr))
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.Equal(t, "2Gi", templateSize())
+
+	deployedClaim.Status.Capacity[v1.ResourceStorage] = resource.MustParse("2Gi")
+	assert.NoError(t, fakeClient.Status().Update(context.TODO(), deployedClaim))
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.Empty(t, cr.Status.VolumeExpansion)
+
+	// a volume can not shrink
+	cr.Spec.DeploymentPlan.Storage.Size = "1Gi"
+	condition, _ := NewActiveMQArtemisReconcilerImpl(cr, outer).validateVolumeClaimSizes(cr, fakeClient, *MakeNamers(cr))
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedStorageShrink, condition.Reason)
+
+	// nor the volume of a broker group
+	cr.Spec.DeploymentPlan.Storage.Size = "2Gi"
+	cr.Spec.DeploymentPlan.BrokerGroups = []brokerv1beta1.BrokerGroupType{{Name: "large", Size: utilpointer.Int32(1), Storage: &brokerv1beta1.BrokerGroupStorageType{Size: "5Gi"}}}
+	assert.NoError(t, fakeClient.Create(context.TODO(), &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: namer.CrToGroupSS(cr.Name, "large"), Namespace: cr.Namespace},
+		Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+			ObjectMeta: metav1.ObjectMeta{Name: cr.Name},
+			Spec:       v1.PersistentVolumeClaimSpec{Resources: v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("5Gi")}}},
+		}}},
+	}))
+	condition, _ = NewActiveMQArtemisReconcilerImpl(cr, outer).validateVolumeClaimSizes(cr, fakeClient, *MakeNamers(cr))
+	assert.Nil(t, condition)
+	cr.Spec.DeploymentPlan.BrokerGroups[0].Storage.Size = "3Gi"
+	condition, _ = NewActiveMQArtemisReconcilerImpl(cr, outer).validateVolumeClaimSizes(cr, fakeClient, *MakeNamers(cr))
+	assert.NotNil(t, condition)
+	assert.Equal(t, brokerv1beta1.ValidConditionFailedStorageShrink, condition.Reason)
+	assert.Contains(t, condition.Message, namer.CrToGroupSS(cr.Name, "large"))
+}
+
+func TestVolumeExpansionWaitsForHeldPodTemplate(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "held", Namespace: "test", UID: "held-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				PersistenceEnabled: true,
+				Storage:            brokerv1beta1.StorageType{Size: "1Gi", StorageClassName: "expandable"},
+			},
+			// a window that is closed unless the test runs in the first minute of a leap day
+			MaintenanceWindow: &brokerv1beta1.MaintenanceWindowType{Schedule: "0 0 29 2 *", Duration: metav1.Duration{Duration: time.Minute}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: utilpointer.Bool(true)},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "held-held-ss-0", Namespace: "test"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: utilpointer.String("expandable"),
+				Resources:        v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+		},
+	).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestVolumeExpansionWaitsForHeldPodTemplate"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+	deployedSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, deployedSS))
+
+	// the claim grows, the statefulset is not recreated with the held template
+	cr.Spec.Env = []v1.EnvVar{{Name: "HELD", Value: "yes"}}
+	cr.Spec.DeploymentPlan.Storage.Size = "2Gi"
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	claim := &v1.PersistentVolumeClaim{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "held-held-ss-0", Namespace: cr.Namespace}, claim))
+	assert.Equal(t, "2Gi", claim.Spec.Resources.Requests.Storage().String())
+	heldSS := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, heldSS))
+	assert.Equal(t, deployedSS.Spec.Template, heldSS.Spec.Template)
+	assert.Equal(t, "1Gi", heldSS.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String())
+	assert.Contains(t, cr.Status.Maintenance.PendingChanges, "spec.volumeClaimTemplates")
+
+	// in the window the statefulset is recreated with both
+	cr.Annotations = map[string]string{common.RolloutNowAnnotation: "true"}
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), ssKey, &appsv1.StatefulSet{})))
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, heldSS))
+	assert.NotEqual(t, deployedSS.Spec.Template, heldSS.Spec.Template)
+	assert.Equal(t, "2Gi", heldSS.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests.Storage().String())
+}
+
+func TestRestoreFromBackup(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	replicas := int32(3)
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "restored", Namespace: "test", UID: "restored-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:               &replicas,
+				PersistenceEnabled: true,
+				Storage: brokerv1beta1.StorageType{
+					Size:        "1Gi",
+					RestoreFrom: &brokerv1beta1.StorageRestoreType{Backup: "nightly"},
+				},
+			},
+		},
+	}
+	snapshot := func(id string, ordinal int32, ready bool) brokerv1beta1.BackupSnapshotStatus {
+		return brokerv1beta1.BackupSnapshotStatus{
+			Name: "nightly-" + strings.ToLower(id) + "-" + strconv.Itoa(int(ordinal)), BackupID: id, Ordinal: ordinal,
+			ClaimName: "ex-ex-ss-" + strconv.Itoa(int(ordinal)), ReadyToUse: ready, RestoreSize: "2Gi",
+		}
+	}
+	backup := &brokerv1beta1.ActiveMQArtemisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "test"},
+		Spec:       brokerv1beta1.ActiveMQArtemisBackupSpec{BrokerName: "ex"},
+		Status: brokerv1beta1.ActiveMQArtemisBackupStatus{Snapshots: []brokerv1beta1.BackupSnapshotStatus{
+			snapshot("20261019T030000Z", 0, false),
+		}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, backup).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestRestoreFromBackup"), false)
+	ssKey := types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}
+
+	// the brokers wait for a backup that can be restored
+	valid, retry := NewActiveMQArtemisReconcilerImpl(cr, outer).validate(cr, fakeClient, *MakeNamers(cr))
+	assert.False(t, valid)
+	assert.True(t, retry)
+	assert.Contains(t, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.ValidConditionType).Message, "no backup with every snapshot ready")
+
+	// the latest restorable backup is restored, the ordinals it lacks start empty
+	backup.Status.Snapshots = append(backup.Status.Snapshots,
+		snapshot("20261019T020000Z", 0, true), snapshot("20261019T020000Z", 1, true))
+	assert.NoError(t, fakeClient.Update(context.TODO(), backup))
+	valid, _ = NewActiveMQArtemisReconcilerImpl(cr, outer).validate(cr, fakeClient, *MakeNamers(cr))
+	assert.True(t, valid)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+	assert.NoError(t, fakeClient.Get(context.TODO(), ssKey, &appsv1.StatefulSet{}))
+
+	claim := &v1.PersistentVolumeClaim{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "restored-restored-ss-1", Namespace: cr.Namespace}, claim))
+	assert.Equal(t, "VolumeSnapshot", claim.Spec.DataSource.Kind)
+	assert.Equal(t, "snapshot.storage.k8s.io", *claim.Spec.DataSource.APIGroup)
+	assert.Equal(t, "nightly-20261019t020000z-1", claim.Spec.DataSource.Name)
+	assert.Equal(t, "2Gi", claim.Spec.Resources.Requests.Storage().String())
+	assert.Len(t, claim.OwnerReferences, 0)
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), types.NamespacedName{Name: "restored-restored-ss-2", Namespace: cr.Namespace}, claim)))
+
+	// once deployed the restore no longer applies
+	assert.NoError(t, fakeClient.Delete(context.TODO(), backup))
+	valid, _ = NewActiveMQArtemisReconcilerImpl(cr, outer).validate(cr, fakeClient, *MakeNamers(cr))
+	assert.True(t, valid)
+}
+
+func TestOrdinalOverrides(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	bigger := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}}
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "test", UID: "pinned-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Env: []v1.EnvVar{{Name: "JAVA_ARGS_APPEND", Value: "-Xmx1g"}},
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size: utilpointer.Int32(2),
+				OrdinalOverrides: []brokerv1beta1.OrdinalOverrideType{{
+					Ordinal:      0,
+					Resources:    &bigger,
+					Env:          []v1.EnvVar{{Name: "JAVA_ARGS_APPEND", Value: "-Xmx3g"}},
+					Labels:       map[string]string{"tier": "large"},
+					NodeSelector: map[string]string{"topology.kubernetes.io/zone": "a"},
+					Tolerations:  []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "brokers", Effect: v1.TaintEffectNoSchedule}},
+				}},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestOrdinalOverrides"), false)
+
+	// the overrides need the pod webhook
+	valid, _ := NewActiveMQArtemisReconcilerImpl(cr, outer).validate(cr, fakeClient, *MakeNamers(cr))
+	assert.False(t, valid)
+	outer.podWebhookEnabled = true
+	valid, _ = NewActiveMQArtemisReconcilerImpl(cr, outer).validate(cr, fakeClient, *MakeNamers(cr))
+	assert.True(t, valid)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	// the template gates no pod, the webhook applies the overrides
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	template := ss.Spec.Template
+	assert.Empty(t, template.Spec.SchedulingGates)
+	assert.NotEmpty(t, template.Annotations[ordinalOverridesChecksumAnnotation])
+	assert.Equal(t, "-Xmx1g", environments.Retrieve(template.Spec.Containers, "JAVA_ARGS_APPEND").Value)
+
+	// the StatefulSet creates the pods from the template, through the webhook
+	for ordinal := 0; ordinal < 2; ordinal++ {
+		pod := &v1.Pod{ObjectMeta: *template.ObjectMeta.DeepCopy(), Spec: *template.Spec.DeepCopy()}
+		pod.Name, pod.Namespace = namer.CrToSSOrdinal(cr.Name, ordinal), cr.Namespace
+		assert.Equal(t, ordinal == 0, applyOrdinalOverrideToPod(cr, nil, int32(ordinal), pod))
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+		assert.NoError(t, fakeClient.Create(context.TODO(), pod))
+	}
+
+	assert.False(t, NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessOrdinalOverrides(cr, *MakeNamers(cr), fakeClient))
+
+	overridden := &v1.Pod{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSSOrdinal(cr.Name, 0), Namespace: cr.Namespace}, overridden))
+	assert.Equal(t, "true", overridden.Annotations[ordinalOverridesAppliedAnnotation])
+	assert.Equal(t, "large", overridden.Labels["tier"])
+	assert.Equal(t, "-Xmx3g", environments.Retrieve(overridden.Spec.Containers, "JAVA_ARGS_APPEND").Value)
+	assert.Equal(t, "a", overridden.Spec.NodeSelector["topology.kubernetes.io/zone"])
+	assert.Len(t, overridden.Spec.Tolerations, len(template.Spec.Tolerations)+1)
+	assert.Equal(t, "4Gi", overridden.Spec.Containers[0].Resources.Limits.Memory().String())
+
+	// an ordinal without an override keeps the template
+	other := &v1.Pod{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSSOrdinal(cr.Name, 1), Namespace: cr.Namespace}, other))
+	assert.Empty(t, other.Annotations[ordinalOverridesAppliedAnnotation])
+	assert.Equal(t, "-Xmx1g", environments.Retrieve(other.Spec.Containers, "JAVA_ARGS_APPEND").Value)
+	assert.Empty(t, other.Labels["tier"])
+
+	// a change of an override rolls the brokers
+	cr.Spec.DeploymentPlan.OrdinalOverrides[0].Labels["tier"] = "larger"
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	rolled := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, rolled))
+	assert.NotEqual(t, template.Annotations[ordinalOverridesChecksumAnnotation], rolled.Spec.Template.Annotations[ordinalOverridesChecksumAnnotation])
+	applied := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.OrdinalOverridesAppliedConditionType)
+	assert.Equal(t, metav1.ConditionTrue, applied.Status)
+}
+
+func TestOrdinalOverridesRecreatePodsCreatedWithoutWebhook(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:             utilpointer.Int32(3),
+				OrdinalOverrides: []brokerv1beta1.OrdinalOverrideType{{Ordinal: 0, Labels: map[string]string{"tier": "large"}}},
+			},
+		},
+	}
+	ready := []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	newPod := func(ordinal int, conditions []v1.PodCondition) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: namer.CrToSSOrdinal(cr.Name, ordinal), Namespace: cr.Namespace},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "broker"}}},
+			Status:     v1.PodStatus{Conditions: conditions},
+		}
+	}
+	// ordinal 0 was admitted while the webhook was down, ordinal 1 is starting
+	// and ordinal 2 is still gated by a previous version of the operator
+	gated := newPod(2, nil)
+	gated.Spec.SchedulingGates = []v1.PodSchedulingGate{{Name: ordinalOverridesSchedulingGate}}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(newPod(0, ready), newPod(1, nil), gated).Build()
+	recorder := record.NewFakeRecorder(10)
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestOrdinalOverridesRecreatePodsCreatedWithoutWebhook"), false)
+	outer.recorder = recorder
+
+	assert.True(t, NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessOrdinalOverrides(cr, *MakeNamers(cr), fakeClient))
+	applied := meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.OrdinalOverridesAppliedConditionType)
+	assert.Equal(t, metav1.ConditionFalse, applied.Status)
+	assert.Equal(t, brokerv1beta1.OrdinalOverridesAppliedConditionNotAppliedReason, applied.Reason)
+	assert.Contains(t, applied.Message, "pinned-ss-0")
+
+	// the gated pod is not running, the running broker waits for the others
+	pod := &v1.Pod{}
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), types.NamespacedName{Name: gated.Name, Namespace: cr.Namespace}, pod)))
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pinned-ss-0", Namespace: cr.Namespace}, pod))
+	assert.Len(t, recorder.Events, 0)
+
+	assert.NoError(t, fakeClient.Create(context.TODO(), newPod(2, ready)))
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pinned-ss-1", Namespace: cr.Namespace}, pod))
+	pod.Status.Conditions = ready
+	assert.NoError(t, fakeClient.Status().Update(context.TODO(), pod))
+
+	assert.True(t, NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessOrdinalOverrides(cr, *MakeNamers(cr), fakeClient))
+	assert.True(t, apierrors.IsNotFound(fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pinned-ss-0", Namespace: cr.Namespace}, pod)))
+	assert.Contains(t, <-recorder.Events, EventReasonOverridesNotApplied)
+
+	// the StatefulSet creates it again through the webhook
+	recreated := newPod(0, ready)
+	assert.True(t, applyOrdinalOverrideToPod(cr, nil, 0, recreated))
+	assert.NoError(t, fakeClient.Create(context.TODO(), recreated))
+	assert.False(t, NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessOrdinalOverrides(cr, *MakeNamers(cr), fakeClient))
+	assert.Equal(t, metav1.ConditionTrue, meta.FindStatusCondition(cr.Status.Conditions, brokerv1beta1.OrdinalOverridesAppliedConditionType).Status)
+}
+
+func TestBrokerGroups(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	large := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("8Gi")}}
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "pools", Namespace: "test", UID: "pools-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:               utilpointer.Int32(2),
+				PersistenceEnabled: true,
+				OrdinalOverrides:   []brokerv1beta1.OrdinalOverrideType{{Ordinal: 0, Labels: map[string]string{"tier": "first"}}},
+				Clustered:          utilpointer.Bool(true),
+				BrokerGroups: []brokerv1beta1.BrokerGroupType{{
+					Name:         "large",
+					Size:         utilpointer.Int32(3),
+					Resources:    &large,
+					Storage:      &brokerv1beta1.BrokerGroupStorageType{Size: "50Gi", StorageClassName: "fast"},
+					NodeSelector: map[string]string{"node.kubernetes.io/instance-type": "m5.2xlarge"},
+				}},
+			},
+			Acceptors:        []brokerv1beta1.AcceptorType{{Name: "amqp", Port: 5672}},
+			BrokerProperties: []string{"broker-0.name=first", "broker-large-0.name=first-large"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestBrokerGroups"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	assert.Equal(t, int32(2), *ss.Spec.Replicas)
+	assert.NotContains(t, ss.Spec.Template.Labels, selectors.LabelBrokerGroupKey)
+
+	group := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pools-ss-large", Namespace: cr.Namespace}, group))
+	assert.Equal(t, "pools-uid", string(group.OwnerReferences[0].UID))
+	assert.Equal(t, int32(3), *group.Spec.Replicas)
+	assert.Equal(t, ss.Spec.ServiceName, group.Spec.ServiceName)
+	assert.Equal(t, "large", group.Spec.Selector.MatchLabels[selectors.LabelBrokerGroupKey])
+	assert.Equal(t, "large", group.Spec.Template.Labels[selectors.LabelBrokerGroupKey])
+	for key, value := range ss.Spec.Selector.MatchLabels {
+		assert.Equal(t, value, group.Spec.Template.Labels[key])
+	}
+	assert.Empty(t, group.Spec.Template.Spec.SchedulingGates)
+	assert.Equal(t, "8Gi", group.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String())
+	assert.Equal(t, "m5.2xlarge", group.Spec.Template.Spec.NodeSelector["node.kubernetes.io/instance-type"])
+	journal := findClaimTemplate(group.Spec.VolumeClaimTemplates, cr.Name)
+	assert.Equal(t, "50Gi", journal.Spec.Resources.Requests.Storage().String())
+	assert.Equal(t, "fast", *journal.Spec.StorageClassName)
+
+	// the brokers of the group have their own ordinals, for the properties and the probes
+	assert.Contains(t, strings.Join(ss.Spec.Template.Spec.Containers[0].Command, " "), "STATEFUL_SET_ORDINAL=${HOSTNAME##*-}")
+	assert.Contains(t, strings.Join(group.Spec.Template.Spec.Containers[0].Command, " "), "STATEFUL_SET_ORDINAL=large-${HOSTNAME##*-}")
+	assert.NotContains(t, strings.Join(group.Spec.Template.Spec.Containers[0].Command, " "), "=${HOSTNAME##*-}")
+
+	properties := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-props", Namespace: cr.Namespace}, properties))
+	assert.Contains(t, string(properties.Data["broker-0.broker.properties"]), "name=first")
+	assert.Contains(t, string(properties.Data["broker-large-0.broker.properties"]), "name=first-large")
+
+	// and their own services
+	for _, ordinal := range []string{"0", "1", "large-0", "large-1", "large-2"} {
+		service := &v1.Service{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: cr.Name + "-amqp-" + ordinal + "-svc", Namespace: cr.Namespace}, service), ordinal)
+		assert.Equal(t, namer.CrToSS(cr.Name)+"-"+ordinal, service.Spec.Selector[PodNameLabelKey])
+	}
+
+	// the scale of each group is reported
+	common.ProcessStatus(cr, fakeClient, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, *MakeNamers(cr), nil)
+	assert.Equal(t, int32(3), cr.Status.BrokerGroups[0].Replicas)
+	assert.Equal(t, []string{"large-0", "large-1", "large-2"}, common.DeployedGroupOrdinals(cr))
+
+	// a removed group is deleted with its StatefulSet
+	cr.Spec.DeploymentPlan.BrokerGroups = nil
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	assert.Error(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "pools-ss-large", Namespace: cr.Namespace}, group))
+}
+
+func TestZonePlacement(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "test", UID: "backup-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:          utilpointer.Int32(2),
+				ZonePlacement: &brokerv1beta1.ZonePlacementType{Zones: []string{"a", "b"}, HAPairOf: "primary"},
+			},
+		},
+	}
+	primary := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "primary", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				ZonePlacement: &brokerv1beta1.ZonePlacementType{Zones: []string{"a", "b"}},
+			},
+		},
+	}
+	// the pair of ordinal 1 is in zone a
+	pairs := []client.Object{
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{v1.LabelTopologyZone: "a"}}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{v1.LabelTopologyZone: "b"}}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-x"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "primary-ss-0", Namespace: "test"}, Spec: v1.PodSpec{NodeName: "node-a"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "primary-ss-1", Namespace: "test"}, Spec: v1.PodSpec{NodeName: "node-a"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, primary).WithObjects(pairs...).Build()
+	recorder := record.NewFakeRecorder(10)
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestZonePlacement"), false)
+	outer.recorder = recorder
+	outer.podWebhookEnabled = true
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	template := ss.Spec.Template
+	assert.Empty(t, template.Spec.SchedulingGates)
+	assert.NotEmpty(t, template.Annotations[zonePlacementChecksumAnnotation])
+	assert.Len(t, template.Spec.TopologySpreadConstraints, 2)
+	assert.Equal(t, v1.LabelTopologyZone, template.Spec.TopologySpreadConstraints[0].TopologyKey)
+	assert.Equal(t, v1.DoNotSchedule, template.Spec.TopologySpreadConstraints[0].WhenUnsatisfiable)
+	assert.Equal(t, "backup", template.Spec.TopologySpreadConstraints[0].LabelSelector.MatchLabels[selectors.LabelResourceKey])
+	antiAffinity := template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Len(t, antiAffinity, 1)
+	assert.Equal(t, "primary", antiAffinity[0].PodAffinityTerm.LabelSelector.MatchLabels[selectors.LabelResourceKey])
+
+	for ordinal := 0; ordinal < 2; ordinal++ {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: namer.CrToSSOrdinal(cr.Name, ordinal), Namespace: cr.Namespace, Labels: template.Labels},
+			Spec:       *template.Spec.DeepCopy(),
+		}
+		assert.True(t, applyOrdinalOverrideToPod(cr, zonePairOf(cr, fakeClient), int32(ordinal), pod))
+		assert.NoError(t, fakeClient.Create(context.TODO(), pod))
+	}
+
+	// the ordinals are placed apart from the zones of the pair
+	pinned := &v1.Pod{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "backup-ss-0", Namespace: cr.Namespace}, pinned))
+	assert.Equal(t, "b", pinned.Spec.NodeSelector[v1.LabelTopologyZone])
+	pinned.Spec.NodeName = "node-b"
+	assert.NoError(t, fakeClient.Update(context.TODO(), pinned))
+
+	// ordinal 1 ends up in zone a with its pair
+	misplaced := &v1.Pod{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "backup-ss-1", Namespace: cr.Namespace}, misplaced))
+	assert.Equal(t, "a", misplaced.Spec.NodeSelector[v1.LabelTopologyZone])
+	misplaced.Spec.NodeName = "node-a"
+	assert.NoError(t, fakeClient.Update(context.TODO(), misplaced))
+
+	NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessZonePlacementStatus(cr, fakeClient)
+	assert.Equal(t, []brokerv1beta1.BrokerZoneStatus{
+		{Ordinal: 0, PlacementZone: "b", Zone: "b"},
+		{Ordinal: 1, PlacementZone: "a", Zone: "a", Message: "is in zone a with its HA pair primary-ss-1"},
+	}, cr.Status.Zones)
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, EventReasonZoneCoLocated)
+
+	// a warning is recorded once
+	NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessZonePlacementStatus(cr, fakeClient)
+	assert.Len(t, recorder.Events, 0)
+
+	// the zone is read from the node, not from the node selector of the pod
+	pinned.Spec.NodeName = "node-a"
+	assert.NoError(t, fakeClient.Update(context.TODO(), pinned))
+	NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessZonePlacementStatus(cr, fakeClient)
+	assert.Equal(t, brokerv1beta1.BrokerZoneStatus{Ordinal: 0, PlacementZone: "b", Zone: "a", Message: "is in zone a instead of b"}, cr.Status.Zones[0])
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, EventReasonZoneCoLocated)
+
+	// the zone of a pod on a node without the zone label is unknown
+	pinned.Spec.NodeName = "node-x"
+	assert.NoError(t, fakeClient.Update(context.TODO(), pinned))
+	NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessZonePlacementStatus(cr, fakeClient)
+	assert.Equal(t, unknownZone, cr.Status.Zones[0].Zone)
+	assert.Contains(t, cr.Status.Zones[0].Message, "zone unknown")
+	assert.Len(t, recorder.Events, 0)
+
+	// without the node reader ClusterRole the zones are unknown
+	forbiddenClient := interceptor.NewClient(fakeClient, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, isNode := obj.(*v1.Node); isNode {
+				return apierrors.NewForbidden(v1.Resource("nodes"), key.Name, errors.New("no cluster role"))
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	})
+	NewActiveMQArtemisReconcilerImpl(cr, outer).ProcessZonePlacementStatus(cr, forbiddenClient)
+	for _, zoneStatus := range cr.Status.Zones {
+		assert.Equal(t, unknownZone, zoneStatus.Zone)
+		assert.Contains(t, zoneStatus.Message, "node-reader-role")
+	}
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestSidecarsAndInitContainers(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "side", Namespace: "test", UID: "side-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				PersistenceEnabled: true,
+				Sidecars: []v1.Container{{
+					Name:         "log-shipper",
+					Image:        "shipper:1",
+					VolumeMounts: []v1.VolumeMount{{Name: "side", MountPath: "/data", ReadOnly: true}},
+				}},
+				InitContainers: []v1.Container{{Name: "fetch-plugins", Image: "fetch:1"}},
+			},
+			Console: brokerv1beta1.ConsoleType{Expose: true, SSLEnabled: true},
+		},
+	}
+	consoleSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "side-console-secret", Namespace: "test"},
+		Data:       map[string][]byte{"broker.ks": []byte("ks"), "client.ts": []byte("ts"), "keyStorePassword": []byte("pw"), "trustStorePassword": []byte("pw")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, consoleSecret).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestSidecarsAndInitContainers"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	podSpec := ss.Spec.Template.Spec
+
+	assert.Len(t, podSpec.Containers, 2)
+	assert.Equal(t, "side-container", podSpec.Containers[0].Name)
+	sidecar := podSpec.Containers[1]
+	assert.Equal(t, "log-shipper", sidecar.Name)
+	assert.Equal(t, "/data", sidecar.VolumeMounts[0].MountPath)
+	// the data volume of the broker is in the pod
+	found := false
+	for _, volume := range podSpec.Volumes {
+		found = found || volume.Name == sidecar.VolumeMounts[0].Name
+	}
+	assert.True(t, found)
+	// the env of the broker stays in the broker container
+	assert.NotNil(t, environments.RetrieveFrom(podSpec.Containers[0], "AMQ_USER"))
+	assert.NotNil(t, environments.RetrieveFrom(podSpec.Containers[0], "AMQ_CONSOLE_ARGS"))
+	assert.Empty(t, sidecar.Env)
+
+	assert.Len(t, podSpec.InitContainers, 2)
+	assert.Equal(t, "side-container-init", podSpec.InitContainers[0].Name)
+	assert.Equal(t, "fetch-plugins", podSpec.InitContainers[1].Name)
+	assert.Empty(t, podSpec.InitContainers[1].Env)
+}
+
+func TestJvm(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "jvm", Namespace: "test", UID: "jvm-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				PersistenceEnabled: true,
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+				Sidecars: []v1.Container{{Name: "log-shipper", Image: "shipper:1"}},
+				Jvm: &brokerv1beta1.JvmType{
+					HeapPercentage:   utilpointer.Int32(50),
+					GarbageCollector: "Z",
+					FlightRecorder:   &brokerv1beta1.FlightRecorderType{VolumeName: "jvm", MaxAge: "6h", MaxSize: "250Mi"},
+				},
+			},
+			Env: []v1.EnvVar{{Name: "JAVA_ARGS_APPEND", Value: "-Dfrom.spec=true"}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestJvm"), false)
+
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	broker := ss.Spec.Template.Spec.Containers[0]
+
+	javaArgsAppend := environments.RetrieveFrom(broker, "JAVA_ARGS_APPEND")
+	if assert.NotNil(t, javaArgsAppend) {
+		// the heap is computed from the memory limit and overrides that of artemis.profile
+		assert.True(t, strings.HasPrefix(javaArgsAppend.Value, "-Dfrom.spec=true -Xms1024m -Xmx1024m -XX:-UseG1GC -XX:+UseZGC"), javaArgsAppend.Value)
+		assert.Contains(t, javaArgsAppend.Value, "-XX:StartFlightRecording=name=continuous,disk=true,dumponexit=true,filename=/amq/jfr/,maxage=21600s,maxsize=250m")
+	}
+	found := false
+	for _, mount := range broker.VolumeMounts {
+		found = found || (mount.Name == "jvm" && mount.MountPath == "/amq/jfr" && mount.SubPath == "jfr")
+	}
+	assert.True(t, found)
+
+	assert.Nil(t, environments.RetrieveFrom(ss.Spec.Template.Spec.Containers[1], "JAVA_ARGS_APPEND"))
+}
+
+func TestGeneratedPasswordsAreMasked(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "masked", Namespace: "test", UID: "masked-uid"},
+	}
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestGeneratedPasswordsAreMasked"), false)
+	credentialsKey := types.NamespacedName{Name: MakeNamers(cr).SecretsCredentialsNameBuilder.Name(), Namespace: cr.Namespace}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	storeStringDataAsData(t, fakeClient)
+
+	generated := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), credentialsKey, generated))
+	assert.False(t, codec.IsMasked(string(generated.Data["AMQ_PASSWORD"])))
+
+	// passwords generated before the codec keep their value
+	cr.Spec.PasswordCodec = &brokerv1beta1.PasswordCodecType{}
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	credentials := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), credentialsKey, credentials))
+	assert.Equal(t, generated.Data["AMQ_USER"], credentials.Data["AMQ_USER"])
+	for _, key := range []string{"AMQ_PASSWORD", "AMQ_CLUSTER_PASSWORD"} {
+		value := string(credentials.Data[key])
+		assert.True(t, codec.IsMasked(value), key)
+		unmasked, err := codec.Unmask(value, codec.DefaultKey)
+		assert.NoError(t, err)
+		assert.Equal(t, string(generated.Data[key]), unmasked, key)
+	}
+
+	// and a new secret is masked from the start
+	fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+	assert.NoError(t, fakeClient.Get(context.TODO(), credentialsKey, credentials))
+	assert.True(t, codec.IsMasked(credentials.StringData["AMQ_PASSWORD"]))
+	assert.True(t, codec.IsMasked(credentials.StringData["AMQ_CLUSTER_PASSWORD"]))
+	assert.False(t, codec.IsMasked(credentials.StringData["AMQ_USER"]))
+}
+
+func TestAdminPasswordFromSourceIsProjected(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		TypeMeta:   metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "sourced", Namespace: "test", UID: "sourced-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			AdminUser: "admin",
+			AdminPasswordFrom: &brokerv1beta1.SecretSourceType{
+				Secret: &brokerv1beta1.SecretSourceSecretType{Name: "store", Key: "password"},
+			},
+		},
+	}
+	outer := NewActiveMQArtemisReconciler(&NillCluster{}, ctrl.Log.WithName("TestAdminPasswordFromSourceIsProjected"), false)
+
+	// resolved on process, a missing source fails it
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr).Build()
+	err := NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme)
+	assert.ErrorContains(t, err, ".Spec.AdminPasswordFrom")
+
+	store := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("from-store")},
+	}
+	fakeClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(cr, store).Build()
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	credentials := &v1.Secret{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: MakeNamers(cr).SecretsCredentialsNameBuilder.Name(), Namespace: cr.Namespace}, credentials))
+	assert.Equal(t, "from-store", credentials.StringData["AMQ_PASSWORD"])
+
+	// from the deployed statefulset
+	storeStringDataAsData(t, fakeClient)
+	assert.NoError(t, NewActiveMQArtemisReconcilerImpl(cr, outer).Process(cr, *MakeNamers(cr), fakeClient, testScheme))
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: namer.CrToSS(cr.Name), Namespace: cr.Namespace}, ss))
+	broker := ss.Spec.Template.Spec.Containers[0]
+	init := ss.Spec.Template.Spec.InitContainers[0]
+
+	assert.Nil(t, environments.RetrieveFrom(broker, "AMQ_PASSWORD"))
+	assert.Nil(t, environments.RetrieveFrom(init, "AMQ_PASSWORD"))
+	assert.NotNil(t, environments.RetrieveFrom(broker, "AMQ_USER"))
+
+	volumeFound := false
+	for _, volume := range ss.Spec.Template.Spec.Volumes {
+		if volume.Name == adminPasswordVolumeName {
+			assert.False(t, volumeFound)
+			volumeFound = true
+			assert.Equal(t, credentials.Name, volume.Secret.SecretName)
+			assert.Equal(t, []v1.KeyToPath{{Key: "AMQ_PASSWORD", Path: adminPasswordFileName}}, volume.Secret.Items)
+		}
+	}
+	assert.True(t, volumeFound)
+	for _, container := range []v1.Container{broker, init} {
+		mounts := 0
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == adminPasswordVolumeName && mount.MountPath == adminPasswordMountPath {
+				mounts++
+			}
+		}
+		assert.Equal(t, 1, mounts, container.Name)
+	}
+	assert.True(t, strings.HasPrefix(broker.Command[2], "export AMQ_PASSWORD=$(cat /amq/extra/credentials/admin-password); export STATEFUL_SET_ORDINAL"), broker.Command[2])
+	assert.True(t, strings.HasPrefix(init.Args[1], "export AMQ_PASSWORD=$(cat /amq/extra/credentials/admin-password) && "), init.Args[1])
+
+	// the export is not repeated on the carried over command and goes with the source
+	template := ss.Spec.Template.DeepCopy()
+	applyAdminPasswordFileToTemplate(cr, *MakeNamers(cr), template)
+	assert.Equal(t, 1, strings.Count(template.Spec.Containers[0].Command[2], adminPasswordFileExport))
+	cr.Spec.AdminPasswordFrom = nil
+	template = ss.Spec.Template.DeepCopy()
+	applyAdminPasswordFileToTemplate(cr, *MakeNamers(cr), template)
+	assert.NotContains(t, template.Spec.Containers[0].Command[2], adminPasswordFileExport)
+}
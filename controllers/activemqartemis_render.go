@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// RenderManifests returns the resources that the operator deploys for a CR,
+// without a cluster. The referenced resources, like secrets, are looked up in
+// objects. The generated resources carry no owner, uid or resource version.
+// The credentials Secret is left out, its generated values are random and
+// secret, it has to be provided to the cluster apart.
+func RenderManifests(customResource *brokerv1beta1.ActiveMQArtemis, objects []rtclient.Object, scheme *runtime.Scheme, isOnOpenShift bool) ([]rtclient.Object, error) {
+	cr := customResource.DeepCopy()
+	cr.TypeMeta = metav1.TypeMeta{Kind: "ActiveMQArtemis", APIVersion: brokerv1beta1.GroupVersion.String()}
+	if cr.UID == "" {
+		// owned resources are found through the uid of their owner
+		cr.UID = types.UID(cr.Namespace + "/" + cr.Name)
+	}
+
+	stored := []rtclient.Object{cr}
+	for _, obj := range objects {
+		if secret, ok := obj.(*corev1.Secret); ok {
+			// as the api server would store it
+			secret = mergeSecretStringDataToData(secret)
+			secret.StringData = nil
+			obj = secret
+		}
+		stored = append(stored, obj)
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stored...).Build()
+	parent := &ActiveMQArtemisReconciler{
+		Client:        client,
+		Scheme:        scheme,
+		log:           ctrl.Log.WithName("render"),
+		isOnOpenShift: isOnOpenShift,
+	}
+	reconciler := NewActiveMQArtemisReconcilerImpl(cr, parent)
+	namer := MakeNamers(cr)
+
+	// a missing resource will not show up later, there is nothing to retry
+	if condition, _ := reconciler.validationCondition(cr, client, *namer); condition.Status == metav1.ConditionFalse {
+		return nil, errors.New(condition.Message)
+	}
+	if err := reconciler.Process(cr, *namer, client, scheme); err != nil {
+		return nil, err
+	}
+
+	deployed, err := common.GetDeployedResources(cr, client, isOnOpenShift)
+	if err != nil {
+		return nil, err
+	}
+	var rendered []rtclient.Object
+	for _, resourceType := range getOrderedTypeList() {
+		for _, obj := range sortedByName(deployed[resourceType]) {
+			if _, ok := obj.(*corev1.Secret); ok && obj.GetName() == namer.SecretsCredentialsNameBuilder.Name() {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, scheme)
+			if err != nil {
+				return nil, err
+			}
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+			obj.SetUID("")
+			obj.SetResourceVersion("")
+			obj.SetOwnerReferences(nil)
+			obj.SetManagedFields(nil)
+			rendered = append(rendered, obj)
+		}
+	}
+	return rendered, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateRestore holds the creation of the brokers until the backup to restore
+// has every snapshot ready, the restore does not apply once they are deployed
+func (r *ActiveMQArtemisReconcilerImpl) validateRestore(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namer common.Namers) (*metav1.Condition, bool) {
+	if customResource.Spec.DeploymentPlan.Storage.RestoreFrom == nil {
+		return nil, false
+	}
+	invalid := func(message string, retry bool) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidRestore,
+			Message: message,
+		}, retry
+	}
+	if condition, _ := validateRestoreSpec(customResource); condition != nil {
+		return condition, false
+	}
+
+	deployed := &appsv1.StatefulSet{}
+	if retrieveResource(namer.SsNameBuilder.Name(), customResource.Namespace, deployed, client) {
+		return nil, false
+	}
+	// the backup may still be in progress
+	if _, err := restoreSnapshots(customResource, client); err != nil {
+		return invalid(err.Error(), true)
+	}
+	return nil, false
+}
+
+func validateRestoreSpec(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	if customResource.Spec.DeploymentPlan.Storage.RestoreFrom == nil {
+		return nil, false
+	}
+	invalid := func(message string) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidRestore,
+			Message: message,
+		}, false
+	}
+	if !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		return invalid(".Spec.DeploymentPlan.Storage.RestoreFrom needs .Spec.DeploymentPlan.PersistenceEnabled")
+	}
+	if customResource.Spec.DeploymentPlan.Storage.RestoreFrom.Backup == "" {
+		return invalid(".Spec.DeploymentPlan.Storage.RestoreFrom.Backup is required")
+	}
+	return nil, false
+}
+
+// restoreSnapshots are the snapshots of the backup to restore by ordinal
+func restoreSnapshots(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) (map[int32]brokerv1beta1.BackupSnapshotStatus, error) {
+	restore := customResource.Spec.DeploymentPlan.Storage.RestoreFrom
+	backup := &brokerv1beta1.ActiveMQArtemisBackup{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: restore.Backup, Namespace: customResource.Namespace}, backup); err != nil {
+		return nil, fmt.Errorf(".Spec.DeploymentPlan.Storage.RestoreFrom ActiveMQArtemisBackup %s can not be read, %v", restore.Backup, err)
+	}
+
+	id := restore.BackupID
+	if id == "" {
+		for _, candidate := range backupIDsOf(backup) {
+			if isRestorable(snapshotsOfBackup(backup, candidate)) {
+				id = candidate
+				break
+			}
+		}
+		if id == "" {
+			return nil, fmt.Errorf(".Spec.DeploymentPlan.Storage.RestoreFrom ActiveMQArtemisBackup %s has no backup with every snapshot ready to use", restore.Backup)
+		}
+	}
+	snapshots := snapshotsOfBackup(backup, id)
+	if !isRestorable(snapshots) {
+		return nil, fmt.Errorf(".Spec.DeploymentPlan.Storage.RestoreFrom backup %s of ActiveMQArtemisBackup %s is not ready to use", id, restore.Backup)
+	}
+
+	byOrdinal := map[int32]brokerv1beta1.BackupSnapshotStatus{}
+	for _, snapshot := range snapshots {
+		byOrdinal[snapshot.Ordinal] = snapshot
+	}
+	return byOrdinal, nil
+}
+
+// restoreVolumeClaims creates the journal claims of a new StatefulSet from the
+// snapshots of the backup before the StatefulSet makes them empty. A claim that
+// exists is kept, as are the ordinals without a snapshot.
+func (reconciler *ActiveMQArtemisReconcilerImpl) restoreVolumeClaims(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, statefulSet *appsv1.StatefulSet) error {
+	if customResource.Spec.DeploymentPlan.Storage.RestoreFrom == nil || !customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		return nil
+	}
+	// the backups are of the brokers of the deployment plan
+	if statefulSet.Name != namer.CrToSS(customResource.Name) {
+		return nil
+	}
+	template := findClaimTemplate(statefulSet.Spec.VolumeClaimTemplates, customResource.Name)
+	if template == nil {
+		return nil
+	}
+	snapshots, err := restoreSnapshots(customResource, client)
+	if err != nil {
+		return err
+	}
+
+	apiGroup := volumeSnapshotGVK.Group
+	for ordinal := 0; ordinal < int(replicasOf(statefulSet)); ordinal++ {
+		snapshot, found := snapshots[int32(ordinal)]
+		if !found {
+			continue
+		}
+		claim := &corev1.PersistentVolumeClaim{}
+		claimName := namer.CrToJournalClaim(customResource.Name, ordinal)
+		if retrieveResource(claimName, customResource.Namespace, claim, client) {
+			continue
+		}
+
+		// the labels the StatefulSet gives to the claims of its template
+		labels := map[string]string{}
+		for key, value := range template.Labels {
+			labels[key] = value
+		}
+		if statefulSet.Spec.Selector != nil {
+			for key, value := range statefulSet.Spec.Selector.MatchLabels {
+				labels[key] = value
+			}
+		}
+		claim = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        claimName,
+				Namespace:   customResource.Namespace,
+				Labels:      labels,
+				Annotations: template.Annotations,
+			},
+			Spec: *template.Spec.DeepCopy(),
+		}
+		claim.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: &apiGroup,
+			Kind:     volumeSnapshotGVK.Kind,
+			Name:     snapshot.Name,
+		}
+		// a volume can not be smaller than its snapshot
+		if restoreSize, err := resource.ParseQuantity(snapshot.RestoreSize); err == nil && restoreSize.Cmp(*claim.Spec.Resources.Requests.Storage()) > 0 {
+			if claim.Spec.Resources.Requests == nil {
+				claim.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			claim.Spec.Resources.Requests[corev1.ResourceStorage] = restoreSize
+		}
+
+		if err := client.Create(context.TODO(), claim); err != nil {
+			return fmt.Errorf("unable to create volume claim %s from volume snapshot %s, %v", claimName, snapshot.Name, err)
+		}
+		recordEvent(reconciler.recorder, customResource, corev1.EventTypeNormal, EventReasonRestoringVolume, MessageRestoringVolume, claimName, snapshot.Name)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func validateContainers(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	sidecars := customResource.Spec.DeploymentPlan.Sidecars
+	initContainers := customResource.Spec.DeploymentPlan.InitContainers
+	if len(sidecars) == 0 && len(initContainers) == 0 {
+		return nil, false
+	}
+	invalid := func(format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidContainers,
+			Message: fmt.Sprintf(format, args...),
+		}, false
+	}
+
+	// the names of the containers of the operator
+	names := map[string]string{
+		customResource.Name + "-container":      "the broker container",
+		customResource.Name + "-container-init": "the init container of the operator",
+	}
+	portNames := map[string]string{}
+	ports := map[int32]string{}
+	for _, port := range MakeContainerPorts(customResource) {
+		portNames[port.Name] = "the broker container"
+		ports[port.ContainerPort] = "the broker container"
+	}
+
+	check := func(field string, containers []corev1.Container) (*metav1.Condition, bool) {
+		for index, container := range containers {
+			containerField := fmt.Sprintf("%s[%d]", field, index)
+			if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+				return invalid("%s.Name %q is not a valid container name, %v", containerField, container.Name, errs)
+			}
+			if owner, found := names[container.Name]; found {
+				return invalid("%s.Name %s is the name of %s", containerField, container.Name, owner)
+			}
+			names[container.Name] = containerField
+			if container.Image == "" {
+				return invalid("%s.Image must be set", containerField)
+			}
+			// the containers of a pod share its network
+			for _, port := range container.Ports {
+				if owner, found := portNames[port.Name]; found && port.Name != "" {
+					return invalid("%s.Ports %s is the name of a port of %s", containerField, port.Name, owner)
+				}
+				if owner, found := ports[port.ContainerPort]; found {
+					return invalid("%s.Ports %d is a port of %s", containerField, port.ContainerPort, owner)
+				}
+				if port.Name != "" {
+					portNames[port.Name] = containerField
+				}
+				ports[port.ContainerPort] = containerField
+			}
+		}
+		return nil, false
+	}
+	if condition, retry := check("Spec.DeploymentPlan.Sidecars", sidecars); condition != nil {
+		return condition, retry
+	}
+	return check("Spec.DeploymentPlan.InitContainers", initContainers)
+}
+
+// applyContainersToTemplate adds the sidecars after the broker container and
+// the init containers after that of the operator. The volumes of the pod
+// template are theirs to mount by name.
+func applyContainersToTemplate(customResource *brokerv1beta1.ActiveMQArtemis, template *corev1.PodTemplateSpec) {
+	for _, sidecar := range customResource.Spec.DeploymentPlan.Sidecars {
+		template.Spec.Containers = append(template.Spec.Containers, *sidecar.DeepCopy())
+	}
+	for _, initContainer := range customResource.Spec.DeploymentPlan.InitContainers {
+		template.Spec.InitContainers = append(template.Spec.InitContainers, *initContainer.DeepCopy())
+	}
+}
+
+// the containers of the operator come before those of the deployment plan, the
+// env of the broker is not for the sidecars
+func operatorContainersOf(containers []corev1.Container, added []corev1.Container) []corev1.Container {
+	if len(added) > len(containers) {
+		return containers
+	}
+	return containers[:len(containers)-len(added)]
+}
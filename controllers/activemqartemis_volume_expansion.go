@@ -0,0 +1,325 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	VolumeExpansionPending                 = "Pending"
+	VolumeExpansionResizing                = "Resizing"
+	VolumeExpansionFileSystemResizePending = "FileSystemResizePending"
+	VolumeExpansionFailed                  = "Failed"
+	VolumeExpansionUnsupported             = "Unsupported"
+)
+
+// expandVolumeClaims grows the claims of the brokers to the larger size of
+// their template. The templates of a StatefulSet are immutable, so once the
+// claims are patched the StatefulSet is deleted leaving its pods running and
+// the next reconcile creates it with the new templates. A template whose claims
+// can not grow stays as deployed. The StatefulSet is created again from the
+// requested pod template, so while the maintenance window holds that template
+// the claims grow but the StatefulSet keeps its templates until the window.
+// It returns true when the StatefulSet is deleted.
+func (reconciler *ActiveMQArtemisReconcilerImpl) expandVolumeClaims(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, deployed *appsv1.StatefulSet, requested *appsv1.StatefulSet, held bool) bool {
+	if deployed == nil {
+		return false
+	}
+
+	recreate := false
+	for index := range requested.Spec.VolumeClaimTemplates {
+		template := &requested.Spec.VolumeClaimTemplates[index]
+		deployedTemplate := findClaimTemplate(deployed.Spec.VolumeClaimTemplates, template.Name)
+		if deployedTemplate == nil {
+			continue
+		}
+		size, deployedSize := template.Spec.Resources.Requests.Storage(), deployedTemplate.Spec.Resources.Requests.Storage()
+		if size.Cmp(*deployedSize) == 0 {
+			continue
+		}
+		// a smaller size is rejected by validation
+		if size.Cmp(*deployedSize) > 0 && reconciler.expandClaimsOf(customResource, client, deployed, template.Name, *size) {
+			recreate = true
+			continue
+		}
+		template.Spec.Resources = *deployedTemplate.Spec.Resources.DeepCopy()
+	}
+
+	if !recreate {
+		return false
+	}
+	if held {
+		reconciler.log.V(1).Info("holding the expanded volume claim templates for the maintenance window", "name", deployed.Name)
+		reconciler.pendingRollout = append(reconciler.pendingRollout, "spec.volumeClaimTemplates")
+		requested.Spec.VolumeClaimTemplates = deployed.DeepCopy().Spec.VolumeClaimTemplates
+		return false
+	}
+	if reconciler.planning() {
+		reconciler.addPlannedChange(reflect.TypeOf(appsv1.StatefulSet{}), deployed, brokerv1beta1.PlanActionDelete, nil)
+		reconciler.addPlannedChange(reflect.TypeOf(appsv1.StatefulSet{}), requested, brokerv1beta1.PlanActionCreate, nil)
+		return true
+	}
+	reconciler.log.V(1).Info("recreating the statefulset with the expanded volume claim templates, orphaning the pods", "name", deployed.Name)
+	if err := client.Delete(context.TODO(), deployed, rtclient.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !apierrors.IsNotFound(err) {
+		reconciler.log.Error(err, "failed to delete the statefulset for the expanded volume claim templates", "name", deployed.Name)
+		return false
+	}
+	return true
+}
+
+// expandClaimsOf patches the claims of a template, it returns false when one of
+// them can not grow
+func (reconciler *ActiveMQArtemisReconcilerImpl) expandClaimsOf(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, statefulSet *appsv1.StatefulSet, templateName string, size resource.Quantity) bool {
+	expandable := true
+	for _, claim := range claimsOf(client, statefulSet.Namespace, templateName, statefulSet.Name) {
+		if claim.Spec.Resources.Requests.Storage().Cmp(size) >= 0 {
+			continue
+		}
+		failed := func(state string, message string) {
+			reconciler.volumeExpansion = append(reconciler.volumeExpansion, brokerv1beta1.VolumeExpansionStatus{
+				Name:          claim.Name,
+				RequestedSize: size.String(),
+				Capacity:      claim.Status.Capacity.Storage().String(),
+				State:         state,
+				Message:       message,
+			})
+			expandable = false
+		}
+
+		if allowed, message := volumeExpansionAllowed(client, claim.Spec.StorageClassName); !allowed {
+			failed(VolumeExpansionUnsupported, message)
+			continue
+		}
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if reconciler.planning() {
+			reconciler.addPlannedChange(reflect.TypeOf(corev1.PersistentVolumeClaim{}), &claim, brokerv1beta1.PlanActionUpdate, []string{"spec.resources.requests.storage"})
+			continue
+		}
+		if err := resources.Update(client, &claim); err != nil {
+			failed(VolumeExpansionFailed, err.Error())
+			continue
+		}
+		recordEvent(reconciler.recorder, customResource, corev1.EventTypeNormal, EventReasonVolumeExpanding, MessageVolumeExpanding, claim.Name, size.String())
+	}
+	return expandable
+}
+
+// the operator may lack the permission to read the storage classes, the api
+// server then rejects the update of a claim that can not grow
+func volumeExpansionAllowed(client rtclient.Client, storageClassName *string) (bool, string) {
+	if storageClassName == nil || *storageClassName == "" {
+		return false, "the claim has no storage class"
+	}
+	storageClass := &storagev1.StorageClass{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: *storageClassName}, storageClass); err != nil {
+		if apierrors.IsForbidden(err) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("storage class %s can not be read, %v", *storageClassName, err)
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return false, fmt.Sprintf("storage class %s does not allow volume expansion", *storageClassName)
+	}
+	return true, ""
+}
+
+// claimsOf lists the claims a StatefulSet made from a template, named
+// <template>-<statefulset>-<ordinal>, including those of scaled down ordinals
+func claimsOf(client rtclient.Client, namespace string, templateName string, statefulSetName string) []corev1.PersistentVolumeClaim {
+	list := &corev1.PersistentVolumeClaimList{}
+	if err := client.List(context.TODO(), list, rtclient.InNamespace(namespace)); err != nil {
+		return nil
+	}
+	prefix := templateName + "-" + statefulSetName + "-"
+	var claims []corev1.PersistentVolumeClaim
+	for _, claim := range list.Items {
+		if ordinal, found := strings.CutPrefix(claim.Name, prefix); found {
+			if _, err := strconv.Atoi(ordinal); err == nil {
+				claims = append(claims, claim)
+			}
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].Name < claims[j].Name })
+	return claims
+}
+
+func claimsOfStatefulSets(client rtclient.Client, namespace string, templateName string, statefulSetNames []string) []corev1.PersistentVolumeClaim {
+	var claims []corev1.PersistentVolumeClaim
+	for _, statefulSetName := range statefulSetNames {
+		claims = append(claims, claimsOf(client, namespace, templateName, statefulSetName)...)
+	}
+	return claims
+}
+
+func findClaimTemplate(templates []corev1.PersistentVolumeClaim, name string) *corev1.PersistentVolumeClaim {
+	for index := range templates {
+		if templates[index].Name == name {
+			return &templates[index]
+		}
+	}
+	return nil
+}
+
+// updateVolumeExpansionStatus reports the claims that can not grow and the
+// bound claims whose capacity is yet to reach their request
+func (reconciler *ActiveMQArtemisReconcilerImpl) updateVolumeExpansionStatus(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) {
+	expansion := append([]brokerv1beta1.VolumeExpansionStatus(nil), reconciler.volumeExpansion...)
+	reported := map[string]bool{}
+	for _, status := range expansion {
+		reported[status.Name] = true
+	}
+
+	statefulSetNames := []string{namer.CrToSS(customResource.Name)}
+	for _, group := range customResource.Spec.DeploymentPlan.BrokerGroups {
+		statefulSetNames = append(statefulSetNames, namer.CrToGroupSS(customResource.Name, group.Name))
+	}
+	for _, templateName := range volumeClaimTemplateNames(customResource) {
+		for _, claim := range claimsOfStatefulSets(client, customResource.Namespace, templateName, statefulSetNames) {
+			requested, capacity := claim.Spec.Resources.Requests.Storage(), claim.Status.Capacity.Storage()
+			if reported[claim.Name] || claim.Status.Phase != corev1.ClaimBound || capacity.Cmp(*requested) >= 0 {
+				continue
+			}
+			state, message := expansionStateOf(&claim)
+			expansion = append(expansion, brokerv1beta1.VolumeExpansionStatus{
+				Name:          claim.Name,
+				RequestedSize: requested.String(),
+				Capacity:      capacity.String(),
+				State:         state,
+				Message:       message,
+			})
+		}
+	}
+	sort.Slice(expansion, func(i, j int) bool { return expansion[i].Name < expansion[j].Name })
+	customResource.Status.VolumeExpansion = expansion
+}
+
+func expansionStateOf(claim *corev1.PersistentVolumeClaim) (string, string) {
+	state := VolumeExpansionPending
+	for _, condition := range claim.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(string(condition.Type), "ResizeError"):
+			return VolumeExpansionFailed, condition.Message
+		case condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending:
+			state = VolumeExpansionFileSystemResizePending
+		case condition.Type == corev1.PersistentVolumeClaimResizing && state == VolumeExpansionPending:
+			state = VolumeExpansionResizing
+		}
+	}
+	return state, ""
+}
+
+func volumeClaimTemplateNames(customResource *brokerv1beta1.ActiveMQArtemis) []string {
+	var names []string
+	if customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		names = append(names, customResource.Name)
+	}
+	for _, template := range customResource.Spec.DeploymentPlan.ExtraVolumeClaimTemplates {
+		names = append(names, template.Name)
+	}
+	return names
+}
+
+// a claim that is growing needs a reconcile to report its progress, there is
+// no watch on the claims
+func isVolumeExpansionInProgress(customResource *brokerv1beta1.ActiveMQArtemis) bool {
+	for _, status := range customResource.Status.VolumeExpansion {
+		if status.State != VolumeExpansionUnsupported && status.State != VolumeExpansionFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVolumeClaimSizes rejects a template size below that of the deployed
+// StatefulSet of the deployment plan or of a broker group, a volume can not shrink
+func (r *ActiveMQArtemisReconcilerImpl) validateVolumeClaimSizes(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namers common.Namers) (*metav1.Condition, bool) {
+	if len(volumeClaimTemplateNames(customResource)) == 0 {
+		return nil, false
+	}
+	claims := r.PersistentVolumeClaimArrayForCR(customResource, namers, appsv1.StatefulSetSpec{})
+	if condition := validateVolumeClaimSizesOf(client, customResource.Namespace, namers.SsNameBuilder.Name(), claims, customResource.Name, nil); condition != nil {
+		return condition, false
+	}
+	for _, group := range customResource.Spec.DeploymentPlan.BrokerGroups {
+		var journalSize *resource.Quantity
+		if group.Storage != nil && group.Storage.Size != "" {
+			size, err := resource.ParseQuantity(group.Storage.Size)
+			if err != nil {
+				// reported by the validation of the groups
+				continue
+			}
+			journalSize = &size
+		}
+		if condition := validateVolumeClaimSizesOf(client, customResource.Namespace, namer.CrToGroupSS(customResource.Name, group.Name), claims, customResource.Name, journalSize); condition != nil {
+			return condition, false
+		}
+	}
+	return nil, false
+}
+
+// validateVolumeClaimSizesOf compares the claim templates with those of a
+// deployed StatefulSet, the journal template of a broker group can have its own size
+func validateVolumeClaimSizesOf(client rtclient.Client, namespace string, statefulSetName string, claims []corev1.PersistentVolumeClaim, journalName string, journalSize *resource.Quantity) *metav1.Condition {
+	deployed := &appsv1.StatefulSet{}
+	if !retrieveResource(statefulSetName, namespace, deployed, client) {
+		return nil
+	}
+	for _, claim := range claims {
+		deployedTemplate := findClaimTemplate(deployed.Spec.VolumeClaimTemplates, claim.Name)
+		if deployedTemplate == nil {
+			continue
+		}
+		size, deployedSize := claim.Spec.Resources.Requests.Storage(), deployedTemplate.Spec.Resources.Requests.Storage()
+		if claim.Name == journalName && journalSize != nil {
+			size = journalSize
+		}
+		if size.Cmp(*deployedSize) < 0 {
+			return &metav1.Condition{
+				Type:    brokerv1beta1.ValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.ValidConditionFailedStorageShrink,
+				Message: fmt.Sprintf("the size %s of volume claim template %s of %s is below the deployed size %s, a volume can not shrink", size.String(), claim.Name, statefulSetName, deployedSize.String()),
+			}
+		}
+	}
+	return nil
+}
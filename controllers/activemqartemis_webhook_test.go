@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// +kubebuilder:docs-gen:collapse=Apache License
+
+package controllers
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+)
+
+type warningCollector struct {
+	messages []string
+}
+
+func (w *warningCollector) HandleWarningHeader(code int, agent string, message string) {
+	w.messages = append(w.messages, message)
+}
+
+var _ = Describe("admission webhooks", Label("webhooks"), func() {
+
+	It("rejects invalid CRs", func() {
+		if os.Getenv("USE_EXISTING_CLUSTER") == "true" || os.Getenv("DEPLOY_OPERATOR") == "true" {
+			Skip("the webhooks are served to an envtest api server")
+		}
+
+		webhookEnv, webhookConfig, webhookCancel := setUpWebhookEnvTest()
+		defer func() {
+			webhookCancel()
+			Expect(webhookEnv.Stop()).To(Succeed())
+		}()
+
+		warnings := &warningCollector{}
+		warningConfig := rest.CopyConfig(webhookConfig)
+		warningConfig.WarningHandler = warnings
+		// our handler is only kept when the client does not install its own
+		webhookClient, err := client.New(warningConfig, client.Options{
+			Scheme:         scheme.Scheme,
+			WarningHandler: client.WarningHandlerOptions{SuppressWarnings: true},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("rejecting an ActiveMQArtemis with a duplicate port")
+		err = webhookClient.Create(ctx, newDuplicatePortBroker("dup"))
+		Expect(err).To(MatchError(ContainSubstring(`.Spec.Acceptors "b" and "a" contain a duplicate port 61617`)))
+
+		valid := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: "valid", Namespace: "default"}}
+		Expect(webhookClient.Create(ctx, valid)).To(Succeed())
+
+		valid.Spec.Acceptors = newDuplicatePortBroker("dup").Spec.Acceptors
+		Expect(webhookClient.Update(ctx, valid)).To(MatchError(ContainSubstring("contain a duplicate port")))
+
+		By("admitting an SSL console before its secret, the reconcile waits for it")
+		Expect(webhookClient.Create(ctx, newSSLConsoleBroker("ssl"))).To(Succeed())
+		Expect(warnings.messages).To(BeEmpty())
+
+		By("rejecting an ActiveMQArtemisAddress without a name")
+		err = webhookClient.Create(ctx, &brokerv1beta1.ActiveMQArtemisAddress{
+			ObjectMeta: metav1.ObjectMeta{Name: "address", Namespace: "default"},
+		})
+		Expect(err).To(MatchError(ContainSubstring(".Spec.AddressName is required")))
+
+		By("rejecting an ActiveMQArtemisSecurity with a missing login module")
+		err = webhookClient.Create(ctx, newSecurityWithDomainModule("missing", "sufficient"))
+		Expect(err).To(MatchError(ContainSubstring(`references login module "missing" that is not defined`)))
+
+		Expect(webhookClient.Create(ctx, newSecurityWithDomainModule("props", "sufficient"))).To(Succeed())
+	})
+})
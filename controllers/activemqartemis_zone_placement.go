@@ -0,0 +1,306 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/adler32"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the pod template annotation that rolls the brokers when their zones change
+const zonePlacementChecksumAnnotation = "arkmq.org/zone-placement-checksum"
+
+// the zone of the status of a scheduled broker whose node can not be read
+const unknownZone = "unknown"
+
+func zoneTopologyKeyOf(placement *brokerv1beta1.ZonePlacementType) string {
+	if placement.TopologyKey == "" {
+		return corev1.LabelTopologyZone
+	}
+	return placement.TopologyKey
+}
+
+func validateZonePlacement(customResource *brokerv1beta1.ActiveMQArtemis) (*metav1.Condition, bool) {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil {
+		return nil, false
+	}
+	invalid := func(format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidZonePlacement,
+			Message: fmt.Sprintf(format, args...),
+		}, false
+	}
+
+	if len(placement.Zones) == 0 {
+		return invalid("Spec.DeploymentPlan.ZonePlacement.Zones must have at least one zone")
+	}
+	zones := map[string]bool{}
+	for _, zone := range placement.Zones {
+		if errs := validation.IsValidLabelValue(zone); zone == "" || len(errs) > 0 {
+			return invalid("Spec.DeploymentPlan.ZonePlacement.Zones %q is not a valid zone, %v", zone, errs)
+		}
+		if zones[zone] {
+			return invalid("Spec.DeploymentPlan.ZonePlacement.Zones %s is not unique", zone)
+		}
+		zones[zone] = true
+	}
+	topologyKey := zoneTopologyKeyOf(placement)
+	if errs := validation.IsQualifiedName(topologyKey); len(errs) > 0 {
+		return invalid("Spec.DeploymentPlan.ZonePlacement.TopologyKey %s is not a valid label, %v", topologyKey, errs)
+	}
+	if placement.HAPairOf != "" {
+		if placement.HAPairOf == customResource.Name {
+			return invalid("Spec.DeploymentPlan.ZonePlacement.HAPairOf must name another ActiveMQArtemis")
+		}
+		if len(placement.Zones) < 2 {
+			return invalid("Spec.DeploymentPlan.ZonePlacement.HAPairOf needs at least two zones to place the brokers apart from their pairs")
+		}
+	}
+
+	// the operator selects the zone of each ordinal
+	if _, found := customResource.Spec.DeploymentPlan.NodeSelector[topologyKey]; found {
+		return invalid("Spec.DeploymentPlan.NodeSelector %s conflicts with Spec.DeploymentPlan.ZonePlacement", topologyKey)
+	}
+	for index, override := range customResource.Spec.DeploymentPlan.OrdinalOverrides {
+		if _, found := override.NodeSelector[topologyKey]; found {
+			return invalid("Spec.DeploymentPlan.OrdinalOverrides[%d].NodeSelector %s conflicts with Spec.DeploymentPlan.ZonePlacement", index, topologyKey)
+		}
+	}
+	return nil, false
+}
+
+// validateZonePairing checks the placement of the HA pair, the brokers are
+// placed apart from the zones of the same ordinals of the pair
+func validateZonePairing(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) (*metav1.Condition, bool) {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil || placement.HAPairOf == "" {
+		return nil, false
+	}
+	invalid := func(retry bool, format string, args ...interface{}) (*metav1.Condition, bool) {
+		return &metav1.Condition{
+			Type:    brokerv1beta1.ValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  brokerv1beta1.ValidConditionFailedInvalidZonePlacement,
+			Message: fmt.Sprintf(format, args...),
+		}, retry
+	}
+
+	pair := &brokerv1beta1.ActiveMQArtemis{}
+	if !retrieveResource(placement.HAPairOf, customResource.Namespace, pair, client) {
+		return invalid(true, "Spec.DeploymentPlan.ZonePlacement.HAPairOf %s is not an ActiveMQArtemis of namespace %s", placement.HAPairOf, customResource.Namespace)
+	}
+	pairPlacement := pair.Spec.DeploymentPlan.ZonePlacement
+	if pairPlacement == nil {
+		return invalid(false, "Spec.DeploymentPlan.ZonePlacement.HAPairOf %s has no Spec.DeploymentPlan.ZonePlacement, the zones of its brokers are unknown", pair.Name)
+	}
+	if pairPlacement.HAPairOf != "" {
+		return invalid(false, "Spec.DeploymentPlan.ZonePlacement.HAPairOf %s is itself the HA pair of %s, only one ActiveMQArtemis of a pair names the other", pair.Name, pairPlacement.HAPairOf)
+	}
+	if zoneTopologyKeyOf(pairPlacement) != zoneTopologyKeyOf(placement) {
+		return invalid(false, "Spec.DeploymentPlan.ZonePlacement.HAPairOf %s places its brokers by %s rather than %s", pair.Name, zoneTopologyKeyOf(pairPlacement), zoneTopologyKeyOf(placement))
+	}
+	return nil, false
+}
+
+// zonePairOf is the HA pair of the brokers, nil without one or when it can
+// not be read
+func zonePairOf(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) *brokerv1beta1.ActiveMQArtemis {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil || placement.HAPairOf == "" {
+		return nil
+	}
+	pair := &brokerv1beta1.ActiveMQArtemis{}
+	if !retrieveResource(placement.HAPairOf, customResource.Namespace, pair, client) {
+		return nil
+	}
+	return pair
+}
+
+// placementZoneOf is the zone of an ordinal. With an HA pair, it is the first
+// zone from that of the ordinal that is not the zone of the same ordinal of
+// the pair, whatever the zones of the pair and their order
+func placementZoneOf(customResource *brokerv1beta1.ActiveMQArtemis, pair *brokerv1beta1.ActiveMQArtemis, ordinal int32) string {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil || len(placement.Zones) == 0 {
+		return ""
+	}
+	start := int(ordinal) % len(placement.Zones)
+	if placement.HAPairOf == "" || pair == nil {
+		return placement.Zones[start]
+	}
+	pairZone := placementZoneOf(pair, nil, ordinal)
+	for offset := range placement.Zones {
+		if zone := placement.Zones[(start+offset)%len(placement.Zones)]; zone != pairZone {
+			return zone
+		}
+	}
+	return placement.Zones[start]
+}
+
+// applyZonePlacementToTemplate spreads the brokers, the pod webhook pins each
+// pod to the zone of its ordinal. The generated spread constraints balance the brokers
+// that are not pinned, like those of the broker groups, and spread the brokers
+// of a zone over its nodes.
+func applyZonePlacementToTemplate(customResource *brokerv1beta1.ActiveMQArtemis, namer common.Namers, template *corev1.PodTemplateSpec) {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil {
+		return
+	}
+
+	topologyKey := zoneTopologyKeyOf(placement)
+	brokers := &metav1.LabelSelector{MatchLabels: namer.LabelBuilder.Labels()}
+	constraints := append([]corev1.TopologySpreadConstraint(nil), template.Spec.TopologySpreadConstraints...)
+	for _, generated := range []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: topologyKey, WhenUnsatisfiable: corev1.DoNotSchedule, LabelSelector: brokers},
+		{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: brokers},
+	} {
+		// the constraints of the deployment plan take precedence
+		if !hasTopologySpreadConstraint(constraints, generated.TopologyKey) {
+			constraints = append(constraints, generated)
+		}
+	}
+	template.Spec.TopologySpreadConstraints = constraints
+
+	if placement.HAPairOf != "" {
+		affinity := &corev1.Affinity{}
+		if template.Spec.Affinity != nil {
+			affinity = template.Spec.Affinity.DeepCopy()
+		}
+		if affinity.PodAntiAffinity == nil {
+			affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{selectors.LabelResourceKey: placement.HAPairOf}},
+				TopologyKey:   topologyKey,
+			},
+		})
+		template.Spec.Affinity = affinity
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	serialized, _ := json.Marshal(placement)
+	digest := adler32.New()
+	digest.Write(serialized)
+	template.Annotations[zonePlacementChecksumAnnotation] = hex.EncodeToString(digest.Sum(nil))
+}
+
+// applyZonePlacementToPod pins a pod to the zone of its ordinal
+func applyZonePlacementToPod(customResource *brokerv1beta1.ActiveMQArtemis, pair *brokerv1beta1.ActiveMQArtemis, ordinal int32, pod *corev1.Pod) {
+	zone := placementZoneOf(customResource, pair, ordinal)
+	if zone == "" {
+		return
+	}
+	if pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+	pod.Spec.NodeSelector[zoneTopologyKeyOf(customResource.Spec.DeploymentPlan.ZonePlacement)] = zone
+}
+
+// ProcessZonePlacementStatus reports the zone of each scheduled broker and
+// warns when a broker is out of its placement zone or in the zone of its HA pair
+func (reconciler *ActiveMQArtemisReconcilerImpl) ProcessZonePlacementStatus(customResource *brokerv1beta1.ActiveMQArtemis, client rtclient.Client) {
+	placement := customResource.Spec.DeploymentPlan.ZonePlacement
+	if placement == nil {
+		customResource.Status.Zones = nil
+		return
+	}
+	topologyKey := zoneTopologyKeyOf(placement)
+	pair := zonePairOf(customResource, client)
+
+	previous := map[int32]string{}
+	for _, zoneStatus := range customResource.Status.Zones {
+		previous[zoneStatus.Ordinal] = zoneStatus.Message
+	}
+	var zones []brokerv1beta1.BrokerZoneStatus
+	for ordinal := int32(0); ordinal < common.GetDeploymentSize(customResource); ordinal++ {
+		zone, scheduled, err := podZoneOf(client, customResource.Namespace, namer.CrToSSOrdinal(customResource.Name, int(ordinal)), topologyKey)
+		zoneStatus := brokerv1beta1.BrokerZoneStatus{
+			Ordinal:       ordinal,
+			PlacementZone: placementZoneOf(customResource, pair, ordinal),
+			Zone:          zone,
+		}
+		if err != nil {
+			zoneStatus.Zone = unknownZone
+			zoneStatus.Message = fmt.Sprintf("zone unknown, %v", err)
+		} else if scheduled && zone == "" {
+			zoneStatus.Zone = unknownZone
+			zoneStatus.Message = fmt.Sprintf("zone unknown, the node of the pod has no %s label", topologyKey)
+		} else if zone != "" {
+			if zone != zoneStatus.PlacementZone {
+				zoneStatus.Message = fmt.Sprintf("is in zone %s instead of %s", zone, zoneStatus.PlacementZone)
+			} else if pair != nil {
+				pairPod := namer.CrToSSOrdinal(pair.Name, int(ordinal))
+				if pairZone, _, _ := podZoneOf(client, customResource.Namespace, pairPod, topologyKey); pairZone == zone {
+					zoneStatus.Message = fmt.Sprintf("is in zone %s with its HA pair %s", zone, pairPod)
+				}
+			}
+		}
+		if zone != "" && zoneStatus.Message != "" && zoneStatus.Message != previous[ordinal] {
+			recordEvent(reconciler.recorder, customResource, corev1.EventTypeWarning, EventReasonZoneCoLocated, MessageZoneCoLocated, ordinal, zoneStatus.Message)
+		}
+		zones = append(zones, zoneStatus)
+	}
+	customResource.Status.Zones = zones
+}
+
+// podZoneOf is the zone of a scheduled pod from the topology label of its node.
+// The nodes are cluster scoped, they are read with the optional node reader
+// ClusterRole of config/rbac and the zone is unknown without it
+func podZoneOf(client rtclient.Client, namespace string, podName string, topologyKey string) (zone string, scheduled bool, err error) {
+	pod := &corev1.Pod{}
+	if !retrieveResource(podName, namespace, pod, client) || pod.Spec.NodeName == "" {
+		return "", false, nil
+	}
+	node := &corev1.Node{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		if apierrors.IsForbidden(err) {
+			return "", true, fmt.Errorf("the operator can not read node %s, bind the node-reader-role ClusterRole to its service account", pod.Spec.NodeName)
+		}
+		return "", true, fmt.Errorf("node %s can not be read, %v", pod.Spec.NodeName, err)
+	}
+	return node.Labels[topologyKey], true, nil
+}
+
+func hasTopologySpreadConstraint(constraints []corev1.TopologySpreadConstraint, topologyKey string) bool {
+	for _, constraint := range constraints {
+		if constraint.TopologyKey == topologyKey {
+			return true
+		}
+	}
+	return false
+}
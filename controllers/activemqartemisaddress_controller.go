@@ -27,14 +27,17 @@ import (
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia"
 	jc "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/lsrcrs"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -51,8 +54,9 @@ var namespacedNameToAddressName = make(map[types.NamespacedName]AddressDeploymen
 // ActiveMQArtemisAddressReconciler reconciles a ActiveMQArtemisAddress object
 type ActiveMQArtemisAddressReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	log    logr.Logger
+	Scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
 func NewActiveMQArtemisAddressReconciler(client client.Client, scheme *runtime.Scheme, logger logr.Logger) *ActiveMQArtemisAddressReconciler {
@@ -88,7 +92,7 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 		if errors.IsNotFound(err) {
 			if lookupSucceeded {
 				if addressInstance.AddressResource.Spec.RemoveFromBrokerOnDelete {
-					err = r.deleteQueue(&addressInstance, request, r.Client)
+					err = r.deleteQueue(ctx, &addressInstance, request, r.Client)
 					if err == nil {
 						reqLogger.V(1).Info("Address and queue deleted")
 					} else {
@@ -131,8 +135,12 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 		}
 	}
 
-	err = r.createQueue(&addressDeployment, request, r.Client)
+	err = r.createQueue(ctx, &addressDeployment, request, r.Client)
 	if nil == err {
+		// the resync period reapplies the same address, it is only news when it changed
+		if !lookupSucceeded || addressInstance.AddressResource.ResourceVersion != instance.ResourceVersion {
+			recordEvent(r.recorder, instance, corev1.EventTypeNormal, EventReasonAddressCreated, MessageAddressCreated, instance.Spec.AddressName)
+		}
 		namespacedNameToAddressName[request.NamespacedName] = addressDeployment
 		crstr, merr := common.ToJson(instance)
 		if merr != nil {
@@ -141,6 +149,7 @@ func (r *ActiveMQArtemisAddressReconciler) Reconcile(ctx context.Context, reques
 		lsrcrs.StoreLastSuccessfulReconciledCR(instance, instance.Name, instance.Namespace, "address", crstr, "", instance.ResourceVersion, getAddressLabels(instance), r.Client, r.Scheme)
 	} else {
 		reqLogger.Error(err, "failed to create address resource, request will be requeued")
+		recordEvent(r.recorder, instance, corev1.EventTypeWarning, EventReasonAddressCreateFailed, MessageAddressCreateFailed, instance.Spec.AddressName, err)
 	}
 	if err != nil {
 		return ctrl.Result{}, err
@@ -190,20 +199,21 @@ func (r *ActiveMQArtemisAddressReconciler) createNameBuilders(instance *brokerv1
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ActiveMQArtemisAddressReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisAddress")
 	go r.setupAddressObserver(mgr, ctx)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&brokerv1beta1.ActiveMQArtemisAddress{}).
 		Owns(&corev1.Pod{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisAddress", tracing.InstrumentReconciler("ActiveMQArtemisAddress", r)))
 }
 
 // This method deals with creating queues and addresses.
-func (r *ActiveMQArtemisAddressReconciler) createQueue(instance *AddressDeployment, request ctrl.Request, client client.Client) error {
+func (r *ActiveMQArtemisAddressReconciler) createQueue(ctx context.Context, instance *AddressDeployment, request ctrl.Request, client client.Client) error {
 
 	r.log.V(1).Info("Creating ActiveMQArtemisAddress")
 
 	var err error = nil
-	artemisArray := r.getPodBrokers(instance, request, client)
+	artemisArray := r.getPodBrokers(ctx, instance, request, client)
 	if nil != artemisArray {
 		for _, a := range artemisArray {
 			if nil == a {
@@ -247,8 +257,14 @@ func createAddressResource(a *jc.JkInfo, addressRes *brokerv1beta1.ActiveMQArtem
 		}
 	} else {
 		log.V(1).Info("Queue name is not empty so create queue", "name", *addressRes.Spec.QueueName, "broker", a.IP)
-		//first make sure address exists
-		response, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
+		//first make sure address exists, with the routing type of the queue when it has none
+		addressRoutingType := "MULTICAST"
+		if addressRes.Spec.RoutingType != nil {
+			addressRoutingType = *addressRes.Spec.RoutingType
+		} else if addressRes.Spec.QueueConfiguration != nil && addressRes.Spec.QueueConfiguration.RoutingType != nil {
+			addressRoutingType = *addressRes.Spec.QueueConfiguration.RoutingType
+		}
+		response, err := a.Artemis.CreateAddress(addressRes.Spec.AddressName, addressRoutingType)
 		if nil != err && mgmt.GetCreationError(response) != mgmt.ADDRESS_ALREADY_EXISTS {
 			log.Error(err, "Error creating ActiveMQArtemisAddress", "address", addressRes.Spec.AddressName)
 			return err
@@ -330,7 +346,7 @@ func (ar *AddressRetry) safeDelete() {
 }
 
 // This method deals with deleting queues and addresses.
-func (r *ActiveMQArtemisAddressReconciler) deleteQueue(instance *AddressDeployment, request ctrl.Request, client client.Client) error {
+func (r *ActiveMQArtemisAddressReconciler) deleteQueue(ctx context.Context, instance *AddressDeployment, request ctrl.Request, client client.Client) error {
 
 	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 
@@ -344,7 +360,7 @@ func (r *ActiveMQArtemisAddressReconciler) deleteQueue(instance *AddressDeployme
 	reqLogger.V(1).Info("Deleting ActiveMQArtemisAddress for queue " + addressName + "/" + queueName)
 
 	var err error = nil
-	artemisArray := r.getPodBrokers(instance, request, client)
+	artemisArray := r.getPodBrokers(ctx, instance, request, client)
 	if nil != artemisArray {
 		addressRetry := NewAddressRetry(addressName, make([]*mgmt.Artemis, 0), r.log.WithName("retry"))
 		for _, a := range artemisArray {
@@ -382,14 +398,15 @@ func (r *ActiveMQArtemisAddressReconciler) deleteQueue(instance *AddressDeployme
 	return err
 }
 
-func (r *ActiveMQArtemisAddressReconciler) getPodBrokers(instance *AddressDeployment, request ctrl.Request, client client.Client) []*jc.JkInfo {
+func (r *ActiveMQArtemisAddressReconciler) getPodBrokers(ctx context.Context, instance *AddressDeployment, request ctrl.Request, client client.Client) []*jc.JkInfo {
 	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.V(2).Info("Getting Pod Brokers for address " + instance.AddressResource.Namespace + "/" + instance.AddressResource.Name)
 	targetCrNamespacedNames := createTargetCrNamespacedNames(request.Namespace, instance.AddressResource.Spec.ApplyToCrNames, reqLogger)
 	reqLogger.V(2).Info("target Cr names", "result", targetCrNamespacedNames)
-	ssInfos := ss.GetDeployedStatefulSetNames(client, request.Namespace, targetCrNamespacedNames)
+	ssInfos := withoutPausedAddressTargets(client, ss.GetDeployedStatefulSetNames(client, request.Namespace, targetCrNamespacedNames), reqLogger)
 
-	return jc.GetBrokers(request.NamespacedName, ssInfos, client)
+	contextOf := func() context.Context { return ctx }
+	return jc.Traced(contextOf, jc.GetBrokers(request.NamespacedName, ssInfos, client), tracing.CR(request.Namespace, request.Name)...)
 }
 
 func createTargetCrNamespacedNames(namespace string, targetCrNames []string, log logr.Logger) []types.NamespacedName {
@@ -0,0 +1,528 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// the snapshots are unstructured, the operator does not depend on the client of
+// the external snapshotter and runs on clusters without its CRDs
+var volumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+const (
+	backupNameLabel    = "arkmq.org/backup"
+	backupIDLabel      = "arkmq.org/backup-id"
+	backupOrdinalLabel = "arkmq.org/backup-ordinal"
+
+	// the id of a backup is the UTC time it was taken, it sorts like the time
+	backupIDLayout = "20060102T150405Z"
+
+	// the acceptors start again when a snapshot is not cut in time
+	quiesceTimeout = 5 * time.Minute
+	// there is no watch on the snapshots, the api may not be installed
+	backupPollPeriod = 10 * time.Second
+)
+
+// ActiveMQArtemisBackupReconciler reconciles a ActiveMQArtemisBackup object
+type ActiveMQArtemisBackupReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	log      logr.Logger
+	// the jolokia clients of the brokers of a CR, replaced by the unit tests
+	brokersOf func(cr *brokerv1beta1.ActiveMQArtemis, client client.Client) []*jolokia_client.JkInfo
+	now       func() time.Time
+}
+
+func NewActiveMQArtemisBackupReconciler(client client.Client, scheme *runtime.Scheme, logger logr.Logger) *ActiveMQArtemisBackupReconciler {
+	return &ActiveMQArtemisBackupReconciler{
+		Client:    client,
+		Scheme:    scheme,
+		log:       logger,
+		brokersOf: brokerJolokiaEndpoints,
+		now:       time.Now,
+	}
+}
+
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisbackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisbackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisbackups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,namespace=activemq-artemis-operator,resources=volumesnapshots,verbs=get;list;create;delete
+
+// Reconcile takes the backups that are due, follows their snapshots and
+// deletes the backups past the retention
+func (r *ActiveMQArtemisBackupReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name, "Reconciling", "ActiveMQArtemisBackup")
+
+	backup := &brokerv1beta1.ActiveMQArtemisBackup{}
+	if err := r.Client.Get(ctx, request.NamespacedName, backup); err != nil {
+		if errors.IsNotFound(err) {
+			// the snapshots are owned by the backup
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "unable to retrieve the backup")
+		return ctrl.Result{}, err
+	}
+
+	before := backup.Status.DeepCopy()
+	result, err := r.reconcileBackup(ctx, backup)
+
+	if !equality.Semantic.DeepEqual(before, &backup.Status) {
+		if updateErr := r.Client.Status().Update(ctx, backup); updateErr != nil {
+			reqLogger.Error(updateErr, "unable to update the backup status")
+			if err == nil {
+				err = updateErr
+			}
+		}
+	}
+	return result, err
+}
+
+func (r *ActiveMQArtemisBackupReconciler) reconcileBackup(ctx context.Context, backup *brokerv1beta1.ActiveMQArtemisBackup) (ctrl.Result, error) {
+	now := r.now()
+
+	schedule, err := validateBackup(backup)
+	if err != nil {
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupInvalidSpecReason, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	broker := &brokerv1beta1.ActiveMQArtemis{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: backup.Spec.BrokerName, Namespace: backup.Namespace}, broker); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupBrokerNotFoundReason, fmt.Sprintf("ActiveMQArtemis %s not found", backup.Spec.BrokerName))
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+	}
+	if !broker.Spec.DeploymentPlan.PersistenceEnabled {
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupNoPersistenceReason, fmt.Sprintf("ActiveMQArtemis %s has no journal volumes, persistenceEnabled is false", broker.Name))
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+	}
+
+	if err := r.refreshSnapshotStatus(ctx, backup); err != nil {
+		if meta.IsNoMatchError(err) {
+			setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupVolumeSnapshotApiReason, "the snapshot.storage.k8s.io/v1 api is not installed, "+err.Error())
+			return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	r.resumeWhenCut(backup, broker, now)
+
+	if backup.Status.QuiescedSince == nil && isBackupDue(backup, schedule, now) {
+		taken, err := r.takeBackup(ctx, backup, broker, now)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !taken {
+			// the condition tells why, the backup is retried
+			return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+		}
+		if err := r.refreshSnapshotStatus(ctx, backup); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.applyRetention(ctx, backup, now); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	backup.Status.NextBackupTime = nil
+	if schedule != nil {
+		backup.Status.NextBackupTime = &metav1.Time{Time: nextBackupTime(backup, schedule)}
+	}
+	updateBackupReadyCondition(backup)
+
+	// the snapshots and the quiesced brokers are followed by polling
+	if backup.Status.QuiescedSince != nil || !isLatestBackupSettled(backup) {
+		return ctrl.Result{RequeueAfter: backupPollPeriod}, nil
+	}
+	if backup.Status.NextBackupTime != nil {
+		return ctrl.Result{RequeueAfter: backup.Status.NextBackupTime.Sub(now)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+func validateBackup(backup *brokerv1beta1.ActiveMQArtemisBackup) (cron.Schedule, error) {
+	if backup.Spec.BrokerName == "" {
+		return nil, fmt.Errorf(".Spec.BrokerName is required")
+	}
+	if retention := backup.Spec.Retention; retention != nil {
+		if retention.MaxCount != nil && *retention.MaxCount < 1 {
+			return nil, fmt.Errorf(".Spec.Retention.MaxCount %d must keep at least one backup", *retention.MaxCount)
+		}
+		if retention.MaxAge != nil && retention.MaxAge.Duration <= 0 {
+			return nil, fmt.Errorf(".Spec.Retention.MaxAge %q must be positive", retention.MaxAge.Duration)
+		}
+	}
+	if backup.Spec.Schedule == "" {
+		return nil, nil
+	}
+	schedule, err := cron.ParseStandard(backup.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf(".Spec.Schedule %q is invalid, %v", backup.Spec.Schedule, err)
+	}
+	return schedule, nil
+}
+
+// a backup without a schedule is taken once, a scheduled backup is due at the
+// first time of the schedule after the last backup or the creation of the CR
+func isBackupDue(backup *brokerv1beta1.ActiveMQArtemisBackup, schedule cron.Schedule, now time.Time) bool {
+	if schedule == nil {
+		return backup.Status.LastBackupTime == nil
+	}
+	return !nextBackupTime(backup, schedule).After(now)
+}
+
+func nextBackupTime(backup *brokerv1beta1.ActiveMQArtemisBackup, schedule cron.Schedule) time.Time {
+	last := backup.CreationTimestamp.Time
+	if backup.Status.LastBackupTime != nil {
+		last = backup.Status.LastBackupTime.Time
+	}
+	return schedule.Next(last.UTC())
+}
+
+// takeBackup snapshots the journal claim of each ordinal, those of scaled down
+// ordinals included, after stopping the acceptors when the backup quiesces
+func (r *ActiveMQArtemisBackupReconciler) takeBackup(ctx context.Context, backup *brokerv1beta1.ActiveMQArtemisBackup, broker *brokerv1beta1.ActiveMQArtemis, now time.Time) (bool, error) {
+	claims := claimsOf(r.Client, broker.Namespace, broker.Name, namer.CrToSS(broker.Name))
+	if len(claims) == 0 {
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupSnapshotsPendingReason, fmt.Sprintf("no journal volume claim of %s to snapshot yet", broker.Name))
+		return false, nil
+	}
+
+	if backup.Spec.Quiesce {
+		if err := r.quiesce(broker); err != nil {
+			setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupQuiesceFailedReason, err.Error())
+			recordEvent(r.recorder, backup, corev1.EventTypeWarning, EventReasonBackupFailed, "%s", err.Error())
+			return false, nil
+		}
+		backup.Status.QuiescedSince = &metav1.Time{Time: now}
+	}
+
+	id := now.UTC().Format(backupIDLayout)
+	for _, claim := range claims {
+		ordinal := strings.TrimPrefix(claim.Name, broker.Name+"-"+namer.CrToSS(broker.Name)+"-")
+		snapshot := newVolumeSnapshot(backup, id, ordinal, claim.Name)
+		if err := controllerutil.SetControllerReference(backup, snapshot, r.Scheme); err != nil {
+			return false, err
+		}
+		if err := r.Client.Create(ctx, snapshot); err != nil && !errors.IsAlreadyExists(err) {
+			recordEvent(r.recorder, backup, corev1.EventTypeWarning, EventReasonBackupFailed, "unable to create volume snapshot %s, %v", snapshot.GetName(), err)
+			return false, err
+		}
+	}
+	backup.Status.LastBackupTime = &metav1.Time{Time: now}
+	recordEvent(r.recorder, backup, corev1.EventTypeNormal, EventReasonBackupStarted, MessageBackupStarted, id, len(claims), broker.Name)
+	return true, nil
+}
+
+func newVolumeSnapshot(backup *brokerv1beta1.ActiveMQArtemisBackup, id string, ordinal string, claimName string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(backup.Name + "-" + strings.ToLower(id) + "-" + ordinal)
+	snapshot.SetNamespace(backup.Namespace)
+	snapshot.SetLabels(map[string]string{
+		backupNameLabel:    backup.Name,
+		backupIDLabel:      id,
+		backupOrdinalLabel: ordinal,
+	})
+	unstructured.SetNestedField(snapshot.Object, claimName, "spec", "source", "persistentVolumeClaimName")
+	if backup.Spec.VolumeSnapshotClassName != "" {
+		unstructured.SetNestedField(snapshot.Object, backup.Spec.VolumeSnapshotClassName, "spec", "volumeSnapshotClassName")
+	}
+	return snapshot
+}
+
+// quiesce stops the acceptors of the spec on every broker, the acceptors of a
+// broker that fails are started again
+func (r *ActiveMQArtemisBackupReconciler) quiesce(broker *brokerv1beta1.ActiveMQArtemis) error {
+	stopped := []*jolokia_client.JkInfo{}
+	for _, jk := range r.backedUpBrokersOf(broker) {
+		for _, acceptor := range broker.Spec.Acceptors {
+			if _, err := jk.Artemis.StopAcceptor(acceptor.Name); err != nil {
+				r.startAcceptors(broker, append(stopped, jk))
+				return fmt.Errorf("unable to quiesce broker %s, %v", jk.Ordinal, err)
+			}
+		}
+		stopped = append(stopped, jk)
+	}
+	return nil
+}
+
+// backedUpBrokersOf are the brokers of the deployment plan, the journals of
+// the brokers of the groups are not backed up and they keep their acceptors
+func (r *ActiveMQArtemisBackupReconciler) backedUpBrokersOf(broker *brokerv1beta1.ActiveMQArtemis) []*jolokia_client.JkInfo {
+	var brokers []*jolokia_client.JkInfo
+	for _, jk := range r.brokersOf(broker, r.Client) {
+		if _, err := strconv.Atoi(jk.Ordinal); err == nil {
+			brokers = append(brokers, jk)
+		}
+	}
+	return brokers
+}
+
+func (r *ActiveMQArtemisBackupReconciler) startAcceptors(broker *brokerv1beta1.ActiveMQArtemis, brokers []*jolokia_client.JkInfo) bool {
+	started := true
+	for _, jk := range brokers {
+		for _, acceptor := range broker.Spec.Acceptors {
+			if _, err := jk.Artemis.StartAcceptor(acceptor.Name); err != nil {
+				r.log.Error(err, "unable to start the acceptor after the backup", "acceptor", acceptor.Name, "ordinal", jk.Ordinal)
+				started = false
+			}
+		}
+	}
+	return started
+}
+
+// resumeWhenCut starts the acceptors once the snapshots of the last backup are
+// cut, or failed, or the timeout passed
+func (r *ActiveMQArtemisBackupReconciler) resumeWhenCut(backup *brokerv1beta1.ActiveMQArtemisBackup, broker *brokerv1beta1.ActiveMQArtemis, now time.Time) {
+	if backup.Status.QuiescedSince == nil {
+		return
+	}
+	cut := true
+	for _, snapshot := range snapshotsOfBackup(backup, latestBackupID(backup)) {
+		cut = cut && (snapshot.Cut || snapshot.Error != "")
+	}
+	if !cut && now.Sub(backup.Status.QuiescedSince.Time) < quiesceTimeout {
+		return
+	}
+	if r.startAcceptors(broker, r.backedUpBrokersOf(broker)) {
+		backup.Status.QuiescedSince = nil
+	}
+}
+
+// refreshSnapshotStatus reports the snapshots of the backup from the cluster
+func (r *ActiveMQArtemisBackupReconciler) refreshSnapshotStatus(ctx context.Context, backup *brokerv1beta1.ActiveMQArtemisBackup) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+	if err := r.Client.List(ctx, list, client.InNamespace(backup.Namespace), client.MatchingLabels{backupNameLabel: backup.Name}); err != nil {
+		return err
+	}
+
+	var snapshots []brokerv1beta1.BackupSnapshotStatus
+	for _, item := range list.Items {
+		snapshots = append(snapshots, snapshotStatusOf(&item))
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].BackupID != snapshots[j].BackupID {
+			return snapshots[i].BackupID > snapshots[j].BackupID
+		}
+		return snapshots[i].Ordinal < snapshots[j].Ordinal
+	})
+	backup.Status.Snapshots = snapshots
+	return nil
+}
+
+func snapshotStatusOf(snapshot *unstructured.Unstructured) brokerv1beta1.BackupSnapshotStatus {
+	ordinal, _ := strconv.Atoi(snapshot.GetLabels()[backupOrdinalLabel])
+	claimName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	creationTime, _, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime")
+	readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	errorMessage, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+
+	status := brokerv1beta1.BackupSnapshotStatus{
+		Name:       snapshot.GetName(),
+		BackupID:   snapshot.GetLabels()[backupIDLabel],
+		Ordinal:    int32(ordinal),
+		ClaimName:  claimName,
+		Cut:        creationTime != "" || readyToUse,
+		ReadyToUse: readyToUse,
+		Error:      errorMessage,
+	}
+	// a quantity, the json of the status may hold it as a string or a number
+	if restoreSize, found, _ := unstructured.NestedFieldNoCopy(snapshot.Object, "status", "restoreSize"); found && restoreSize != nil {
+		status.RestoreSize = fmt.Sprint(restoreSize)
+	}
+	return status
+}
+
+// applyRetention deletes the snapshots of the backups past the max count or the
+// max age, nothing is deleted while the latest backup is in progress and the
+// latest backup that can be restored is kept
+func (r *ActiveMQArtemisBackupReconciler) applyRetention(ctx context.Context, backup *brokerv1beta1.ActiveMQArtemisBackup, now time.Time) error {
+	retention := backup.Spec.Retention
+	if retention == nil || backup.Status.QuiescedSince != nil || !isLatestBackupSettled(backup) {
+		return nil
+	}
+	ids := backupIDsOf(backup)
+	expired := map[string]bool{}
+	for index, id := range ids {
+		if retention.MaxCount != nil && index >= int(*retention.MaxCount) {
+			expired[id] = true
+		}
+		if takenAt, err := time.Parse(backupIDLayout, id); err == nil && retention.MaxAge != nil && now.Sub(takenAt) > retention.MaxAge.Duration {
+			expired[id] = true
+		}
+	}
+	// a failed backup does not take the place of the last good one
+	for _, id := range ids {
+		if isRestorable(snapshotsOfBackup(backup, id)) {
+			delete(expired, id)
+			break
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	var kept []brokerv1beta1.BackupSnapshotStatus
+	for _, id := range ids {
+		if !expired[id] {
+			kept = append(kept, snapshotsOfBackup(backup, id)...)
+			continue
+		}
+		recordEvent(r.recorder, backup, corev1.EventTypeNormal, EventReasonBackupDeleted, MessageBackupDeleted, id)
+		for _, status := range snapshotsOfBackup(backup, id) {
+			snapshot := &unstructured.Unstructured{}
+			snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+			snapshot.SetName(status.Name)
+			snapshot.SetNamespace(backup.Namespace)
+			if err := r.Client.Delete(ctx, snapshot); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	backup.Status.Snapshots = kept
+	return nil
+}
+
+// the ids of the backups in the status, the latest first
+func backupIDsOf(backup *brokerv1beta1.ActiveMQArtemisBackup) []string {
+	var ids []string
+	for _, snapshot := range backup.Status.Snapshots {
+		if len(ids) == 0 || ids[len(ids)-1] != snapshot.BackupID {
+			ids = append(ids, snapshot.BackupID)
+		}
+	}
+	return ids
+}
+
+func latestBackupID(backup *brokerv1beta1.ActiveMQArtemisBackup) string {
+	if ids := backupIDsOf(backup); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+func snapshotsOfBackup(backup *brokerv1beta1.ActiveMQArtemisBackup, id string) []brokerv1beta1.BackupSnapshotStatus {
+	var snapshots []brokerv1beta1.BackupSnapshotStatus
+	for _, snapshot := range backup.Status.Snapshots {
+		if snapshot.BackupID == id {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots
+}
+
+// every snapshot of the latest backup is ready or failed
+func isLatestBackupSettled(backup *brokerv1beta1.ActiveMQArtemisBackup) bool {
+	for _, snapshot := range snapshotsOfBackup(backup, latestBackupID(backup)) {
+		if !snapshot.ReadyToUse && snapshot.Error == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isRestorable is true for a backup with every snapshot ready to use
+func isRestorable(snapshots []brokerv1beta1.BackupSnapshotStatus) bool {
+	for _, snapshot := range snapshots {
+		if !snapshot.ReadyToUse {
+			return false
+		}
+	}
+	return len(snapshots) > 0
+}
+
+// the Ready condition reports the latest backup
+func updateBackupReadyCondition(backup *brokerv1beta1.ActiveMQArtemisBackup) {
+	id := latestBackupID(backup)
+	if id == "" {
+		message := "no backup taken yet"
+		if backup.Status.NextBackupTime != nil {
+			message = fmt.Sprintf("no backup taken yet, the first backup is due at %s", backup.Status.NextBackupTime.Format(time.RFC3339))
+		}
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupSnapshotsPendingReason, message)
+		return
+	}
+
+	var failed, pending []string
+	for _, snapshot := range snapshotsOfBackup(backup, id) {
+		if snapshot.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", snapshot.Name, snapshot.Error))
+		} else if !snapshot.ReadyToUse {
+			pending = append(pending, snapshot.Name)
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupSnapshotFailedReason, fmt.Sprintf("backup %s failed, %s", id, strings.Join(failed, ", ")))
+	case len(pending) > 0:
+		setBackupReady(backup, metav1.ConditionFalse, brokerv1beta1.BackupSnapshotsPendingReason, fmt.Sprintf("backup %s waits for the snapshots %s", id, strings.Join(pending, ", ")))
+	default:
+		setBackupReady(backup, metav1.ConditionTrue, brokerv1beta1.BackupSnapshotsReadyReason, fmt.Sprintf("backup %s is ready to restore", id))
+	}
+}
+
+func setBackupReady(backup *brokerv1beta1.ActiveMQArtemisBackup, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&backup.Status.Conditions, metav1.Condition{
+		Type:               brokerv1beta1.ReadyConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: backup.Generation,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ActiveMQArtemisBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisBackup")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisBackup{}).
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisBackup", tracing.InstrumentReconciler("ActiveMQArtemisBackup", r)))
+}
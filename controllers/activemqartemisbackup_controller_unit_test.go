@@ -0,0 +1,221 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// +kubebuilder:docs-gen:collapse=Apache License
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	artemis_client "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newBackupTestScheme(t *testing.T) *runtime.Scheme {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+	testScheme.AddKnownTypeWithName(volumeSnapshotGVK, &unstructured.Unstructured{})
+	testScheme.AddKnownTypeWithName(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind+"List"), &unstructured.UnstructuredList{})
+	return testScheme
+}
+
+func listVolumeSnapshots(t *testing.T, fakeClient client.Client) []unstructured.Unstructured {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+	assert.NoError(t, fakeClient.List(context.TODO(), list))
+	return list.Items
+}
+
+// the snapshot controller cuts the snapshots and makes them ready
+func markSnapshotsReady(t *testing.T, fakeClient client.Client) {
+	for _, item := range listVolumeSnapshots(t, fakeClient) {
+		snapshot := item.DeepCopy()
+		assert.NoError(t, unstructured.SetNestedField(snapshot.Object, "2026-10-19T02:00:01Z", "status", "creationTime"))
+		assert.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+		assert.NoError(t, unstructured.SetNestedField(snapshot.Object, "1Gi", "status", "restoreSize"))
+		assert.NoError(t, fakeClient.Update(context.TODO(), snapshot))
+	}
+}
+
+func TestBackupReconcile(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	testScheme := newBackupTestScheme(t)
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{PersistenceEnabled: true},
+			Acceptors:      []brokerv1beta1.AcceptorType{{Name: "amqp"}},
+		},
+	}
+	claim := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+	maxCount := int32(1)
+	backup := &brokerv1beta1.ActiveMQArtemisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "test", UID: "nightly-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisBackupSpec{
+			BrokerName:              "ex",
+			VolumeSnapshotClassName: "csi-snapclass",
+			Quiesce:                 true,
+			Retention:               &brokerv1beta1.BackupRetentionType{MaxCount: &maxCount},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, backup, claim("ex-ex-ss-0"), claim("ex-ex-ss-1"), claim("other-ex-ss-0")).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisBackup{}).Build()
+
+	j := jolokia.NewMockIJolokia(mockCtrl)
+	acceptorMBean := "org.apache.activemq.artemis:broker=\"a\",component=acceptors,name=\"amqp\""
+	stop := j.EXPECT().Exec(acceptorMBean, gomock.Any()).DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+		assert.Contains(t, body, "stop()")
+		return &jolokia.ResponseData{Status: 200}, nil
+	}).Times(1)
+	j.EXPECT().Exec(acceptorMBean, gomock.Any()).DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+		assert.Contains(t, body, "start()")
+		return &jolokia.ResponseData{Status: 200}, nil
+	}).Times(1).After(stop)
+
+	now := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+	r := NewActiveMQArtemisBackupReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestBackupReconcile"))
+	r.now = func() time.Time { return now }
+	r.brokersOf = func(cr *brokerv1beta1.ActiveMQArtemis, _ client.Client) []*jolokia_client.JkInfo {
+		return []*jolokia_client.JkInfo{{Artemis: artemis_client.GetArtemisWithJolokia(j, "a"), Ordinal: "0"}}
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "test"}}
+	current := func() *brokerv1beta1.ActiveMQArtemisBackup {
+		current := &brokerv1beta1.ActiveMQArtemisBackup{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+		return current
+	}
+
+	// the brokers are quiesced and each journal claim is snapshot
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, backupPollPeriod, result.RequeueAfter)
+
+	snapshots := listVolumeSnapshots(t, fakeClient)
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, "nightly-20261019t020000z-0", snapshots[0].GetName())
+	assert.Equal(t, "20261019T020000Z", snapshots[0].GetLabels()[backupIDLabel])
+	assert.Equal(t, "nightly", snapshots[0].GetOwnerReferences()[0].Name)
+	source, _, _ := unstructured.NestedString(snapshots[0].Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "ex-ex-ss-0", source)
+	class, _, _ := unstructured.NestedString(snapshots[1].Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapclass", class)
+
+	status := current().Status
+	assert.NotNil(t, status.QuiescedSince)
+	assert.Equal(t, now, status.LastBackupTime.UTC())
+	assert.Len(t, status.Snapshots, 2)
+	assert.Equal(t, "ex-ex-ss-1", status.Snapshots[1].ClaimName)
+	ready := meta.FindStatusCondition(status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, brokerv1beta1.BackupSnapshotsPendingReason, ready.Reason)
+
+	// once cut the acceptors start again and a single backup is done
+	markSnapshotsReady(t, fakeClient)
+	now = now.Add(time.Minute)
+	result, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	status = current().Status
+	assert.Nil(t, status.QuiescedSince)
+	assert.Equal(t, "1Gi", status.Snapshots[0].RestoreSize)
+	assert.True(t, status.Snapshots[0].Cut)
+	ready = meta.FindStatusCondition(status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, brokerv1beta1.BackupSnapshotsReadyReason, ready.Reason)
+
+	// a scheduled backup replaces the previous one once its snapshots are ready
+	scheduled := current()
+	scheduled.Spec.Schedule = "0 * * * *"
+	scheduled.Spec.Quiesce = false
+	assert.NoError(t, fakeClient.Update(context.TODO(), scheduled))
+	now = time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)
+
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Len(t, listVolumeSnapshots(t, fakeClient), 4)
+	assert.Equal(t, []string{"20261019T030000Z", "20261019T020000Z"}, backupIDsOf(current()))
+
+	markSnapshotsReady(t, fakeClient)
+	result, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Len(t, listVolumeSnapshots(t, fakeClient), 2)
+	status = current().Status
+	assert.Equal(t, []string{"20261019T030000Z"}, backupIDsOf(current()))
+	assert.Equal(t, time.Date(2026, 10, 19, 4, 0, 0, 0, time.UTC), status.NextBackupTime.UTC())
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+}
+
+func TestBackupReconcileInvalid(t *testing.T) {
+	testScheme := newBackupTestScheme(t)
+	maxCount := int32(0)
+	backup := &brokerv1beta1.ActiveMQArtemisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "test"},
+		Spec:       brokerv1beta1.ActiveMQArtemisBackupSpec{BrokerName: "missing", Schedule: "daily"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(backup).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisBackup{}).Build()
+	r := NewActiveMQArtemisBackupReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestBackupReconcileInvalid"))
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "b", Namespace: "test"}}
+
+	readyOf := func() *metav1.Condition {
+		current := &brokerv1beta1.ActiveMQArtemisBackup{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+		return meta.FindStatusCondition(current.Status.Conditions, brokerv1beta1.ReadyConditionType)
+	}
+
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, brokerv1beta1.BackupInvalidSpecReason, readyOf().Reason)
+	assert.Contains(t, readyOf().Message, ".Spec.Schedule")
+
+	current := &brokerv1beta1.ActiveMQArtemisBackup{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+	current.Spec.Schedule = ""
+	current.Spec.Retention = &brokerv1beta1.BackupRetentionType{MaxCount: &maxCount}
+	assert.NoError(t, fakeClient.Update(context.TODO(), current))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Contains(t, readyOf().Message, ".Spec.Retention.MaxCount")
+
+	current = &brokerv1beta1.ActiveMQArtemisBackup{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+	current.Spec.Retention = nil
+	assert.NoError(t, fakeClient.Update(context.TODO(), current))
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, brokerv1beta1.BackupBrokerNotFoundReason, readyOf().Reason)
+	assert.NotZero(t, result.RequeueAfter)
+}
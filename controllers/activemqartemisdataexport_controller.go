@@ -0,0 +1,474 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	dataExportLabel     = "arkmq.org/data-export"
+	dataExportMountPath = "/export"
+	artemisCli          = "/opt/amq/bin/artemis"
+
+	// the queues of the messages of the file, counted into the termination
+	// message of the container, the status reports them from there
+	countQueueMessagesScript = `grep -o '<queue name="[^"]*"' "$FILE" | sed 's/<queue name="//;s/"$//' | sort | uniq -c | ` +
+		`while read count queue; do echo "$queue=$count"; done | head -c 4000 > /dev/termination-log`
+)
+
+// ActiveMQArtemisDataExportReconciler reconciles a ActiveMQArtemisDataExport object
+type ActiveMQArtemisDataExportReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	log      logr.Logger
+}
+
+func NewActiveMQArtemisDataExportReconciler(client client.Client, scheme *runtime.Scheme, logger logr.Logger) *ActiveMQArtemisDataExportReconciler {
+	return &ActiveMQArtemisDataExportReconciler{
+		Client: client,
+		Scheme: scheme,
+		log:    logger,
+	}
+}
+
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdataexports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdataexports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdataexports/finalizers,verbs=update
+//+kubebuilder:rbac:groups=batch,namespace=activemq-artemis-operator,resources=jobs,verbs=get;list;watch;create;update;delete
+
+// Reconcile runs the job of the artemis data tool once the broker is in the
+// state the action needs and reports the job
+func (r *ActiveMQArtemisDataExportReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name, "Reconciling", "ActiveMQArtemisDataExport")
+
+	export := &brokerv1beta1.ActiveMQArtemisDataExport{}
+	if err := r.Client.Get(ctx, request.NamespacedName, export); err != nil {
+		if errors.IsNotFound(err) {
+			// the job is owned by the export
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "unable to retrieve the data export")
+		return ctrl.Result{}, err
+	}
+
+	before := export.Status.DeepCopy()
+	result, err := r.reconcileDataExport(ctx, export)
+
+	if !equality.Semantic.DeepEqual(before, &export.Status) {
+		if updateErr := r.Client.Status().Update(ctx, export); updateErr != nil {
+			reqLogger.Error(updateErr, "unable to update the data export status")
+			if err == nil {
+				err = updateErr
+			}
+		}
+	}
+	return result, err
+}
+
+func (r *ActiveMQArtemisDataExportReconciler) reconcileDataExport(ctx context.Context, export *brokerv1beta1.ActiveMQArtemisDataExport) (ctrl.Result, error) {
+	// the job is immutable, a change of the spec needs a new CR
+	job := &batchv1.Job{}
+	created := true
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: dataExportJobName(export), Namespace: export.Namespace}, job); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		created = false
+	}
+	if created && !isJobSuspended(job) {
+		return ctrl.Result{}, r.updateDataExportStatus(ctx, export, job)
+	}
+
+	broker := &brokerv1beta1.ActiveMQArtemis{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: export.Spec.BrokerName, Namespace: export.Namespace}, broker); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		setDataExportReady(export, metav1.ConditionFalse, brokerv1beta1.DataExportBrokerNotFoundReason, fmt.Sprintf("ActiveMQArtemis %s not found", export.Spec.BrokerName))
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+	}
+	if err := validateDataExport(export, broker); err != nil {
+		setDataExportReady(export, metav1.ConditionFalse, brokerv1beta1.DataExportInvalidSpecReason, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// the job is created suspended and only started once the broker checks
+	// pass, they are done again on each reconcile until then
+	if !created {
+		job = newDataExportJob(export, broker)
+		if err := controllerutil.SetControllerReference(export, job, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Client.Create(ctx, job); err != nil {
+			return ctrl.Result{}, err
+		}
+		export.Status.JobName = job.Name
+	}
+
+	podName := namer.CrToSSOrdinal(broker.Name, int(export.Spec.Ordinal))
+	if reason, message := r.dataExportBlocker(export, broker, podName); reason != "" {
+		setDataExportReady(export, metav1.ConditionFalse, reason, message)
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+	}
+
+	job.Spec.Suspend = nil
+	if err := r.Client.Update(ctx, job); err != nil {
+		return ctrl.Result{}, err
+	}
+	recordEvent(r.recorder, export, corev1.EventTypeNormal, EventReasonDataExportStarted, MessageDataExportStarted, job.Name, strings.ToLower(string(dataExportActionOf(export))), podName)
+	setDataExportReady(export, metav1.ConditionFalse, brokerv1beta1.DataExportPendingReason, fmt.Sprintf("job %s is started", job.Name))
+	return ctrl.Result{}, nil
+}
+
+// dataExportBlocker tells why the job can not start yet, an export needs the
+// ordinal to be scaled down so that the StatefulSet does not bring its pod
+// back while the job reads the journal volume
+func (r *ActiveMQArtemisDataExportReconciler) dataExportBlocker(export *brokerv1beta1.ActiveMQArtemisDataExport, broker *brokerv1beta1.ActiveMQArtemis, podName string) (string, string) {
+	pod := &corev1.Pod{}
+	running := retrieveResource(podName, export.Namespace, pod, r.Client)
+
+	if dataExportActionOf(export) == brokerv1beta1.DataExportActionImport {
+		if !running || !isPodReady(pod) {
+			return brokerv1beta1.DataExportBrokerNotReadyReason, fmt.Sprintf("waiting for broker pod %s to be ready", podName)
+		}
+		return "", ""
+	}
+
+	// the operator brings the StatefulSet to the size of the CR
+	replicas := common.GetDeploymentSize(broker)
+	statefulSet := &appsv1.StatefulSet{}
+	if retrieveResource(namer.CrToSS(broker.Name), export.Namespace, statefulSet, r.Client) && statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas > replicas {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	if export.Spec.Ordinal < replicas {
+		return brokerv1beta1.DataExportOrdinalNotScaledDownReason, fmt.Sprintf("waiting for ordinal %d to be scaled down, the size of %s is %d, the journal of a running broker can not be exported", export.Spec.Ordinal, broker.Name, replicas)
+	}
+	// the journal volume of a running broker is locked by the broker
+	if running {
+		return brokerv1beta1.DataExportBrokerRunningReason, fmt.Sprintf("waiting for broker pod %s to stop, the journal of a running broker can not be exported", podName)
+	}
+	return "", ""
+}
+
+func isJobSuspended(job *batchv1.Job) bool {
+	return job.Spec.Suspend != nil && *job.Spec.Suspend
+}
+
+func validateDataExport(export *brokerv1beta1.ActiveMQArtemisDataExport, broker *brokerv1beta1.ActiveMQArtemis) error {
+	if export.Spec.Target.ClaimName == "" {
+		return fmt.Errorf(".Spec.Target.ClaimName is required")
+	}
+	if file := dataExportFile(export); path.IsAbs(file) || strings.HasPrefix(path.Clean(file), "..") {
+		return fmt.Errorf(".Spec.Target.File %q must be a relative path in the volume", file)
+	}
+	if export.Spec.Ordinal < 0 {
+		return fmt.Errorf(".Spec.Ordinal %d must not be negative", export.Spec.Ordinal)
+	}
+	switch dataExportActionOf(export) {
+	case brokerv1beta1.DataExportActionExport:
+		if !broker.Spec.DeploymentPlan.PersistenceEnabled {
+			return fmt.Errorf("ActiveMQArtemis %s has no journal volume to export, persistenceEnabled is false", broker.Name)
+		}
+	case brokerv1beta1.DataExportActionImport:
+		if _, err := importAcceptorPort(export, broker); err != nil {
+			return err
+		}
+		// the artemis command line does not decode a masked admin password
+		if broker.Spec.PasswordCodec != nil {
+			return fmt.Errorf("ActiveMQArtemis %s masks its admin password with passwordCodec, it can not be used by an import", broker.Name)
+		}
+	default:
+		return fmt.Errorf(".Spec.Action %q must be Export or Import", export.Spec.Action)
+	}
+	return nil
+}
+
+func dataExportActionOf(export *brokerv1beta1.ActiveMQArtemisDataExport) brokerv1beta1.DataExportAction {
+	if export.Spec.Action == "" {
+		return brokerv1beta1.DataExportActionExport
+	}
+	return export.Spec.Action
+}
+
+func dataExportJobName(export *brokerv1beta1.ActiveMQArtemisDataExport) string {
+	return export.Name + "-" + strings.ToLower(string(dataExportActionOf(export)))
+}
+
+func dataExportFile(export *brokerv1beta1.ActiveMQArtemisDataExport) string {
+	if export.Spec.Target.File != "" {
+		return export.Spec.Target.File
+	}
+	return export.Name + ".xml"
+}
+
+// importAcceptorPort is the port of the acceptor an import connects to, the
+// ports of the acceptors without one are assigned like the broker config does
+func importAcceptorPort(export *brokerv1beta1.ActiveMQArtemisDataExport, broker *brokerv1beta1.ActiveMQArtemis) (int32, error) {
+	if export.Spec.Acceptor == "" {
+		// the core acceptor of the cluster
+		return 61616, nil
+	}
+	var nextPort int32 = 61626
+	for _, acceptor := range broker.Spec.Acceptors {
+		port := acceptor.Port
+		if port == 0 {
+			port = nextPort
+			nextPort += 10
+		}
+		if acceptor.Name != export.Spec.Acceptor {
+			continue
+		}
+		if acceptor.SSLEnabled {
+			return 0, fmt.Errorf(".Spec.Acceptor %s has ssl enabled, an import needs a plain acceptor", acceptor.Name)
+		}
+		return port, nil
+	}
+	return 0, fmt.Errorf(".Spec.Acceptor %s is not an acceptor of ActiveMQArtemis %s", export.Spec.Acceptor, broker.Name)
+}
+
+func newDataExportJob(export *brokerv1beta1.ActiveMQArtemisDataExport, broker *brokerv1beta1.ActiveMQArtemis) *batchv1.Job {
+	namers := MakeNamers(broker)
+	labels := map[string]string{dataExportLabel: export.Name}
+	env := []corev1.EnvVar{{Name: "FILE", Value: path.Join(dataExportMountPath, dataExportFile(export))}}
+	volumes := []corev1.Volume{{
+		Name:         "target",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: export.Spec.Target.ClaimName}},
+	}}
+	mounts := []corev1.VolumeMount{{Name: "target", MountPath: dataExportMountPath}}
+
+	var script string
+	if dataExportActionOf(export) == brokerv1beta1.DataExportActionExport {
+		journal, bindings, paging, largeMessages := journalDirectories(broker, *namers)
+		env = append(env,
+			corev1.EnvVar{Name: "JOURNAL_DIR", Value: journal},
+			corev1.EnvVar{Name: "BINDINGS_DIR", Value: bindings},
+			corev1.EnvVar{Name: "PAGING_DIR", Value: paging},
+			corev1.EnvVar{Name: "LARGE_MESSAGES_DIR", Value: largeMessages})
+		volumes = append(volumes, corev1.Volume{
+			Name: "journal",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: namer.CrToJournalClaim(broker.Name, int(export.Spec.Ordinal)),
+				ReadOnly:  true,
+			}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "journal", MountPath: getDataMountPath(broker, *namers), ReadOnly: true})
+		script = `set -e; mkdir -p "$(dirname "$FILE")"; ` +
+			artemisCli + ` data exp --journal "$JOURNAL_DIR" --bindings "$BINDINGS_DIR" --paging "$PAGING_DIR" --large-messages "$LARGE_MESSAGES_DIR" > "$FILE.part"; ` +
+			`mv "$FILE.part" "$FILE"; ` + countQueueMessagesScript
+	} else {
+		port, _ := importAcceptorPort(export, broker)
+		host := fmt.Sprintf("%s.%s.%s.svc", namer.CrToSSOrdinal(broker.Name, int(export.Spec.Ordinal)), namers.SvcHeadlessNameBuilder.Name(), broker.Namespace)
+		credentials := namers.SecretsCredentialsNameBuilder.Name()
+		env = append(env,
+			corev1.EnvVar{Name: "BROKER_HOST", Value: host},
+			corev1.EnvVar{Name: "BROKER_PORT", Value: strconv.Itoa(int(port))},
+			corev1.EnvVar{Name: "AMQ_USER", ValueFrom: secretKeyRef(credentials, "AMQ_USER")},
+			corev1.EnvVar{Name: "AMQ_PASSWORD", ValueFrom: secretKeyRef(credentials, "AMQ_PASSWORD")})
+		script = `set -e; ` + countQueueMessagesScript + `; ` +
+			artemisCli + ` data imp --host "$BROKER_HOST" --port "$BROKER_PORT" --user "$AMQ_USER" --password "$AMQ_PASSWORD" --input "$FILE"`
+	}
+
+	var backoffLimit int32 = 0
+	suspend := true
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dataExportJobName(export),
+			Namespace: export.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// an import that fails part way must not send the messages again
+			BackoffLimit: &backoffLimit,
+			Suspend:      &suspend,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					ImagePullSecrets: broker.Spec.DeploymentPlan.ImagePullSecrets,
+					SecurityContext:  broker.Spec.DeploymentPlan.PodSecurityContext,
+					Containers: []corev1.Container{{
+						Name:                     "data-tool",
+						Image:                    common.ResolveImage(broker, common.BrokerImageKey),
+						Command:                  []string{"/bin/sh", "-c", script},
+						Env:                      env,
+						VolumeMounts:             mounts,
+						Resources:                export.Spec.Resources,
+						SecurityContext:          broker.Spec.DeploymentPlan.ContainerSecurityContext,
+						TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+func secretKeyRef(secretName string, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+		Key:                  key,
+	}}
+}
+
+// the directories of the journal in its volume, like the broker config sets them
+func journalDirectories(broker *brokerv1beta1.ActiveMQArtemis, namers common.Namers) (journal string, bindings string, paging string, largeMessages string) {
+	if common.IsRestricted(broker) {
+		return "/app/data", "/app/data/bindings", "/app/data/paging", "/app/data/largemessages"
+	}
+	data := namers.GLOBAL_DATA_PATH
+	return data + "/journal", data + "/bindings", data + "/paging", data + "/large-messages"
+}
+
+// updateDataExportStatus reports the job, and the message counts of the file
+// once it succeeded
+func (r *ActiveMQArtemisDataExportReconciler) updateDataExportStatus(ctx context.Context, export *brokerv1beta1.ActiveMQArtemisDataExport, job *batchv1.Job) error {
+	export.Status.JobName = job.Name
+	export.Status.StartTime = job.Status.StartTime
+	export.Status.CompletionTime = job.Status.CompletionTime
+
+	previous := meta.FindStatusCondition(export.Status.Conditions, brokerv1beta1.ReadyConditionType)
+	switch {
+	case isJobConditionTrue(job, batchv1.JobComplete):
+		if previous != nil && previous.Reason == brokerv1beta1.DataExportSucceededReason {
+			return nil
+		}
+		counts, err := r.queueMessageCounts(ctx, job)
+		if err != nil {
+			return err
+		}
+		export.Status.QueueMessageCounts = counts
+		export.Status.TotalMessages = 0
+		for _, count := range counts {
+			export.Status.TotalMessages += count.Messages
+		}
+		setDataExportReady(export, metav1.ConditionTrue, brokerv1beta1.DataExportSucceededReason, fmt.Sprintf("job %s completed with %d messages", job.Name, export.Status.TotalMessages))
+		recordEvent(r.recorder, export, corev1.EventTypeNormal, EventReasonDataExportSucceeded, MessageDataExportSucceeded, job.Name, export.Status.TotalMessages)
+	case isJobConditionTrue(job, batchv1.JobFailed):
+		if previous != nil && previous.Reason == brokerv1beta1.DataExportFailedReason {
+			return nil
+		}
+		message := fmt.Sprintf("job %s failed, see the logs of its pod", job.Name)
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Message != "" {
+				message = fmt.Sprintf("job %s failed, %s", job.Name, condition.Message)
+			}
+		}
+		setDataExportReady(export, metav1.ConditionFalse, brokerv1beta1.DataExportFailedReason, message)
+		recordEvent(r.recorder, export, corev1.EventTypeWarning, EventReasonDataExportFailed, "%s", message)
+	case job.Status.Active > 0:
+		setDataExportReady(export, metav1.ConditionFalse, brokerv1beta1.DataExportRunningReason, fmt.Sprintf("job %s is running", job.Name))
+	}
+	return nil
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// the counts are in the termination message of the pod that succeeded
+func (r *ActiveMQArtemisDataExportReconciler) queueMessageCounts(ctx context.Context, job *batchv1.Job) ([]brokerv1beta1.QueueMessageCount, error) {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{dataExportLabel: job.Labels[dataExportLabel]}); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+				return parseQueueMessageCounts(status.State.Terminated.Message), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func parseQueueMessageCounts(message string) []brokerv1beta1.QueueMessageCount {
+	var counts []brokerv1beta1.QueueMessageCount
+	for _, line := range strings.Split(message, "\n") {
+		separator := strings.LastIndex(line, "=")
+		if separator <= 0 {
+			continue
+		}
+		// the last line may be cut by the size limit of the message
+		messages, err := strconv.ParseInt(line[separator+1:], 10, 64)
+		if err != nil {
+			continue
+		}
+		counts = append(counts, brokerv1beta1.QueueMessageCount{Queue: line[:separator], Messages: messages})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Queue < counts[j].Queue })
+	return counts
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func setDataExportReady(export *brokerv1beta1.ActiveMQArtemisDataExport, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&export.Status.Conditions, metav1.Condition{
+		Type:               brokerv1beta1.ReadyConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: export.Generation,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ActiveMQArtemisDataExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisDataExport")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisDataExport{}).
+		Owns(&batchv1.Job{}).
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisDataExport", tracing.InstrumentReconciler("ActiveMQArtemisDataExport", r)))
+}
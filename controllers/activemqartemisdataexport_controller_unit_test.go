@@ -0,0 +1,190 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// +kubebuilder:docs-gen:collapse=Apache License
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDataExportReconcile(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{PersistenceEnabled: true, Size: common.Int32ToPtr(2)},
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-ss", Namespace: "test"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: common.Int32ToPtr(2)},
+	}
+	export := &brokerv1beta1.ActiveMQArtemisDataExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain", Namespace: "test", UID: "drain-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisDataExportSpec{
+			BrokerName: "ex",
+			Ordinal:    1,
+			Target:     brokerv1beta1.DataExportTargetType{ClaimName: "exports"},
+		},
+	}
+	runningPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "ex-ss-1", Namespace: "test"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, statefulSet, export, runningPod).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisDataExport{}, &batchv1.Job{}).Build()
+
+	r := NewActiveMQArtemisDataExportReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestDataExportReconcile"))
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "drain", Namespace: "test"}}
+	current := func() *brokerv1beta1.ActiveMQArtemisDataExport {
+		current := &brokerv1beta1.ActiveMQArtemisDataExport{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+		return current
+	}
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{Name: "drain-export", Namespace: "test"}
+
+	reason := func() string {
+		ready := meta.FindStatusCondition(current().Status.Conditions, brokerv1beta1.ReadyConditionType)
+		assert.NotNil(t, ready)
+		return ready.Reason
+	}
+
+	// the statefulset would bring back the pod of an ordinal that is not scaled down
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0)
+	assert.Equal(t, brokerv1beta1.DataExportOrdinalNotScaledDownReason, reason())
+	assert.NoError(t, fakeClient.Get(context.TODO(), jobKey, job))
+	assert.True(t, *job.Spec.Suspend)
+
+	// the cr alone is not enough while the statefulset is not scaled down
+	broker.Spec.DeploymentPlan.Size = common.Int32ToPtr(1)
+	assert.NoError(t, fakeClient.Update(context.TODO(), broker))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, brokerv1beta1.DataExportOrdinalNotScaledDownReason, reason())
+
+	// the journal of a running broker is locked
+	statefulSet.Spec.Replicas = common.Int32ToPtr(1)
+	assert.NoError(t, fakeClient.Update(context.TODO(), statefulSet))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, brokerv1beta1.DataExportBrokerRunningReason, reason())
+	assert.NoError(t, fakeClient.Get(context.TODO(), jobKey, job))
+	assert.True(t, *job.Spec.Suspend)
+
+	// once the pod is gone the job starts and reads its journal volume
+	assert.NoError(t, fakeClient.Delete(context.TODO(), runningPod))
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.NoError(t, fakeClient.Get(context.TODO(), jobKey, job))
+	assert.False(t, isJobSuspended(job))
+	assert.Equal(t, "drain", job.OwnerReferences[0].Name)
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	assert.Len(t, podSpec.Volumes, 2)
+	assert.Equal(t, "exports", podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, "ex-ex-ss-1", podSpec.Volumes[1].PersistentVolumeClaim.ClaimName)
+	assert.True(t, podSpec.Volumes[1].PersistentVolumeClaim.ReadOnly)
+	container := podSpec.Containers[0]
+	assert.Contains(t, container.Command[2], "artemis data exp")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "FILE", Value: "/export/drain.xml"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "JOURNAL_DIR", Value: "/opt/ex/data/journal"})
+	assert.Equal(t, brokerv1beta1.DataExportPendingReason, meta.FindStatusCondition(current().Status.Conditions, brokerv1beta1.ReadyConditionType).Reason)
+
+	// the counts are in the termination message of the pod of the job
+	jobPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain-export-abcde", Namespace: "test", Labels: map[string]string{dataExportLabel: "drain"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "data-tool",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 0,
+				Message:  "orders=12\nDLQ=3\nexpiry.queue=",
+			}},
+		}}},
+	}
+	assert.NoError(t, fakeClient.Create(context.TODO(), jobPod))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	assert.NoError(t, fakeClient.Status().Update(context.TODO(), job))
+
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	status := current().Status
+	assert.Equal(t, "drain-export", status.JobName)
+	assert.Equal(t, brokerv1beta1.DataExportSucceededReason, meta.FindStatusCondition(status.Conditions, brokerv1beta1.ReadyConditionType).Reason)
+	assert.Equal(t, []brokerv1beta1.QueueMessageCount{{Queue: "DLQ", Messages: 3}, {Queue: "orders", Messages: 12}}, status.QueueMessageCounts)
+	assert.Equal(t, int64(15), status.TotalMessages)
+}
+
+func TestDataExportImportJob(t *testing.T) {
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{{Name: "amqp"}, {Name: "core", Port: 61617}, {Name: "all"}, {Name: "secure", SSLEnabled: true}},
+		},
+	}
+	export := &brokerv1beta1.ActiveMQArtemisDataExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "refill", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisDataExportSpec{
+			BrokerName: "ex",
+			Action:     brokerv1beta1.DataExportActionImport,
+			Acceptor:   "all",
+			Target:     brokerv1beta1.DataExportTargetType{ClaimName: "exports", File: "drain/drain.xml"},
+		},
+	}
+	assert.NoError(t, validateDataExport(export, broker))
+
+	job := newDataExportJob(export, broker)
+	assert.Equal(t, "refill-import", job.Name)
+	assert.True(t, isJobSuspended(job))
+	podSpec := job.Spec.Template.Spec
+	assert.Len(t, podSpec.Volumes, 1)
+	container := podSpec.Containers[0]
+	assert.Contains(t, container.Command[2], "artemis data imp")
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "FILE", Value: "/export/drain/drain.xml"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "BROKER_HOST", Value: "ex-ss-0.ex-hdls-svc.test.svc"})
+	// the second acceptor without a port
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "BROKER_PORT", Value: "61636"})
+
+	broker.Spec.PasswordCodec = &brokerv1beta1.PasswordCodecType{}
+	assert.ErrorContains(t, validateDataExport(export, broker), "passwordCodec")
+	broker.Spec.PasswordCodec = nil
+
+	export.Spec.Acceptor = "secure"
+	assert.ErrorContains(t, validateDataExport(export, broker), "ssl")
+	export.Spec.Acceptor = "missing"
+	assert.Error(t, validateDataExport(export, broker))
+	export.Spec.Acceptor = ""
+	export.Spec.Target.File = "../other.xml"
+	assert.Error(t, validateDataExport(export, broker))
+}
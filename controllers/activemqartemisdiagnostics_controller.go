@@ -0,0 +1,436 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	diagnosticsLabel           = "arkmq.org/diagnostics"
+	diagnosticsConfigMapPrefix = "diagnostics-"
+
+	defaultFlightRecordingDuration = time.Minute
+	// a recording holds the jvm until it is dumped, it is for the time of an incident
+	maxFlightRecordingDuration = time.Hour
+	// the size limit of a ConfigMap is 1MiB, with room for its metadata
+	maxDiagnosticsOutputSize = 1000 * 1000
+)
+
+// ActiveMQArtemisDiagnosticsReconciler reconciles a ActiveMQArtemisDiagnostics object
+type ActiveMQArtemisDiagnosticsReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	log      logr.Logger
+	// the jolokia clients of the brokers of a CR, replaced by the unit tests
+	brokersOf func(cr *brokerv1beta1.ActiveMQArtemis, client client.Client) []*jolokia_client.JkInfo
+	now       func() time.Time
+}
+
+func NewActiveMQArtemisDiagnosticsReconciler(client client.Client, scheme *runtime.Scheme, logger logr.Logger) *ActiveMQArtemisDiagnosticsReconciler {
+	return &ActiveMQArtemisDiagnosticsReconciler{
+		Client:    client,
+		Scheme:    scheme,
+		log:       logger,
+		brokersOf: brokerJolokiaEndpoints,
+		now:       time.Now,
+	}
+}
+
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdiagnostics,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdiagnostics/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=broker.amq.io,namespace=activemq-artemis-operator,resources=activemqartemisdiagnostics/finalizers,verbs=update
+
+// Reconcile runs the diagnostic command on the brokers once they are ready and
+// reports where the output is
+func (r *ActiveMQArtemisDiagnosticsReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name, "Reconciling", "ActiveMQArtemisDiagnostics")
+
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{}
+	if err := r.Client.Get(ctx, request.NamespacedName, diagnostics); err != nil {
+		if errors.IsNotFound(err) {
+			// the config maps are owned by the diagnostics
+			return ctrl.Result{}, nil
+		}
+		reqLogger.Error(err, "unable to retrieve the diagnostics")
+		return ctrl.Result{}, err
+	}
+
+	before := diagnostics.Status.DeepCopy()
+	result, err := r.reconcileDiagnostics(ctx, diagnostics)
+
+	if !equality.Semantic.DeepEqual(before, &diagnostics.Status) {
+		if updateErr := r.Client.Status().Update(ctx, diagnostics); updateErr != nil {
+			reqLogger.Error(updateErr, "unable to update the diagnostics status")
+			if err == nil {
+				err = updateErr
+			}
+		}
+	}
+	return result, err
+}
+
+func (r *ActiveMQArtemisDiagnosticsReconciler) reconcileDiagnostics(ctx context.Context, diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) (ctrl.Result, error) {
+	// the diagnostics run once, another run needs a new CR
+	if ready := meta.FindStatusCondition(diagnostics.Status.Conditions, brokerv1beta1.ReadyConditionType); ready != nil {
+		switch ready.Reason {
+		case brokerv1beta1.DiagnosticsSucceededReason, brokerv1beta1.DiagnosticsFailedReason:
+			return ctrl.Result{}, nil
+		case brokerv1beta1.DiagnosticsRunningReason:
+			return r.completeFlightRecording(diagnostics), nil
+		}
+	}
+
+	broker := &brokerv1beta1.ActiveMQArtemis{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: diagnostics.Spec.BrokerName, Namespace: diagnostics.Namespace}, broker); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		setDiagnosticsReady(diagnostics, metav1.ConditionFalse, brokerv1beta1.DiagnosticsBrokerNotFoundReason, fmt.Sprintf("ActiveMQArtemis %s not found", diagnostics.Spec.BrokerName))
+		return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+	}
+	if err := validateDiagnostics(diagnostics, broker); err != nil {
+		setDiagnosticsReady(diagnostics, metav1.ConditionFalse, brokerv1beta1.DiagnosticsInvalidSpecReason, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// the command runs on all the brokers or on none
+	brokers := map[string]*jolokia_client.JkInfo{}
+	for _, jk := range r.brokersOf(broker, r.Client) {
+		brokers[jk.Ordinal] = jk
+	}
+	ordinals := diagnosticsOrdinalsOf(diagnostics, broker)
+	for _, ordinal := range ordinals {
+		if brokers[diagnosticsBrokerOrdinal(diagnostics.Spec.BrokerGroup, ordinal)] == nil {
+			setDiagnosticsReady(diagnostics, metav1.ConditionFalse, brokerv1beta1.DiagnosticsBrokerNotReadyReason, fmt.Sprintf("waiting for broker pod %s to be ready", diagnosticsPodName(broker, diagnostics.Spec.BrokerGroup, ordinal)))
+			return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
+		}
+	}
+
+	if diagnostics.Status.StartTime == nil {
+		now := metav1.NewTime(r.now())
+		diagnostics.Status.StartTime = &now
+	}
+	for _, ordinal := range ordinals {
+		// a reconcile that failed on the api after some brokers does not run their command again
+		if hasDiagnosticsResult(diagnostics, ordinal) {
+			continue
+		}
+		result, err := r.runDiagnosticCommand(ctx, diagnostics, broker, ordinal, brokers[diagnosticsBrokerOrdinal(diagnostics.Spec.BrokerGroup, ordinal)])
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		diagnostics.Status.Results = append(diagnostics.Status.Results, result)
+	}
+
+	if diagnosticsActionOf(diagnostics) == brokerv1beta1.DiagnosticsActionFlightRecording && !hasDiagnosticsErrors(diagnostics) {
+		duration := flightRecordingDurationOf(diagnostics)
+		setDiagnosticsReady(diagnostics, metav1.ConditionFalse, brokerv1beta1.DiagnosticsRunningReason, fmt.Sprintf("recording for %s", duration))
+		recordEvent(r.recorder, diagnostics, corev1.EventTypeNormal, EventReasonDiagnosticsStarted, MessageDiagnosticsStarted, strings.ToLower(string(diagnosticsActionOf(diagnostics))), len(ordinals), broker.Name)
+		return ctrl.Result{RequeueAfter: duration}, nil
+	}
+	r.completeDiagnostics(diagnostics)
+	return ctrl.Result{}, nil
+}
+
+func validateDiagnostics(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, broker *brokerv1beta1.ActiveMQArtemis) error {
+	if common.IsRestricted(broker) {
+		// the certificate of the operator has no role on the mbeans of the jvm
+		return fmt.Errorf("ActiveMQArtemis %s is restricted, its jolokia agent does not allow the DiagnosticCommand MBean", broker.Name)
+	}
+	size, found := diagnosticsSizeOf(diagnostics, broker)
+	if !found {
+		return fmt.Errorf(".Spec.BrokerGroup %s is not a group of spec.deploymentPlan.brokerGroups of ActiveMQArtemis %s", diagnostics.Spec.BrokerGroup, broker.Name)
+	}
+	ordinals := map[int32]bool{}
+	for _, ordinal := range diagnostics.Spec.Ordinals {
+		if ordinal < 0 || ordinal >= size {
+			return fmt.Errorf(".Spec.Ordinals %d is not an ordinal of the %d brokers of ActiveMQArtemis %s%s", ordinal, size, broker.Name, diagnosticsGroupSuffix(diagnostics.Spec.BrokerGroup))
+		}
+		if ordinals[ordinal] {
+			return fmt.Errorf(".Spec.Ordinals %d is not unique", ordinal)
+		}
+		ordinals[ordinal] = true
+	}
+	switch diagnosticsActionOf(diagnostics) {
+	case brokerv1beta1.DiagnosticsActionThreadDump, brokerv1beta1.DiagnosticsActionHeapHistogram:
+		if diagnostics.Spec.Duration != nil {
+			return fmt.Errorf(".Spec.Duration is for a FlightRecording")
+		}
+	case brokerv1beta1.DiagnosticsActionFlightRecording:
+		if duration := flightRecordingDurationOf(diagnostics); duration <= 0 || duration > maxFlightRecordingDuration {
+			return fmt.Errorf(".Spec.Duration %s must be positive and at most %s", duration, maxFlightRecordingDuration)
+		}
+		if jvm := broker.Spec.DeploymentPlan.Jvm; jvm == nil || jvm.FlightRecorder == nil {
+			return fmt.Errorf("ActiveMQArtemis %s has no volume for the recordings, spec.deploymentPlan.jvm.flightRecorder is not set", broker.Name)
+		}
+	default:
+		return fmt.Errorf(".Spec.Action %q must be ThreadDump, HeapHistogram or FlightRecording", diagnostics.Spec.Action)
+	}
+	return nil
+}
+
+func diagnosticsActionOf(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) brokerv1beta1.DiagnosticsAction {
+	if diagnostics.Spec.Action == "" {
+		return brokerv1beta1.DiagnosticsActionThreadDump
+	}
+	return diagnostics.Spec.Action
+}
+
+func flightRecordingDurationOf(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) time.Duration {
+	if diagnostics.Spec.Duration == nil {
+		return defaultFlightRecordingDuration
+	}
+	return diagnostics.Spec.Duration.Duration
+}
+
+// diagnosticsSizeOf is the size of the deployment plan or of the group of the
+// diagnostics, not found for a group the broker does not have
+func diagnosticsSizeOf(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, broker *brokerv1beta1.ActiveMQArtemis) (int32, bool) {
+	if diagnostics.Spec.BrokerGroup == "" {
+		return common.GetDeploymentSize(broker), true
+	}
+	for index := range broker.Spec.DeploymentPlan.BrokerGroups {
+		if group := &broker.Spec.DeploymentPlan.BrokerGroups[index]; group.Name == diagnostics.Spec.BrokerGroup {
+			return common.GetBrokerGroupSize(group), true
+		}
+	}
+	return 0, false
+}
+
+func diagnosticsOrdinalsOf(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, broker *brokerv1beta1.ActiveMQArtemis) []int32 {
+	if len(diagnostics.Spec.Ordinals) > 0 {
+		return diagnostics.Spec.Ordinals
+	}
+	size, _ := diagnosticsSizeOf(diagnostics, broker)
+	var ordinals []int32
+	for ordinal := int32(0); ordinal < size; ordinal++ {
+		ordinals = append(ordinals, ordinal)
+	}
+	return ordinals
+}
+
+// diagnosticsBrokerOrdinal is the ordinal of a broker as it ends the name of
+// its pod, that of a broker of a group starts with the group
+func diagnosticsBrokerOrdinal(group string, ordinal int32) string {
+	if group == "" {
+		return strconv.Itoa(int(ordinal))
+	}
+	return common.GroupOrdinal(group, ordinal)
+}
+
+func diagnosticsPodName(broker *brokerv1beta1.ActiveMQArtemis, group string, ordinal int32) string {
+	return namer.CrToSS(broker.Name) + "-" + diagnosticsBrokerOrdinal(group, ordinal)
+}
+
+func diagnosticsGroupSuffix(group string) string {
+	if group == "" {
+		return ""
+	}
+	return " in group " + group
+}
+
+func hasDiagnosticsResult(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, ordinal int32) bool {
+	for _, result := range diagnostics.Status.Results {
+		if result.BrokerGroup == diagnostics.Spec.BrokerGroup && result.Ordinal == ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnosticsConfigMapName is prefixed so that it does not collide with the
+// ConfigMaps of the namespace named like the diagnostics
+func diagnosticsConfigMapName(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, ordinal int32) string {
+	return diagnosticsConfigMapPrefix + diagnostics.Name + "-" + diagnosticsBrokerOrdinal(diagnostics.Spec.BrokerGroup, ordinal)
+}
+
+// runDiagnosticCommand runs the command of the action on a broker, the error
+// of the broker is in the result, the error of the api is returned
+func (r *ActiveMQArtemisDiagnosticsReconciler) runDiagnosticCommand(ctx context.Context, diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, broker *brokerv1beta1.ActiveMQArtemis, ordinal int32, jk *jolokia_client.JkInfo) (brokerv1beta1.DiagnosticsResult, error) {
+	result := brokerv1beta1.DiagnosticsResult{BrokerGroup: diagnostics.Spec.BrokerGroup, Ordinal: ordinal}
+
+	var command, key string
+	var arguments []string
+	switch diagnosticsActionOf(diagnostics) {
+	case brokerv1beta1.DiagnosticsActionThreadDump:
+		// with the locks the threads hold
+		command, key, arguments = "threadPrint", "thread-dump.txt", []string{"-l"}
+	case brokerv1beta1.DiagnosticsActionHeapHistogram:
+		command, key = "gcClassHistogram", "heap-histogram.txt"
+	case brokerv1beta1.DiagnosticsActionFlightRecording:
+		recorder := broker.Spec.DeploymentPlan.Jvm.FlightRecorder
+		file := diagnostics.Name + "-" + diagnosticsBrokerOrdinal(diagnostics.Spec.BrokerGroup, ordinal) + ".jfr"
+		result.VolumeName = recorder.VolumeName
+		result.Path = path.Join(flightRecorderSubPath, file)
+		if !isExtraVolume(broker, recorder.VolumeName) {
+			// the claims of the volume claim templates are named like those of the journal
+			result.ClaimName = recorder.VolumeName + "-" + diagnosticsPodName(broker, diagnostics.Spec.BrokerGroup, ordinal)
+		}
+		arguments = []string{
+			"name=" + diagnostics.Name,
+			fmt.Sprintf("duration=%ds", int64(flightRecordingDurationOf(diagnostics).Seconds())),
+			"filename=" + path.Join(flightRecorderMountPath, file),
+		}
+		if recorder.Settings != "" {
+			arguments = append(arguments, "settings="+recorder.Settings)
+		}
+		if _, err := jk.Artemis.DiagnosticCommand("jfrStart", arguments...); err != nil {
+			result.Error = err.Error()
+		}
+		return result, nil
+	}
+
+	output, err := jk.Artemis.DiagnosticCommand(command, arguments...)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if len(output) > maxDiagnosticsOutputSize {
+		output = output[:maxDiagnosticsOutputSize]
+		result.Truncated = true
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      diagnosticsConfigMapName(diagnostics, ordinal),
+			Namespace: diagnostics.Namespace,
+			Labels:    map[string]string{diagnosticsLabel: diagnostics.Name},
+		},
+		Data: map[string]string{key: output},
+	}
+	if err := controllerutil.SetControllerReference(diagnostics, configMap, r.Scheme); err != nil {
+		return result, err
+	}
+	if err := r.Client.Create(ctx, configMap); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return result, err
+		}
+		existing := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(configMap), existing); err != nil {
+			return result, err
+		}
+		// only the output of an earlier reconcile of these diagnostics is replaced
+		if !metav1.IsControlledBy(existing, diagnostics) {
+			result.Error = fmt.Sprintf("ConfigMap %s exists and is not owned by the diagnostics", configMap.Name)
+			return result, nil
+		}
+		existing.Data = configMap.Data
+		if err := r.Client.Update(ctx, existing); err != nil {
+			return result, err
+		}
+	}
+	result.ConfigMapName = configMap.Name
+	result.Key = key
+	return result, nil
+}
+
+func isExtraVolume(broker *brokerv1beta1.ActiveMQArtemis, name string) bool {
+	for _, volume := range broker.Spec.DeploymentPlan.ExtraVolumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// completeFlightRecording waits for the duration of the recordings, the jvm
+// writes a recording to its file when it stops
+func (r *ActiveMQArtemisDiagnosticsReconciler) completeFlightRecording(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) ctrl.Result {
+	if diagnostics.Status.StartTime != nil {
+		end := diagnostics.Status.StartTime.Add(flightRecordingDurationOf(diagnostics))
+		if remaining := end.Sub(r.now()); remaining > 0 {
+			return ctrl.Result{RequeueAfter: remaining}
+		}
+	}
+	r.completeDiagnostics(diagnostics)
+	return ctrl.Result{}
+}
+
+func (r *ActiveMQArtemisDiagnosticsReconciler) completeDiagnostics(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) {
+	now := metav1.NewTime(r.now())
+	diagnostics.Status.CompletionTime = &now
+
+	var failed []string
+	for _, result := range diagnostics.Status.Results {
+		if result.Error != "" {
+			failed = append(failed, fmt.Sprintf("broker %s %s", diagnosticsBrokerOrdinal(result.BrokerGroup, result.Ordinal), result.Error))
+		}
+	}
+	action := strings.ToLower(string(diagnosticsActionOf(diagnostics)))
+	if len(failed) > 0 {
+		message := fmt.Sprintf("the %s failed on %d of %d brokers, %s", action, len(failed), len(diagnostics.Status.Results), strings.Join(failed, ", "))
+		setDiagnosticsReady(diagnostics, metav1.ConditionFalse, brokerv1beta1.DiagnosticsFailedReason, message)
+		recordEvent(r.recorder, diagnostics, corev1.EventTypeWarning, EventReasonDiagnosticsFailed, "%s", message)
+		return
+	}
+	setDiagnosticsReady(diagnostics, metav1.ConditionTrue, brokerv1beta1.DiagnosticsSucceededReason, fmt.Sprintf("the %s of %d brokers is collected", action, len(diagnostics.Status.Results)))
+	recordEvent(r.recorder, diagnostics, corev1.EventTypeNormal, EventReasonDiagnosticsSucceeded, MessageDiagnosticsSucceeded, action, len(diagnostics.Status.Results))
+}
+
+func hasDiagnosticsErrors(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics) bool {
+	for _, result := range diagnostics.Status.Results {
+		if result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func setDiagnosticsReady(diagnostics *brokerv1beta1.ActiveMQArtemisDiagnostics, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&diagnostics.Status.Conditions, metav1.Condition{
+		Type:               brokerv1beta1.ReadyConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: diagnostics.Generation,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ActiveMQArtemisDiagnosticsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisDiagnostics")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisDiagnostics{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisDiagnostics", tracing.InstrumentReconciler("ActiveMQArtemisDiagnostics", r)))
+}
@@ -0,0 +1,339 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// +kubebuilder:docs-gen:collapse=Apache License
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	artemis_client "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia_client"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const diagnosticCommandMBean = "com.sun.management:type=DiagnosticCommand"
+
+func TestDiagnosticsThreadDump(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{Size: common.Int32ToPtr(3)},
+		},
+	}
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "test", UID: "stuck-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisDiagnosticsSpec{
+			BrokerName: "ex",
+			Ordinals:   []int32{0, 2},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, diagnostics).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisDiagnostics{}).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	j := jolokia.NewMockIJolokia(mockCtrl)
+	j.EXPECT().Exec(diagnosticCommandMBean, gomock.Any()).DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+		assert.Contains(t, body, "threadPrint")
+		return &jolokia.ResponseData{Status: 200, Value: strings.Repeat("x", maxDiagnosticsOutputSize+1)}, nil
+	}).Times(2)
+
+	r := NewActiveMQArtemisDiagnosticsReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestDiagnosticsThreadDump"))
+	ready := []*jolokia_client.JkInfo{{Artemis: artemis_client.GetArtemisWithJolokia(j, "ex"), Ordinal: "0"}}
+	r.brokersOf = func(cr *brokerv1beta1.ActiveMQArtemis, _ client.Client) []*jolokia_client.JkInfo {
+		return ready
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "stuck", Namespace: "test"}}
+	current := func() *brokerv1beta1.ActiveMQArtemisDiagnostics {
+		current := &brokerv1beta1.ActiveMQArtemisDiagnostics{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+		return current
+	}
+
+	// the command waits for all the brokers
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0)
+	condition := meta.FindStatusCondition(current().Status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, brokerv1beta1.DiagnosticsBrokerNotReadyReason, condition.Reason)
+	assert.Contains(t, condition.Message, "ex-ss-2")
+
+	ready = append(ready, &jolokia_client.JkInfo{Artemis: artemis_client.GetArtemisWithJolokia(j, "ex"), Ordinal: "2"})
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+
+	status := current().Status
+	condition = meta.FindStatusCondition(status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, brokerv1beta1.DiagnosticsSucceededReason, condition.Reason)
+	assert.NotNil(t, status.CompletionTime)
+	assert.Len(t, status.Results, 2)
+	assert.Equal(t, int32(2), status.Results[1].Ordinal)
+	assert.Equal(t, "diagnostics-stuck-2", status.Results[1].ConfigMapName)
+	assert.True(t, status.Results[1].Truncated)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "diagnostics-stuck-2", Namespace: "test"}, configMap))
+	assert.Len(t, configMap.Data[status.Results[1].Key], maxDiagnosticsOutputSize)
+	assert.Equal(t, "stuck", configMap.OwnerReferences[0].Name)
+
+	// the diagnostics run once
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+}
+
+func TestDiagnosticsFlightRecording(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				PersistenceEnabled: true,
+				Jvm: &brokerv1beta1.JvmType{
+					FlightRecorder: &brokerv1beta1.FlightRecorderType{VolumeName: "ex", Settings: "profile"},
+				},
+			},
+		},
+	}
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{
+		ObjectMeta: metav1.ObjectMeta{Name: "slow", Namespace: "test", UID: "slow-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisDiagnosticsSpec{
+			BrokerName: "ex",
+			Action:     brokerv1beta1.DiagnosticsActionFlightRecording,
+			Duration:   &metav1.Duration{Duration: 5 * time.Minute},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, diagnostics).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisDiagnostics{}).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	j := jolokia.NewMockIJolokia(mockCtrl)
+	j.EXPECT().Exec(diagnosticCommandMBean, gomock.Any()).DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+		assert.Contains(t, body, `"operation":"jfrStart([Ljava.lang.String;)","arguments":[["name=slow","duration=300s","filename=/amq/jfr/slow-0.jfr","settings=profile"]]`)
+		return &jolokia.ResponseData{Status: 200, Value: "Started recording 1"}, nil
+	}).Times(1)
+
+	now := time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+	r := NewActiveMQArtemisDiagnosticsReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestDiagnosticsFlightRecording"))
+	r.now = func() time.Time { return now }
+	r.brokersOf = func(cr *brokerv1beta1.ActiveMQArtemis, _ client.Client) []*jolokia_client.JkInfo {
+		return []*jolokia_client.JkInfo{{Artemis: artemis_client.GetArtemisWithJolokia(j, "ex"), Ordinal: "0"}}
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "slow", Namespace: "test"}}
+	current := func() *brokerv1beta1.ActiveMQArtemisDiagnostics {
+		current := &brokerv1beta1.ActiveMQArtemisDiagnostics{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+		return current
+	}
+
+	result, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, result.RequeueAfter)
+	status := current().Status
+	assert.Equal(t, brokerv1beta1.DiagnosticsRunningReason, meta.FindStatusCondition(status.Conditions, brokerv1beta1.ReadyConditionType).Reason)
+	assert.Equal(t, "ex-ex-ss-0", status.Results[0].ClaimName)
+	assert.Equal(t, "jfr/slow-0.jfr", status.Results[0].Path)
+
+	now = now.Add(2 * time.Minute)
+	result, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, result.RequeueAfter)
+
+	now = now.Add(3 * time.Minute)
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	condition := meta.FindStatusCondition(current().Status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, brokerv1beta1.DiagnosticsSucceededReason, condition.Reason)
+}
+
+func TestDiagnosticsKeepConfigMapsOfOthers(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{Size: common.Int32ToPtr(3)},
+		},
+	}
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "test", UID: "stuck-uid"},
+		Spec:       brokerv1beta1.ActiveMQArtemisDiagnosticsSpec{BrokerName: "ex"},
+	}
+	// a ConfigMap of the user named like the output of a broker
+	theirs := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "diagnostics-stuck-0", Namespace: "test"},
+		Data:       map[string]string{"app.properties": "mine"},
+	}
+	createFails := true
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, diagnostics, theirs).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisDiagnostics{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if obj.GetName() == "diagnostics-stuck-2" && createFails {
+					createFails = false
+					return fmt.Errorf("etcdserver: request timed out")
+				}
+				return client.Create(ctx, obj, opts...)
+			},
+		}).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	j := jolokia.NewMockIJolokia(mockCtrl)
+	// once for each broker, the command is not run again on a retry
+	j.EXPECT().Exec(diagnosticCommandMBean, gomock.Any()).Return(&jolokia.ResponseData{Status: 200, Value: "threads"}, nil).Times(4)
+
+	r := NewActiveMQArtemisDiagnosticsReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestDiagnosticsKeepConfigMapsOfOthers"))
+	r.brokersOf = func(cr *brokerv1beta1.ActiveMQArtemis, _ client.Client) []*jolokia_client.JkInfo {
+		var brokers []*jolokia_client.JkInfo
+		for _, ordinal := range []string{"0", "1", "2"} {
+			brokers = append(brokers, &jolokia_client.JkInfo{Artemis: artemis_client.GetArtemisWithJolokia(j, "ex"), Ordinal: ordinal})
+		}
+		return brokers
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "stuck", Namespace: "test"}}
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.ErrorContains(t, err, "timed out")
+
+	current := &brokerv1beta1.ActiveMQArtemisDiagnostics{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+	assert.Len(t, current.Status.Results, 2)
+
+	_, err = r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+	assert.Len(t, current.Status.Results, 3)
+	assert.Contains(t, current.Status.Results[0].Error, "not owned by the diagnostics")
+	assert.Empty(t, current.Status.Results[2].Error)
+	condition := meta.FindStatusCondition(current.Status.Conditions, brokerv1beta1.ReadyConditionType)
+	assert.Equal(t, brokerv1beta1.DiagnosticsFailedReason, condition.Reason)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "diagnostics-stuck-0", Namespace: "test"}, configMap))
+	assert.Equal(t, theirs.Data, configMap.Data)
+	assert.Empty(t, configMap.OwnerReferences)
+}
+
+func TestDiagnosticsOfBrokerGroup(t *testing.T) {
+	testScheme := runtime.NewScheme()
+	assert.NoError(t, scheme.AddToScheme(testScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(testScheme))
+
+	broker := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				Size:         common.Int32ToPtr(2),
+				BrokerGroups: []brokerv1beta1.BrokerGroupType{{Name: "large", Size: common.Int32ToPtr(2)}},
+			},
+		},
+	}
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "test", UID: "stuck-uid"},
+		Spec: brokerv1beta1.ActiveMQArtemisDiagnosticsSpec{
+			BrokerName:  "ex",
+			BrokerGroup: "large",
+			Ordinals:    []int32{1},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+		WithObjects(broker, diagnostics).
+		WithStatusSubresource(&brokerv1beta1.ActiveMQArtemisDiagnostics{}).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	planBroker := jolokia.NewMockIJolokia(mockCtrl)
+	groupBroker := jolokia.NewMockIJolokia(mockCtrl)
+	groupBroker.EXPECT().Exec(diagnosticCommandMBean, gomock.Any()).Return(&jolokia.ResponseData{Status: 200, Value: "threads"}, nil).Times(1)
+
+	r := NewActiveMQArtemisDiagnosticsReconciler(fakeClient, testScheme, ctrl.Log.WithName("TestDiagnosticsOfBrokerGroup"))
+	r.brokersOf = func(cr *brokerv1beta1.ActiveMQArtemis, _ client.Client) []*jolokia_client.JkInfo {
+		return []*jolokia_client.JkInfo{
+			{Artemis: artemis_client.GetArtemisWithJolokia(planBroker, "ex"), Ordinal: "1"},
+			{Artemis: artemis_client.GetArtemisWithJolokia(groupBroker, "ex"), Ordinal: "large-1"},
+		}
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "stuck", Namespace: "test"}}
+	_, err := r.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+
+	current := &brokerv1beta1.ActiveMQArtemisDiagnostics{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), request.NamespacedName, current))
+	assert.Equal(t, brokerv1beta1.DiagnosticsSucceededReason, meta.FindStatusCondition(current.Status.Conditions, brokerv1beta1.ReadyConditionType).Reason)
+	assert.Len(t, current.Status.Results, 1)
+	assert.Equal(t, "large", current.Status.Results[0].BrokerGroup)
+	assert.Equal(t, int32(1), current.Status.Results[0].Ordinal)
+	assert.Equal(t, "diagnostics-stuck-large-1", current.Status.Results[0].ConfigMapName)
+}
+
+func TestValidateDiagnostics(t *testing.T) {
+	broker := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: "ex"}}
+	diagnostics := &brokerv1beta1.ActiveMQArtemisDiagnostics{
+		Spec: brokerv1beta1.ActiveMQArtemisDiagnosticsSpec{BrokerName: "ex", Ordinals: []int32{0}},
+	}
+	assert.NoError(t, validateDiagnostics(diagnostics, broker))
+
+	diagnostics.Spec.Ordinals = []int32{1}
+	assert.ErrorContains(t, validateDiagnostics(diagnostics, broker), "not an ordinal")
+
+	diagnostics.Spec.BrokerGroup = "large"
+	assert.ErrorContains(t, validateDiagnostics(diagnostics, broker), "not a group")
+
+	broker.Spec.DeploymentPlan.BrokerGroups = []brokerv1beta1.BrokerGroupType{{Name: "large", Size: common.Int32ToPtr(2)}}
+	assert.NoError(t, validateDiagnostics(diagnostics, broker))
+
+	diagnostics.Spec.BrokerGroup = ""
+	diagnostics.Spec.Ordinals = nil
+	diagnostics.Spec.Action = brokerv1beta1.DiagnosticsActionFlightRecording
+	assert.ErrorContains(t, validateDiagnostics(diagnostics, broker), "flightRecorder")
+
+	broker.Spec.DeploymentPlan.Jvm = &brokerv1beta1.JvmType{FlightRecorder: &brokerv1beta1.FlightRecorderType{VolumeName: "recordings"}}
+	diagnostics.Spec.Duration = &metav1.Duration{Duration: 2 * time.Hour}
+	assert.ErrorContains(t, validateDiagnostics(diagnostics, broker), ".Spec.Duration")
+
+	diagnostics.Spec.Duration = nil
+	broker.Spec.Restricted = common.NewTrue()
+	assert.ErrorContains(t, validateDiagnostics(diagnostics, broker), "restricted")
+}
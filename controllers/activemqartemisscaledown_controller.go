@@ -23,6 +23,8 @@ import (
 
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/draincontroller"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -30,6 +32,7 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -43,9 +46,10 @@ var kubeClient *kubernetes.Clientset
 // ActiveMQArtemisScaledownReconciler reconciles a ActiveMQArtemisScaledown object
 type ActiveMQArtemisScaledownReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Config *rest.Config
-	log    logr.Logger
+	Scheme   *runtime.Scheme
+	Config   *rest.Config
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
 func NewActiveMQArtemisScaledownReconciler(client client.Client, scheme *runtime.Scheme, config *rest.Config, logger logr.Logger) *ActiveMQArtemisScaledownReconciler {
@@ -102,6 +106,8 @@ func (r *ActiveMQArtemisScaledownReconciler) Reconcile(ctx context.Context, requ
 
 		reqLogger.V(2).Info("Running drain controller async so multiple controllers can run...")
 		go r.runDrainController(drainControllerInstance)
+
+		recordEvent(r.recorder, instance, corev1.EventTypeNormal, EventReasonScaledownStarted, MessageScaledownStarted, scaledownScope(instance.Spec.LocalOnly, request.Namespace))
 	}
 
 	reqLogger.V(1).Info("OK, return result")
@@ -157,8 +163,9 @@ func (r *ActiveMQArtemisScaledownReconciler) runDrainController(controller *drai
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ActiveMQArtemisScaledownReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisScaledown")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&brokerv1beta1.ActiveMQArtemisScaledown{}).
 		Owns(&corev1.Pod{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisScaledown", tracing.InstrumentReconciler("ActiveMQArtemisScaledown", r)))
 }
@@ -18,16 +18,21 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/secrets"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/lsrcrs"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/random"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/secretsource"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 	"github.com/go-logr/logr"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +40,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -44,6 +50,7 @@ type ActiveMQArtemisSecurityReconciler struct {
 	client.Client
 	Scheme           *runtime.Scheme
 	BrokerReconciler *ActiveMQArtemisReconciler
+	recorder         record.EventRecorder
 	log              logr.Logger
 }
 
@@ -118,6 +125,7 @@ func (r *ActiveMQArtemisSecurityReconciler) Reconcile(ctx context.Context, reque
 
 	if err := r.BrokerReconciler.AddBrokerConfigHandler(request.NamespacedName, newHandler, toReconcile); err != nil {
 		reqLogger.Error(err, "failed to config security cr", "request", request.NamespacedName)
+		recordEvent(r.recorder, instance, corev1.EventTypeWarning, EventReasonSecurityApplyFailed, MessageSecurityApplyFailed, err)
 		return ctrl.Result{}, err
 	}
 	//persist the CR
@@ -126,7 +134,13 @@ func (r *ActiveMQArtemisSecurityReconciler) Reconcile(ctx context.Context, reque
 		reqLogger.Error(merr, "failed to marshal cr")
 	}
 
-	instanceWithPasswords := newHandler.processCrPasswords()
+	instanceWithPasswords, err := newHandler.processCrPasswords()
+	if err != nil {
+		reqLogger.Error(err, "failed to resolve passwords from external secret sources", "request", request.NamespacedName)
+		recordEvent(r.recorder, instance, corev1.EventTypeWarning, EventReasonSecurityApplyFailed, MessageSecurityApplyFailed, err)
+		return ctrl.Result{}, err
+	}
+	withoutOIDCLoginModuleReferences(instanceWithPasswords)
 
 	// remove superfluous data that can trip up the shell
 	instanceWithPasswords.ObjectMeta = metav1.ObjectMeta{}
@@ -139,6 +153,10 @@ func (r *ActiveMQArtemisSecurityReconciler) Reconcile(ctx context.Context, reque
 	lsrcrs.StoreLastSuccessfulReconciledCR(instance, instance.Name, instance.Namespace, "security",
 		crstr, string(data), instance.ResourceVersion, getLabels(instance), r.Client, r.Scheme)
 
+	if toReconcile {
+		recordEvent(r.recorder, instance, corev1.EventTypeNormal, EventReasonSecurityApplied, MessageSecurityApplied, instance.Namespace)
+	}
+
 	return ctrl.Result{RequeueAfter: common.GetReconcileResyncPeriod()}, nil
 }
 
@@ -184,9 +202,13 @@ func (r *ActiveMQArtemisSecurityConfigHandler) IsApplicableFor(brokerNamespacedN
 	return false
 }
 
-func (r *ActiveMQArtemisSecurityConfigHandler) processCrPasswords() *brokerv1beta1.ActiveMQArtemisSecurity {
+func (r *ActiveMQArtemisSecurityConfigHandler) processCrPasswords() (*brokerv1beta1.ActiveMQArtemisSecurity, error) {
 	result := r.SecurityCR.DeepCopy()
 
+	if err := r.resolvePasswordSources(result); err != nil {
+		return nil, err
+	}
+
 	if len(result.Spec.LoginModules.PropertiesLoginModules) > 0 {
 		for i, pm := range result.Spec.LoginModules.PropertiesLoginModules {
 			if len(pm.Users) > 0 {
@@ -225,7 +247,64 @@ func (r *ActiveMQArtemisSecurityConfigHandler) processCrPasswords() *brokerv1bet
 			}
 		}
 	}
-	return result
+
+	// keycloak configuration has no notion of masked values, only the properties users are masked
+	if result.Spec.MaskPasswords != nil && *result.Spec.MaskPasswords {
+		for i, pm := range result.Spec.LoginModules.PropertiesLoginModules {
+			for j, user := range pm.Users {
+				if user.Password != nil {
+					// a stable salt keeps the generated config from changing on every reconcile
+					hashed := codec.MaskOneWay(*user.Password, []byte(r.NamespacedName.String()+"/"+pm.Name+"/"+user.Name))
+					result.Spec.LoginModules.PropertiesLoginModules[i].Users[j].Password = &hashed
+				}
+			}
+		}
+	}
+	result.Spec.MaskPasswords = nil
+	return result, nil
+}
+
+// values from external secret sources take the place of the plain fields, the
+// result is only persisted in the secret that is projected into the init container
+func (r *ActiveMQArtemisSecurityConfigHandler) resolvePasswordSources(cr *brokerv1beta1.ActiveMQArtemisSecurity) error {
+	resolver := secretsource.NewResolver(r.owner.Client)
+	namespace := r.NamespacedName.Namespace
+
+	resolve := func(value **string, source **brokerv1beta1.SecretSourceType, field string) error {
+		if *source != nil {
+			if *value == nil {
+				resolved, err := resolver.Resolve(context.TODO(), *source, namespace)
+				if err != nil {
+					return fmt.Errorf("unable to resolve %s, %v", field, err)
+				}
+				*value = &resolved
+			}
+			*source = nil
+		}
+		return nil
+	}
+
+	for i, pm := range cr.Spec.LoginModules.PropertiesLoginModules {
+		for j := range pm.Users {
+			user := &cr.Spec.LoginModules.PropertiesLoginModules[i].Users[j]
+			if err := resolve(&user.Password, &user.PasswordFrom, fmt.Sprintf("password of user %s in login module %s", user.Name, pm.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	for i, km := range cr.Spec.LoginModules.KeycloakLoginModules {
+		config := &cr.Spec.LoginModules.KeycloakLoginModules[i].Configuration
+		if err := resolve(&config.TrustStorePassword, &config.TrustStorePasswordFrom, "trust store password of login module "+km.Name); err != nil {
+			return err
+		}
+		if err := resolve(&config.ClientKeyStorePassword, &config.ClientKeyStorePasswordFrom, "client key store password of login module "+km.Name); err != nil {
+			return err
+		}
+		if err := resolve(&config.ClientKeyPassword, &config.ClientKeyPasswordFrom, "client key password of login module "+km.Name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *ActiveMQArtemisSecurityConfigHandler) GetDefaultLabels() map[string]string {
@@ -285,6 +364,7 @@ func (r *ActiveMQArtemisSecurityConfigHandler) Config(initContainers []corev1.Co
 	r.owner.log.V(2).Info("get the command", "value", cmdPersistCRAsYaml)
 	configCmds = append(configCmds, cmdPersistCRAsYaml)
 	configCmds = append(configCmds, "/opt/amq-broker/script/cfg/config-security.sh")
+	configCmds = append(configCmds, r.oidcConfigCmds(outputDir)...)
 	envVarName := "SECURITY_CFG_YAML"
 	envVar := corev1.EnvVar{
 		Name:      envVarName,
@@ -315,7 +395,8 @@ func (r *ActiveMQArtemisSecurityConfigHandler) Config(initContainers []corev1.Co
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ActiveMQArtemisSecurityReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("ActiveMQArtemisSecurity")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&brokerv1beta1.ActiveMQArtemisSecurity{}).
-		Complete(r)
+		Complete(metrics.InstrumentReconciler("ActiveMQArtemisSecurity", tracing.InstrumentReconciler("ActiveMQArtemisSecurity", r)))
 }
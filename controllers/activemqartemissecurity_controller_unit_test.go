@@ -0,0 +1,321 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// +kubebuilder:docs-gen:collapse=Apache License
+package controllers
+
+import (
+	"encoding/base64"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/secretsource"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newOIDCSecurityCR(issuerUrl string) *brokerv1beta1.ActiveMQArtemisSecurity {
+	moduleName := "oidc"
+	propsModuleName := "props"
+	guestModuleName := "guest"
+	consoleDomainName := "console"
+	return &brokerv1beta1.ActiveMQArtemisSecurity{
+		Spec: brokerv1beta1.ActiveMQArtemisSecuritySpec{
+			LoginModules: brokerv1beta1.LoginModulesType{
+				PropertiesLoginModules: []brokerv1beta1.PropertiesLoginModuleType{{Name: propsModuleName}},
+				GuestLoginModules:      []brokerv1beta1.GuestLoginModuleType{{Name: guestModuleName}},
+				OIDCLoginModules: []brokerv1beta1.OIDCLoginModuleType{
+					{
+						Name:        moduleName,
+						IssuerUrl:   issuerUrl + "/",
+						Audience:    &[]string{"artemis"}[0],
+						ClientId:    &[]string{"artemis-console"}[0],
+						RedirectUri: &[]string{"http://console.local/console"}[0],
+						RolesClaim:  &[]string{"realm_access.roles"}[0],
+						RoleMappings: []brokerv1beta1.OIDCRoleMappingType{
+							{Claim: "broker-admin", Roles: []string{"amq", "admin"}},
+							{Claim: "group:ops \"east\"", Roles: []string{"amq"}},
+						},
+						JwksCache: brokerv1beta1.OIDCJwksCacheType{CacheTtl: &[]int64{90}[0]},
+					},
+				},
+			},
+			SecurityDomains: brokerv1beta1.SecurityDomainsType{
+				BrokerDomain: brokerv1beta1.BrokerDomainType{
+					LoginModules: []brokerv1beta1.LoginModuleReferenceType{
+						{Name: &propsModuleName, Flag: &[]string{"sufficient"}[0]},
+						{Name: &moduleName, Flag: &[]string{"sufficient"}[0]},
+						{Name: &guestModuleName, Flag: &[]string{"required"}[0]},
+					},
+				},
+				ConsoleDomain: brokerv1beta1.BrokerDomainType{
+					Name: &consoleDomainName,
+					LoginModules: []brokerv1beta1.LoginModuleReferenceType{
+						{Name: &moduleName},
+					},
+				},
+			},
+		},
+	}
+}
+
+func decodeWriteBase64Cmd(t *testing.T, cmd string) string {
+	fields := strings.Fields(cmd)
+	assert.Equal(t, "echo", fields[0])
+	content, err := base64.StdEncoding.DecodeString(fields[1])
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestOIDCHawtioConfig(t *testing.T) {
+	cr := newOIDCSecurityCR("http://issuer.local")
+
+	config := renderHawtioOIDCConfig(&cr.Spec.LoginModules.OIDCLoginModules[0])
+
+	assert.Contains(t, config, "provider = http://issuer.local\n")
+	assert.Contains(t, config, "client_id = artemis-console\n")
+	assert.Contains(t, config, "scope = openid\n")
+	assert.Contains(t, config, "redirect_uri = http://console.local/console\n")
+	assert.Contains(t, config, "jwks.cacheTime = 2\n")
+	assert.Contains(t, config, "oidc.rolesPath = realm_access.roles\n")
+	assert.Contains(t, config, "roleMapping.broker-admin = amq,admin\n")
+	assert.Contains(t, config, "roleMapping.group\\:ops\\ \"east\" = amq\n")
+}
+
+func TestOIDCLoginModuleEntry(t *testing.T) {
+	cr := newOIDCSecurityCR("http://issuer.local")
+	module := &cr.Spec.LoginModules.OIDCLoginModules[0]
+
+	entry := renderOIDCLoginModuleEntry(module, &cr.Spec.SecurityDomains.BrokerDomain.LoginModules[1])
+
+	assert.True(t, strings.HasPrefix(entry, "    "+oidcLoginModuleClass+" sufficient\n"))
+	assert.Contains(t, entry, "provider=\"http://issuer.local\"\n")
+	assert.Contains(t, entry, "audience=\"artemis\"\n")
+	assert.Contains(t, entry, "identityPaths=\"sub\"\n")
+	assert.Contains(t, entry, "rolesPaths=\"realm_access.roles\"\n")
+	assert.Contains(t, entry, "cacheKeysTime=\"90\"\n")
+	assert.Contains(t, entry, "minTimeBetweenJwksRequests=\"10\"\n")
+	assert.Contains(t, entry, "\"roleMapping.broker-admin\"=\"amq,admin\"\n")
+	assert.Contains(t, entry, "\"roleMapping.group:ops \\\"east\\\"\"=\"amq\"\n")
+	assert.True(t, strings.HasSuffix(entry, "    ;\n"))
+
+	entry = renderOIDCLoginModuleEntry(module, &cr.Spec.SecurityDomains.ConsoleDomain.LoginModules[0])
+	assert.True(t, strings.HasPrefix(entry, "    "+oidcLoginModuleClass+" required\n"))
+}
+
+func TestOIDCLoginModuleReferencesRemovedForYacfg(t *testing.T) {
+	cr := newOIDCSecurityCR("http://issuer.local")
+
+	withoutOIDCLoginModuleReferences(cr)
+
+	assert.Len(t, cr.Spec.SecurityDomains.BrokerDomain.LoginModules, 2)
+	assert.Equal(t, "props", *cr.Spec.SecurityDomains.BrokerDomain.LoginModules[0].Name)
+	assert.Equal(t, "guest", *cr.Spec.SecurityDomains.BrokerDomain.LoginModules[1].Name)
+	assert.Empty(t, cr.Spec.SecurityDomains.ConsoleDomain.LoginModules)
+	assert.Len(t, cr.Spec.LoginModules.OIDCLoginModules, 1)
+}
+
+func TestOIDCSecurityConfigCmds(t *testing.T) {
+	cr := newOIDCSecurityCR("http://issuer.local")
+	handler := &ActiveMQArtemisSecurityConfigHandler{
+		SecurityCR:     cr,
+		NamespacedName: types.NamespacedName{Name: "sec", Namespace: "test"},
+		owner:          &ActiveMQArtemisSecurityReconciler{log: ctrl.Log},
+	}
+	initContainers := []corev1.Container{{Name: "init"}}
+
+	cmds := handler.Config(initContainers, "/init", "1.0.0", "artemis")
+
+	var securityCfgIndex int
+	for i, cmd := range cmds {
+		if cmd == "/opt/amq-broker/script/cfg/config-security.sh" {
+			securityCfgIndex = i
+		}
+	}
+	oidcCmds := cmds[securityCfgIndex+1:]
+	assert.Len(t, oidcCmds, 7)
+
+	assert.Equal(t, oidcMergeScript, decodeWriteBase64Cmd(t, oidcCmds[0]))
+	assert.True(t, strings.HasSuffix(oidcCmds[0], "> /init/security/oidc-login-config.sh"))
+
+	assert.Contains(t, decodeWriteBase64Cmd(t, oidcCmds[1]), "provider=\"http://issuer.local\"")
+	assert.True(t, strings.HasSuffix(oidcCmds[1], "> /init/security/oidc-console-0.login.config"))
+	assert.Equal(t, "/bin/bash /init/security/oidc-login-config.sh console /init/security/oidc-console ${CONFIG_INSTANCE_DIR}/etc/login.config", oidcCmds[2])
+
+	// the oidc module follows the properties module in the broker domain
+	assert.True(t, strings.HasSuffix(oidcCmds[3], "> /init/security/oidc-activemq-1.login.config"))
+	assert.Equal(t, "/bin/bash /init/security/oidc-login-config.sh activemq /init/security/oidc-activemq ${CONFIG_INSTANCE_DIR}/etc/login.config", oidcCmds[4])
+
+	assert.Contains(t, decodeWriteBase64Cmd(t, oidcCmds[5]), "client_id = artemis-console")
+	assert.True(t, strings.HasSuffix(oidcCmds[5], "> ${CONFIG_INSTANCE_DIR}/etc/hawtio-oidc.properties"))
+	assert.Contains(t, decodeWriteBase64Cmd(t, oidcCmds[6]), "-Dhawtio.oidcConfig=${ARTEMIS_INSTANCE_ETC}/hawtio-oidc.properties")
+	assert.True(t, strings.HasSuffix(oidcCmds[6], ">> ${CONFIG_INSTANCE_DIR}/etc/artemis.profile"))
+}
+
+// the login.config as rendered by yacfg without the oidc references
+const yacfgOIDCLoginConfig = `activemq {
+
+    // props
+    org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule sufficient
+        reload=true
+        org.apache.activemq.jaas.properties.user="artemis-users.properties"
+        org.apache.activemq.jaas.properties.role="artemis-roles.properties";
+
+    // guest
+    org.apache.activemq.artemis.spi.core.security.jaas.GuestLoginModule required
+        org.apache.activemq.jaas.guest.user="guest"
+        org.apache.activemq.jaas.guest.role="guest";
+
+};
+
+console {
+};
+`
+
+func TestOIDCSecurityConfigCmdsLoginConfig(t *testing.T) {
+	if _, err := os.Stat("/bin/bash"); err != nil {
+		t.Skip("the init commands need /bin/bash")
+	}
+	cr := newOIDCSecurityCR("http://issuer.local")
+	handler := &ActiveMQArtemisSecurityConfigHandler{
+		SecurityCR:     cr,
+		NamespacedName: types.NamespacedName{Name: "sec", Namespace: "test"},
+		owner:          &ActiveMQArtemisSecurityReconciler{log: ctrl.Log},
+	}
+	instanceDir := t.TempDir()
+	outputDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(instanceDir+"/etc", 0755))
+	assert.NoError(t, os.WriteFile(instanceDir+"/etc/login.config", []byte(yacfgOIDCLoginConfig), 0644))
+
+	cmd := exec.Command("/bin/bash", "-c", strings.Join(handler.oidcConfigCmds(outputDir), " && "))
+	cmd.Env = append(os.Environ(), "CONFIG_INSTANCE_DIR="+instanceDir)
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+
+	loginConfig, err := os.ReadFile(instanceDir + "/etc/login.config")
+	assert.NoError(t, err)
+
+	// the modules of each domain, in order
+	modules := map[string][]string{}
+	var domain string
+	for _, line := range strings.Split(string(loginConfig), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[1] == "{":
+			domain = fields[0]
+		case len(fields) == 2 && strings.HasPrefix(fields[0], "org.apache.activemq.artemis.spi.core.security.jaas."):
+			modules[domain] = append(modules[domain], strings.TrimPrefix(fields[0], "org.apache.activemq.artemis.spi.core.security.jaas.")+" "+fields[1])
+		}
+	}
+	assert.Equal(t, []string{"PropertiesLoginModule sufficient", "OIDCLoginModule sufficient", "GuestLoginModule required"}, modules["activemq"])
+	assert.Equal(t, []string{"OIDCLoginModule required"}, modules["console"])
+	assert.Contains(t, string(loginConfig), "        \"roleMapping.group:ops \\\"east\\\"\"=\"amq\"\n")
+	assert.True(t, strings.HasSuffix(string(loginConfig), "console {\n"+renderOIDCLoginModuleEntry(&cr.Spec.LoginModules.OIDCLoginModules[0], &cr.Spec.SecurityDomains.ConsoleDomain.LoginModules[0])+"};\n"))
+}
+
+func TestSecurityPasswordsFromSecretSources(t *testing.T) {
+	client := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "shared-credentials",
+			Namespace:   "store",
+			Annotations: map[string]string{secretsource.SharedWithNamespacesAnnotation: "test"},
+		},
+		Data: map[string][]byte{"joe": []byte("from-store"), "truststore": []byte("changeit")},
+	}).Build()
+
+	source := func(key string) *brokerv1beta1.SecretSourceType {
+		return &brokerv1beta1.SecretSourceType{Secret: &brokerv1beta1.SecretSourceSecretType{Namespace: "store", Name: "shared-credentials", Key: key}}
+	}
+	plain := "plain"
+	cr := &brokerv1beta1.ActiveMQArtemisSecurity{
+		Spec: brokerv1beta1.ActiveMQArtemisSecuritySpec{
+			LoginModules: brokerv1beta1.LoginModulesType{
+				PropertiesLoginModules: []brokerv1beta1.PropertiesLoginModuleType{{
+					Name: "props",
+					Users: []brokerv1beta1.UserType{
+						{Name: "joe", PasswordFrom: source("joe")},
+						{Name: "ann", Password: &plain, PasswordFrom: source("joe")},
+					},
+				}},
+				KeycloakLoginModules: []brokerv1beta1.KeycloakLoginModuleType{{
+					Name: "keycloak",
+					Configuration: brokerv1beta1.KeycloakModuleConfigurationType{
+						TrustStorePasswordFrom: source("truststore"),
+					},
+				}},
+			},
+		},
+	}
+	handler := &ActiveMQArtemisSecurityConfigHandler{
+		SecurityCR:     cr,
+		NamespacedName: types.NamespacedName{Name: "sec", Namespace: "test"},
+		owner:          &ActiveMQArtemisSecurityReconciler{Client: client, log: ctrl.Log},
+	}
+
+	result, err := handler.processCrPasswords()
+	assert.NoError(t, err)
+
+	users := result.Spec.LoginModules.PropertiesLoginModules[0].Users
+	assert.Equal(t, "from-store", *users[0].Password)
+	assert.Equal(t, "plain", *users[1].Password)
+	assert.Nil(t, users[0].PasswordFrom)
+	assert.Equal(t, "changeit", *result.Spec.LoginModules.KeycloakLoginModules[0].Configuration.TrustStorePassword)
+	// the CR itself is left as is
+	assert.Nil(t, cr.Spec.LoginModules.PropertiesLoginModules[0].Users[0].Password)
+
+	cr.Spec.LoginModules.PropertiesLoginModules[0].Users[0].PasswordFrom = source("missing")
+	_, err = handler.processCrPasswords()
+	assert.ErrorContains(t, err, "password of user joe in login module props")
+}
+
+func TestSecurityMaskPasswords(t *testing.T) {
+	plain := "plain"
+	mask := true
+	cr := &brokerv1beta1.ActiveMQArtemisSecurity{
+		Spec: brokerv1beta1.ActiveMQArtemisSecuritySpec{
+			MaskPasswords: &mask,
+			LoginModules: brokerv1beta1.LoginModulesType{
+				PropertiesLoginModules: []brokerv1beta1.PropertiesLoginModuleType{{
+					Name:  "props",
+					Users: []brokerv1beta1.UserType{{Name: "joe", Password: &plain}},
+				}},
+			},
+		},
+	}
+	handler := &ActiveMQArtemisSecurityConfigHandler{
+		SecurityCR:     cr,
+		NamespacedName: types.NamespacedName{Name: "sec", Namespace: "test"},
+		owner:          &ActiveMQArtemisSecurityReconciler{Client: fake.NewClientBuilder().Build(), log: ctrl.Log},
+	}
+
+	result, err := handler.processCrPasswords()
+	assert.NoError(t, err)
+	masked := *result.Spec.LoginModules.PropertiesLoginModules[0].Users[0].Password
+	assert.True(t, codec.IsMasked(masked))
+	assert.True(t, codec.VerifyOneWay("plain", masked))
+	assert.Nil(t, result.Spec.MaskPasswords)
+
+	// stable across reconciles
+	again, err := handler.processCrPasswords()
+	assert.NoError(t, err)
+	assert.Equal(t, masked, *again.Spec.LoginModules.PropertiesLoginModules[0].Users[0].Password)
+	assert.Equal(t, "plain", *cr.Spec.LoginModules.PropertiesLoginModules[0].Users[0].Password)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+)
+
+const (
+	oidcLoginModuleClass     = "org.apache.activemq.artemis.spi.core.security.jaas.OIDCLoginModule"
+	hawtioOIDCConfigFileName = "hawtio-oidc.properties"
+	oidcMergeScriptFileName  = "oidc-login-config.sh"
+
+	defaultBrokerDomainName            = "activemq"
+	defaultOIDCScope                   = "openid"
+	defaultOIDCPrincipalClaim          = "sub"
+	defaultOIDCJwksCacheTtl            = int64(3600)
+	defaultOIDCJwksMinTimeBetweenFetch = int64(10)
+)
+
+// the yacfg templates of the init image know nothing of oidc modules, the
+// references are removed from the domains it renders and the oidc entries are
+// merged into the generated login.config afterwards, at the position of their
+// reference so that the flags keep their meaning
+func withoutOIDCLoginModuleReferences(cr *brokerv1beta1.ActiveMQArtemisSecurity) {
+	if len(cr.Spec.LoginModules.OIDCLoginModules) == 0 {
+		return
+	}
+	for _, domain := range []*brokerv1beta1.BrokerDomainType{&cr.Spec.SecurityDomains.BrokerDomain, &cr.Spec.SecurityDomains.ConsoleDomain} {
+		var remaining []brokerv1beta1.LoginModuleReferenceType
+		for _, ref := range domain.LoginModules {
+			if findOIDCLoginModule(cr, ref.Name) == nil {
+				remaining = append(remaining, ref)
+			}
+		}
+		domain.LoginModules = remaining
+	}
+}
+
+func findOIDCLoginModule(cr *brokerv1beta1.ActiveMQArtemisSecurity, name *string) *brokerv1beta1.OIDCLoginModuleType {
+	if name == nil {
+		return nil
+	}
+	for i, module := range cr.Spec.LoginModules.OIDCLoginModules {
+		if module.Name == *name {
+			return &cr.Spec.LoginModules.OIDCLoginModules[i]
+		}
+	}
+	return nil
+}
+
+// the module validates the access token that a client presents as its
+// password, for AMQP with SASL PLAIN
+func renderOIDCLoginModuleEntry(module *brokerv1beta1.OIDCLoginModuleType, ref *brokerv1beta1.LoginModuleReferenceType) string {
+	flag := "required"
+	if ref.Flag != nil && *ref.Flag != "" {
+		flag = *ref.Flag
+	}
+	debug := false
+	if ref.Debug != nil {
+		debug = *ref.Debug
+	}
+
+	entry := &bytes.Buffer{}
+	fmt.Fprintf(entry, "    %s %s\n", oidcLoginModuleClass, flag)
+	fmt.Fprintf(entry, "        debug=%t\n", debug)
+	fmt.Fprintf(entry, "        provider=%s\n", jaasQuoted(strings.TrimSuffix(module.IssuerUrl, "/")))
+	if module.Audience != nil {
+		fmt.Fprintf(entry, "        audience=%s\n", jaasQuoted(*module.Audience))
+	}
+	fmt.Fprintf(entry, "        identityPaths=%s\n", jaasQuoted(valueOrDefault(module.PrincipalClaim, defaultOIDCPrincipalClaim)))
+	if module.RolesClaim != nil {
+		fmt.Fprintf(entry, "        rolesPaths=%s\n", jaasQuoted(*module.RolesClaim))
+	}
+	fmt.Fprintf(entry, "        cacheKeysTime=\"%d\"\n", int64ValueOrDefault(module.JwksCache.CacheTtl, defaultOIDCJwksCacheTtl))
+	fmt.Fprintf(entry, "        minTimeBetweenJwksRequests=\"%d\"\n", int64ValueOrDefault(module.JwksCache.MinTimeBetweenRequests, defaultOIDCJwksMinTimeBetweenFetch))
+	for _, mapping := range module.RoleMappings {
+		// claim values like group:admins are no jaas words, the key is quoted as well
+		fmt.Fprintf(entry, "        %s=%s\n", jaasQuoted("roleMapping."+mapping.Claim), jaasQuoted(strings.Join(mapping.Roles, ",")))
+	}
+	fmt.Fprintln(entry, "    ;")
+	return entry.String()
+}
+
+// a quoted string of the login.config, read with the escapes of a java StreamTokenizer
+func jaasQuoted(value string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(value) + "\""
+}
+
+// a key of a java properties file
+func propertiesKey(key string) string {
+	return strings.NewReplacer("\\", "\\\\", " ", "\\ ", ":", "\\:", "=", "\\=").Replace(key)
+}
+
+// hawtio-oidc.properties as understood by the hawtio OIDC authentication filter
+func renderHawtioOIDCConfig(module *brokerv1beta1.OIDCLoginModuleType) string {
+	config := &bytes.Buffer{}
+	fmt.Fprintf(config, "provider = %s\n", strings.TrimSuffix(module.IssuerUrl, "/"))
+	if module.ClientId != nil {
+		fmt.Fprintf(config, "client_id = %s\n", *module.ClientId)
+	}
+	fmt.Fprintln(config, "response_mode = fragment")
+	fmt.Fprintf(config, "scope = %s\n", valueOrDefault(module.Scope, defaultOIDCScope))
+	if module.RedirectUri != nil {
+		fmt.Fprintf(config, "redirect_uri = %s\n", *module.RedirectUri)
+	}
+	fmt.Fprintln(config, "code_challenge_method = S256")
+	fmt.Fprintln(config, "prompt = login")
+	fmt.Fprintln(config, "oidc.cacheConfig = true")
+	// hawtio caches keys in minutes, round up so the cache is never disabled
+	cacheTtl := int64ValueOrDefault(module.JwksCache.CacheTtl, defaultOIDCJwksCacheTtl)
+	fmt.Fprintf(config, "jwks.cacheTime = %d\n", (cacheTtl+59)/60)
+	if module.RolesClaim != nil {
+		fmt.Fprintf(config, "oidc.rolesPath = %s\n", *module.RolesClaim)
+	}
+	for _, mapping := range module.RoleMappings {
+		fmt.Fprintf(config, "%s = %s\n", propertiesKey("roleMapping."+mapping.Claim), strings.Join(mapping.Roles, ","))
+	}
+	return config.String()
+}
+
+// merges the oidc entries into a domain of a login.config rendered by yacfg,
+// args are the domain, the prefix of its fragments and the login.config
+const oidcMergeScript = `domain="$1"
+prefix="$2"
+config="$3"
+in_domain=false
+entries=0
+while IFS= read -r line || [ -n "$line" ]; do
+  printf '%s\n' "$line"
+  if [ "$in_domain" = false ]; then
+    if [[ "$line" =~ ^"$domain"[[:space:]]*\{ ]]; then
+      in_domain=true
+      if [ -f "$prefix-0.login.config" ]; then cat "$prefix-0.login.config"; fi
+    fi
+  elif [[ "$line" =~ ^[[:space:]]*\} ]]; then
+    in_domain=false
+  elif [[ ! "$line" =~ ^[[:space:]]*// && "$line" =~ \;[[:space:]]*$ ]]; then
+    entries=$((entries + 1))
+    if [ -f "$prefix-$entries.login.config" ]; then cat "$prefix-$entries.login.config"; fi
+  fi
+done < "$config" > "$config.oidc"
+mv "$config.oidc" "$config"
+`
+
+// init commands that add the oidc entries to the domains of the login.config and
+// point the management console at the hawtio oidc configuration
+func (r *ActiveMQArtemisSecurityConfigHandler) oidcConfigCmds(outputDir string) []string {
+	cr := r.SecurityCR
+	if len(cr.Spec.LoginModules.OIDCLoginModules) == 0 {
+		return nil
+	}
+
+	var cmds []string
+	brokerDomainName := valueOrDefault(cr.Spec.SecurityDomains.BrokerDomain.Name, defaultBrokerDomainName)
+	consoleDomain := &cr.Spec.SecurityDomains.BrokerDomain
+	if cr.Spec.SecurityDomains.ConsoleDomain.Name != nil {
+		consoleDomain = &cr.Spec.SecurityDomains.ConsoleDomain
+		cmds = append(cmds, oidcDomainCmds(cr, consoleDomain, *consoleDomain.Name, outputDir)...)
+	}
+	cmds = append(cmds, oidcDomainCmds(cr, &cr.Spec.SecurityDomains.BrokerDomain, brokerDomainName, outputDir)...)
+	if len(cmds) > 0 {
+		cmds = append([]string{writeBase64Cmd(oidcMergeScript, outputDir+"/"+oidcMergeScriptFileName, false)}, cmds...)
+	}
+
+	for _, ref := range consoleDomain.LoginModules {
+		if module := findOIDCLoginModule(cr, ref.Name); module != nil {
+			etcDir := "${CONFIG_INSTANCE_DIR}/etc"
+			cmds = append(cmds, writeBase64Cmd(renderHawtioOIDCConfig(module), etcDir+"/"+hawtioOIDCConfigFileName, false))
+			// resolved when the profile is sourced by the broker
+			javaArgs := fmt.Sprintf("JAVA_ARGS=\"$JAVA_ARGS -Dhawtio.authenticationEnabled=true -Dhawtio.oidcConfig=${ARTEMIS_INSTANCE_ETC}/%s\"\n", hawtioOIDCConfigFileName)
+			cmds = append(cmds, writeBase64Cmd(javaArgs, etcDir+"/artemis.profile", true))
+			// the console supports a single oidc provider
+			break
+		}
+	}
+	return cmds
+}
+
+// the entries following the n-th entry rendered by yacfg in a domain are
+// written to <outputDir>/oidc-<domain>-<n>.login.config for the merge script
+func oidcDomainCmds(cr *brokerv1beta1.ActiveMQArtemisSecurity, domain *brokerv1beta1.BrokerDomainType, domainName string, outputDir string) []string {
+	var cmds []string
+	fragments := map[int]*bytes.Buffer{}
+	position := 0
+	for i, ref := range domain.LoginModules {
+		module := findOIDCLoginModule(cr, ref.Name)
+		if module == nil {
+			position++
+			continue
+		}
+		if fragments[position] == nil {
+			fragments[position] = &bytes.Buffer{}
+		}
+		fragments[position].WriteString(renderOIDCLoginModuleEntry(module, &domain.LoginModules[i]))
+	}
+	if len(fragments) == 0 {
+		return nil
+	}
+	prefix := outputDir + "/oidc-" + domainName
+	for position := 0; position <= len(domain.LoginModules); position++ {
+		if fragment, found := fragments[position]; found {
+			cmds = append(cmds, writeBase64Cmd(fragment.String(), fmt.Sprintf("%s-%d.login.config", prefix, position), false))
+		}
+	}
+	return append(cmds, fmt.Sprintf("/bin/bash %s/%s %s %s ${CONFIG_INSTANCE_DIR}/etc/login.config", outputDir, oidcMergeScriptFileName, domainName, prefix))
+}
+
+// content is base64 encoded so that nothing in it is interpreted by the init shell
+func writeBase64Cmd(content string, path string, appendTo bool) string {
+	redirect := ">"
+	if appendTo {
+		redirect = ">>"
+	}
+	return fmt.Sprintf("echo %s | base64 -d %s %s", base64.StdEncoding.EncodeToString([]byte(content)), redirect, path)
+}
+
+func valueOrDefault(value *string, defaultValue string) string {
+	if value != nil && *value != "" {
+		return *value
+	}
+	return defaultValue
+}
+
+func int64ValueOrDefault(value *int64, defaultValue int64) int64 {
+	if value != nil {
+		return *value
+	}
+	return defaultValue
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// the reasons of the events recorded against the custom resources, they are
+// part of the api, kubectl get events --field-selector reason=<reason> relies on them
+const (
+	EventReasonValidationFailed            = "ValidationFailed"
+	EventReasonValidated                   = "Validated"
+	EventReasonReconcileBlocked            = "ReconcileBlocked"
+	EventReasonReconcileResumed            = "ReconcileResumed"
+	EventReasonReconcilePaused             = "ReconcilePaused"
+	EventReasonReconcileFailed             = "ReconcileFailed"
+	EventReasonPodsRolling                 = "PodsRolling"
+	EventReasonScaled                      = "Scaled"
+	EventReasonBrokerPropertiesApplied     = "BrokerPropertiesApplied"
+	EventReasonBrokerPropertiesApplyFailed = "BrokerPropertiesApplyFailed"
+	EventReasonJaasPropertiesApplied       = "JaasPropertiesApplied"
+	EventReasonJaasPropertiesApplyFailed   = "JaasPropertiesApplyFailed"
+	EventReasonAddressCreated              = "AddressCreated"
+	EventReasonAddressCreateFailed         = "AddressCreateFailed"
+	EventReasonSecurityApplied             = "SecurityApplied"
+	EventReasonSecurityApplyFailed         = "SecurityApplyFailed"
+	EventReasonScaledownStarted            = "ScaledownStarted"
+	EventReasonVolumeExpanding             = "VolumeExpanding"
+	EventReasonJournalAutoExpanded         = "JournalAutoExpanded"
+	EventReasonDiskPressure                = "DiskPressure"
+	EventReasonDiskPressureRelieved        = "DiskPressureRelieved"
+	EventReasonBackupStarted               = "BackupStarted"
+	EventReasonBackupFailed                = "BackupFailed"
+	EventReasonBackupDeleted               = "BackupDeleted"
+	EventReasonRestoringVolume             = "RestoringVolume"
+	EventReasonDataExportStarted           = "DataExportStarted"
+	EventReasonDataExportSucceeded         = "DataExportSucceeded"
+	EventReasonDataExportFailed            = "DataExportFailed"
+	EventReasonOverridesNotApplied         = "OrdinalOverridesNotApplied"
+	EventReasonZoneCoLocated               = "ZoneCoLocated"
+	EventReasonDiagnosticsStarted          = "DiagnosticsStarted"
+	EventReasonDiagnosticsSucceeded        = "DiagnosticsSucceeded"
+	EventReasonDiagnosticsFailed           = "DiagnosticsFailed"
+
+	MessageValidated               = "the spec is valid"
+	MessageReconcileBlocked        = "reconcile is blocked by the annotation %s"
+	MessageReconcileResumed        = "reconcile resumed"
+	MessagePodsRollingOnChecksum   = "rolling the pods of %s, the content of a referenced secret changed"
+	MessagePodsRollingOnTemplate   = "rolling the pods of %s, the pod template changed"
+	MessageScaled                  = "scaled %s from %d to %d replicas"
+	MessageBrokerPropertiesApplied = "broker properties are applied on all brokers"
+	MessageJaasPropertiesApplied   = "jaas properties are applied on all brokers"
+	MessageAddressCreated          = "address %s is created on the matching brokers"
+	MessageAddressCreateFailed     = "address %s could not be created, %v"
+	MessageSecurityApplied         = "security is applied to the brokers of namespace %s"
+	MessageSecurityApplyFailed     = "security could not be applied, %v"
+	MessageScaledownStarted        = "drain controller started for the statefulsets of %s"
+	MessageVolumeExpanding         = "expanding volume claim %s to %s"
+	MessageJournalAutoExpanded     = "growing the journal volumes from %s to %s under disk pressure"
+	MessageDiskPressureRelieved    = "the usage of every broker is below the thresholds"
+	MessageBackupStarted           = "taking backup %s of %d journal volumes of %s"
+	MessageBackupDeleted           = "deleting backup %s and its volume snapshots, past the retention"
+	MessageRestoringVolume         = "creating volume claim %s from volume snapshot %s"
+	MessageDataExportStarted       = "job %s started to %s the messages of broker pod %s"
+	MessageDataExportSucceeded     = "job %s completed with %d messages"
+	MessageOverridesNotApplied     = "recreating pod %s, it was created without the override or the zone of its ordinal"
+	MessageZoneCoLocated           = "broker %d %s"
+	MessageDiagnosticsStarted      = "started the %s of %d brokers of %s"
+	MessageDiagnosticsSucceeded    = "the %s of %d brokers is collected"
+)
+
+// the render command and the unit tests run without a recorder
+func recordEvent(recorder record.EventRecorder, object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+// recordConditionEvents records the transitions of the conditions of a broker
+// between two reconciles, a condition that stays the same is not recorded again
+func recordConditionEvents(recorder record.EventRecorder, cr *brokerv1beta1.ActiveMQArtemis, before []metav1.Condition) {
+	previous := func(conditionType string) *metav1.Condition {
+		return meta.FindStatusCondition(before, conditionType)
+	}
+	current := func(conditionType string) *metav1.Condition {
+		return meta.FindStatusCondition(cr.Status.Conditions, conditionType)
+	}
+
+	if valid := current(brokerv1beta1.ValidConditionType); valid != nil {
+		was := previous(brokerv1beta1.ValidConditionType)
+		if valid.Status == metav1.ConditionFalse && (was == nil || was.Status != valid.Status || was.Message != valid.Message) {
+			recordEvent(recorder, cr, corev1.EventTypeWarning, EventReasonValidationFailed, "%s: %s", valid.Reason, valid.Message)
+		} else if valid.Status == metav1.ConditionTrue && was != nil && was.Status == metav1.ConditionFalse {
+			recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonValidated, MessageValidated)
+		}
+	}
+
+	blocked, wasBlocked := current(brokerv1beta1.ReconcileBlockedType) != nil, previous(brokerv1beta1.ReconcileBlockedType) != nil
+	if blocked && !wasBlocked {
+		recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonReconcileBlocked, MessageReconcileBlocked, common.BlockReconcileAnnotation)
+	} else if !blocked && wasBlocked {
+		recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonReconcileResumed, MessageReconcileResumed)
+	}
+
+	// a change of the paused parts is news too
+	if paused, was := current(brokerv1beta1.ReconcilePausedType), previous(brokerv1beta1.ReconcilePausedType); paused != nil && (was == nil || was.Message != paused.Message) {
+		recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonReconcilePaused, "%s", paused.Message)
+	} else if paused == nil && was != nil {
+		recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonReconcileResumed, MessageReconcileResumed)
+	}
+
+	if pressure, was := current(brokerv1beta1.DiskPressureConditionType), previous(brokerv1beta1.DiskPressureConditionType); pressure != nil && (was == nil || was.Message != pressure.Message) {
+		recordEvent(recorder, cr, corev1.EventTypeWarning, EventReasonDiskPressure, "%s", pressure.Message)
+	} else if pressure == nil && was != nil {
+		recordEvent(recorder, cr, corev1.EventTypeNormal, EventReasonDiskPressureRelieved, MessageDiskPressureRelieved)
+	}
+
+	recordAppliedEvents(recorder, cr, current(brokerv1beta1.ConfigAppliedConditionType), previous(brokerv1beta1.ConfigAppliedConditionType),
+		EventReasonBrokerPropertiesApplied, MessageBrokerPropertiesApplied, EventReasonBrokerPropertiesApplyFailed)
+	recordAppliedEvents(recorder, cr, current(brokerv1beta1.JaasConfigAppliedConditionType), previous(brokerv1beta1.JaasConfigAppliedConditionType),
+		EventReasonJaasPropertiesApplied, MessageJaasPropertiesApplied, EventReasonJaasPropertiesApplyFailed)
+}
+
+// only an apply error reported by the brokers is a failure, the other false
+// reasons are transient while the brokers start or reload
+func recordAppliedEvents(recorder record.EventRecorder, cr *brokerv1beta1.ActiveMQArtemis, applied *metav1.Condition, was *metav1.Condition, appliedReason string, appliedMessage string, failedReason string) {
+	if applied == nil {
+		return
+	}
+	if applied.Status == metav1.ConditionTrue {
+		if was == nil || was.Status != metav1.ConditionTrue {
+			recordEvent(recorder, cr, corev1.EventTypeNormal, appliedReason, "%s", appliedMessage)
+		}
+	} else if applied.Reason == brokerv1beta1.ConfigAppliedConditionSynchedWithErrorReason {
+		if was == nil || was.Reason != applied.Reason || was.Message != applied.Message {
+			recordEvent(recorder, cr, corev1.EventTypeWarning, failedReason, "%s", applied.Message)
+		}
+	}
+}
+
+// recordStatefulSetEvents records what an update of the statefulset does to
+// the pods, a roll or a scale
+func (reconciler *ActiveMQArtemisReconcilerImpl) recordStatefulSetEvents(customResource *brokerv1beta1.ActiveMQArtemis, deployed *appsv1.StatefulSet, requested *appsv1.StatefulSet) {
+	if deployed == nil || requested == nil {
+		return
+	}
+	if !equality.Semantic.DeepEqual(deployed.Spec.Template, requested.Spec.Template) {
+		if rollCountOf(deployed) != rollCountOf(requested) {
+			recordEvent(reconciler.recorder, customResource, corev1.EventTypeNormal, EventReasonPodsRolling, MessagePodsRollingOnChecksum, requested.Name)
+		} else {
+			recordEvent(reconciler.recorder, customResource, corev1.EventTypeNormal, EventReasonPodsRolling, MessagePodsRollingOnTemplate, requested.Name)
+		}
+	}
+	if from, to := replicasOf(deployed), replicasOf(requested); from != to {
+		recordEvent(reconciler.recorder, customResource, corev1.EventTypeNormal, EventReasonScaled, MessageScaled, requested.Name, from, to)
+	}
+}
+
+func rollCountOf(statefulSet *appsv1.StatefulSet) string {
+	if envVar := environments.Retrieve(statefulSet.Spec.Template.Spec.Containers, "TRIGGERED_ROLL_COUNT"); envVar != nil {
+		return envVar.Value
+	}
+	return ""
+}
+
+func replicasOf(statefulSet *appsv1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		// the api server default
+		return 1
+	}
+	return *statefulSet.Spec.Replicas
+}
+
+func isStatefulSetType(kind reflect.Type) bool {
+	return kind == reflect.TypeOf(appsv1.StatefulSet{})
+}
+
+func scaledownScope(localOnly bool, namespace string) string {
+	if localOnly {
+		return fmt.Sprintf("namespace %s", namespace)
+	}
+	return "all namespaces"
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func recordedEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestRecordConditionEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "test"}}
+
+	invalid := metav1.Condition{
+		Type:    brokerv1beta1.ValidConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  brokerv1beta1.ValidConditionFailedDuplicateAcceptorPort,
+		Message: "duplicate port 61616",
+	}
+	cr.Status.Conditions = []metav1.Condition{invalid}
+	recordConditionEvents(recorder, cr, nil)
+	assert.Equal(t, []string{"Warning ValidationFailed " + invalid.Reason + ": duplicate port 61616"}, recordedEvents(recorder))
+
+	// the same failure is not recorded again
+	recordConditionEvents(recorder, cr, []metav1.Condition{invalid})
+	assert.Empty(t, recordedEvents(recorder))
+
+	before := cr.Status.Conditions
+	cr.Status.Conditions = []metav1.Condition{
+		{Type: brokerv1beta1.ValidConditionType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.ValidConditionSuccessReason},
+		{Type: brokerv1beta1.ReconcileBlockedType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.ReconcileBlockedReason},
+		{Type: brokerv1beta1.ConfigAppliedConditionType, Status: metav1.ConditionFalse, Reason: brokerv1beta1.ConfigAppliedConditionSynchedWithErrorReason, Message: "bad key %d"},
+	}
+	recordConditionEvents(recorder, cr, before)
+	assert.Equal(t, []string{
+		"Normal Validated " + MessageValidated,
+		"Normal ReconcileBlocked reconcile is blocked by the annotation arkmq.org/block-reconcile",
+		"Warning BrokerPropertiesApplyFailed bad key %d",
+	}, recordedEvents(recorder))
+
+	before = cr.Status.Conditions
+	cr.Status.Conditions = []metav1.Condition{
+		{Type: brokerv1beta1.ValidConditionType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.ValidConditionSuccessReason},
+		{Type: brokerv1beta1.ConfigAppliedConditionType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.ConfigAppliedConditionSynchedReason},
+	}
+	recordConditionEvents(recorder, cr, before)
+	assert.Equal(t, []string{
+		"Normal ReconcileResumed " + MessageReconcileResumed,
+		"Normal BrokerPropertiesApplied " + MessageBrokerPropertiesApplied,
+	}, recordedEvents(recorder))
+
+	// a broker that is yet to start is not a failure
+	before = cr.Status.Conditions
+	cr.Status.Conditions = []metav1.Condition{
+		{Type: brokerv1beta1.ConfigAppliedConditionType, Status: metav1.ConditionFalse, Reason: brokerv1beta1.ConfigAppliedConditionNoJolokiaClientsAvailableReason},
+	}
+	recordConditionEvents(recorder, cr, before)
+	assert.Empty(t, recordedEvents(recorder))
+
+	paused := metav1.Condition{Type: brokerv1beta1.ReconcilePausedType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.ReconcilePausedReason, Message: "Reconcile paused for statefulSet"}
+	recordConditionEvents(recorder, &brokerv1beta1.ActiveMQArtemis{Status: brokerv1beta1.ActiveMQArtemisStatus{Conditions: []metav1.Condition{paused}}}, nil)
+	assert.Equal(t, []string{"Normal ReconcilePaused Reconcile paused for statefulSet"}, recordedEvents(recorder))
+	recordConditionEvents(recorder, cr, []metav1.Condition{paused})
+	assert.Equal(t, []string{"Normal ReconcileResumed " + MessageReconcileResumed}, recordedEvents(recorder))
+
+	pressure := metav1.Condition{Type: brokerv1beta1.DiskPressureConditionType, Status: metav1.ConditionTrue, Reason: brokerv1beta1.DiskPressureThresholdReason, Message: "journal disk of broker 0 above 80%"}
+	recordConditionEvents(recorder, &brokerv1beta1.ActiveMQArtemis{Status: brokerv1beta1.ActiveMQArtemisStatus{Conditions: []metav1.Condition{pressure}}}, nil)
+	assert.Equal(t, []string{"Warning DiskPressure journal disk of broker 0 above 80%"}, recordedEvents(recorder))
+	recordConditionEvents(recorder, cr, []metav1.Condition{pressure})
+	assert.Equal(t, []string{"Normal DiskPressureRelieved " + MessageDiskPressureRelieved}, recordedEvents(recorder))
+}
+
+func TestRecordStatefulSetEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	cr := &brokerv1beta1.ActiveMQArtemis{ObjectMeta: metav1.ObjectMeta{Name: "broker", Namespace: "test"}}
+	reconciler := &ActiveMQArtemisReconcilerImpl{recorder: recorder}
+
+	replicas := int32(1)
+	deployed := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "broker-ss"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "broker", Env: []corev1.EnvVar{{Name: "TRIGGERED_ROLL_COUNT", Value: "a"}}}},
+				},
+			},
+		},
+	}
+
+	requested := deployed.DeepCopy()
+	reconciler.recordStatefulSetEvents(cr, deployed, requested)
+	assert.Empty(t, recordedEvents(recorder))
+
+	requested.Spec.Template.Spec.Containers[0].Env[0].Value = "b"
+	reconciler.recordStatefulSetEvents(cr, deployed, requested)
+	assert.Equal(t, []string{"Normal PodsRolling rolling the pods of broker-ss, the content of a referenced secret changed"}, recordedEvents(recorder))
+
+	requested = deployed.DeepCopy()
+	requested.Spec.Template.Spec.Containers[0].Image = "broker:next"
+	scaled := int32(3)
+	requested.Spec.Replicas = &scaled
+	reconciler.recordStatefulSetEvents(cr, deployed, requested)
+	assert.Equal(t, []string{
+		"Normal PodsRolling rolling the pods of broker-ss, the pod template changed",
+		"Normal Scaled scaled broker-ss from 1 to 3 replicas",
+	}, recordedEvents(recorder))
+}
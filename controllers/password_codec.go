@@ -0,0 +1,88 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type passwordMasker func(string) (string, error)
+
+// the masker for a codec spec, nil when passwords are emitted as is
+func newPasswordMasker(codecSpec *brokerv1beta1.PasswordCodecType, namespace string, client rtclient.Client) (passwordMasker, error) {
+	if codecSpec == nil {
+		return nil, nil
+	}
+	key := codec.DefaultKey
+	if ref := codecSpec.KeySecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("unable to retrieve password codec key secret %s, %v", ref.Name, err)
+		}
+		value, found := secret.Data[ref.Key]
+		if !found || len(value) == 0 {
+			return nil, fmt.Errorf("password codec key secret %s has no key %s", ref.Name, ref.Key)
+		}
+		key = string(value)
+	}
+	// a key the cipher rejects fails the reconcile rather than each password
+	if _, err := codec.Mask("", key); err != nil {
+		return nil, fmt.Errorf("password codec key of secret %s is not usable, %v", codecSpec.KeySecretRef.Name, err)
+	}
+	return func(password string) (string, error) {
+		return codec.Mask(password, key)
+	}, nil
+}
+
+func (mask passwordMasker) maskAll(passwords ...*string) error {
+	if mask == nil {
+		return nil
+	}
+	for _, password := range passwords {
+		if password == nil || *password == "" {
+			continue
+		}
+		masked, err := mask(*password)
+		if err != nil {
+			return err
+		}
+		*password = masked
+	}
+	return nil
+}
+
+// the broker decodes with the same key
+func passwordCodecBrokerConfig(codecSpec *brokerv1beta1.PasswordCodecType, container *corev1.Container) {
+	if codecSpec == nil {
+		return
+	}
+	if codecSpec.KeySecretRef != nil {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: codec.KeyEnvVar,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: codecSpec.KeySecretRef,
+			},
+		})
+	}
+}
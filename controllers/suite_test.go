@@ -58,6 +58,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	brokerv1alpha1 "github.com/arkmq-org/activemq-artemis-operator/api/v1alpha1"
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
@@ -577,6 +579,51 @@ func shutdownControllerManager() {
 	<-managerChannel
 }
 
+// setUpWebhookEnvTest serves the webhooks to their own api server, the specs of
+// the suite create invalid CRs on purpose to assert on the Valid condition
+func setUpWebhookEnvTest() (*envtest.Environment, *rest.Config, context.CancelFunc) {
+	webhookEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "config", "webhook")},
+		},
+	}
+	webhookConfig, err := webhookEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+
+	options := &webhookEnv.WebhookInstallOptions
+	webhookManager, err := ctrl.NewManager(webhookConfig, ctrl.Options{
+		Scheme: scheme.Scheme,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    options.LocalServingHost,
+			Port:    options.LocalServingPort,
+			CertDir: options.LocalServingCertDir,
+		}),
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(SetupWebhooksWithManager(webhookManager, NewActiveMQArtemisReconciler(webhookManager, ctrl.Log, false))).To(Succeed())
+
+	webhookCtx, webhookCancel := context.WithCancel(ctx)
+	go func() {
+		defer GinkgoRecover()
+		Expect(webhookManager.Start(webhookCtx)).To(Succeed())
+	}()
+
+	address := fmt.Sprintf("%s:%d", options.LocalServingHost, options.LocalServingPort)
+	Eventually(func() error {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}, timeout, interval).Should(Succeed())
+
+	return webhookEnv, webhookConfig, webhookCancel
+}
+
 func setUpRealOperator() {
 	var err error
 	restConfig, err = config.GetConfig()
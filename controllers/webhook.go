@@ -0,0 +1,335 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The validating webhooks reject a CR with the message of the failed check. They
+// only check the spec, the resources a CR depends on, like a referenced secret, can
+// be applied after it and are checked by the reconcile. An update is only rejected
+// by a check that the previous spec passed, so that a CR stored before a check was
+// added can still be changed.
+//
+// Each kind is registered for its v1beta1 hub, which also serves /convert for the
+// older versions once the CRDs use the conversion webhook.
+
+//+kubebuilder:webhook:path=/validate-broker-amq-io-v1beta1-activemqartemis,mutating=false,failurePolicy=fail,sideEffects=None,groups=broker.amq.io,resources=activemqartemises,verbs=create;update,versions=v1beta1,name=vactivemqartemis.broker.amq.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-broker-amq-io-v1beta1-activemqartemisaddress,mutating=false,failurePolicy=fail,sideEffects=None,groups=broker.amq.io,resources=activemqartemisaddresses,verbs=create;update,versions=v1beta1,name=vactivemqartemisaddress.broker.amq.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-broker-amq-io-v1beta1-activemqartemissecurity,mutating=false,failurePolicy=fail,sideEffects=None,groups=broker.amq.io,resources=activemqartemissecurities,verbs=create;update,versions=v1beta1,name=vactivemqartemissecurity.broker.amq.io,admissionReviewVersions=v1
+
+// The pod webhook applies the override and the placement zone of their ordinal
+// to the broker pods, the objectSelector of config/webhook limits it to the pods
+// of an ActiveMQArtemis. It fails open so that the brokers are created while the
+// operator is down, the reconcile recreates the pods it did not see.
+
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.broker.amq.io,admissionReviewVersions=v1
+
+func SetupWebhooksWithManager(mgr ctrl.Manager, brokerReconciler *ActiveMQArtemisReconciler) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemis{}).
+		WithValidator(&activeMQArtemisValidator{isOnOpenShift: brokerReconciler.isOnOpenShift}).
+		Complete(); err != nil {
+		return err
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisAddress{}).
+		WithValidator(&activeMQArtemisAddressValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisSecurity{}).
+		WithValidator(&activeMQArtemisSecurityValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(&brokerPodDefaulter{client: mgr.GetClient()}).
+		Complete(); err != nil {
+		return err
+	}
+	// the overrides and the placement zones are applied on admission
+	brokerReconciler.podWebhookEnabled = true
+	// conversion only
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&brokerv1beta1.ActiveMQArtemisScaledown{}).
+		Complete()
+}
+
+type activeMQArtemisValidator struct {
+	isOnOpenShift bool
+}
+
+func (v *activeMQArtemisValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*brokerv1beta1.ActiveMQArtemis)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemis but got a %T", obj)
+	}
+	return admissionResult(v.validate(cr), nil)
+}
+
+func (v *activeMQArtemisValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCr, ok := oldObj.(*brokerv1beta1.ActiveMQArtemis)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemis but got a %T", oldObj)
+	}
+	cr, ok := newObj.(*brokerv1beta1.ActiveMQArtemis)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemis but got a %T", newObj)
+	}
+	// metadata only updates, like removing a finalizer, must not be blocked by a spec that was accepted before
+	if cr.DeletionTimestamp != nil || reflect.DeepEqual(oldCr.Spec, cr.Spec) {
+		return nil, nil
+	}
+	return admissionResult(v.validate(cr), v.validate(oldCr))
+}
+
+func (v *activeMQArtemisValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *activeMQArtemisValidator) validate(cr *brokerv1beta1.ActiveMQArtemis) error {
+	if condition, _ := specValidationCondition(cr, v.isOnOpenShift); condition.Status == metav1.ConditionFalse {
+		return errors.New(condition.Message)
+	}
+	return nil
+}
+
+// an error that the previous spec of an update also has is only a warning
+func admissionResult(err error, previous error) (admission.Warnings, error) {
+	if err == nil {
+		return nil, nil
+	}
+	if previous != nil && previous.Error() == err.Error() {
+		return admission.Warnings{err.Error()}, nil
+	}
+	return nil, err
+}
+
+type activeMQArtemisAddressValidator struct{}
+
+func (v *activeMQArtemisAddressValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*brokerv1beta1.ActiveMQArtemisAddress)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisAddress but got a %T", obj)
+	}
+	return admissionResult(validateAddress(cr), nil)
+}
+
+func (v *activeMQArtemisAddressValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCr, ok := oldObj.(*brokerv1beta1.ActiveMQArtemisAddress)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisAddress but got a %T", oldObj)
+	}
+	cr, ok := newObj.(*brokerv1beta1.ActiveMQArtemisAddress)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisAddress but got a %T", newObj)
+	}
+	if cr.DeletionTimestamp != nil || reflect.DeepEqual(oldCr.Spec, cr.Spec) {
+		return nil, nil
+	}
+	return admissionResult(validateAddress(cr), validateAddress(oldCr))
+}
+
+func (v *activeMQArtemisAddressValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateAddress(cr *brokerv1beta1.ActiveMQArtemisAddress) error {
+	if cr.Spec.AddressName == "" {
+		return errors.New(".Spec.AddressName is required")
+	}
+	if err := validateRoutingType(".Spec.RoutingType", cr.Spec.RoutingType); err != nil {
+		return err
+	}
+	if cr.Spec.QueueConfiguration != nil {
+		if err := validateRoutingType(".Spec.QueueConfiguration.RoutingType", cr.Spec.QueueConfiguration.RoutingType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRoutingType(path string, routingType *string) error {
+	if routingType == nil {
+		return nil
+	}
+	switch strings.ToUpper(*routingType) {
+	case "ANYCAST", "MULTICAST":
+		return nil
+	}
+	return fmt.Errorf("%s %q is invalid, it must be one of anycast or multicast", path, *routingType)
+}
+
+type activeMQArtemisSecurityValidator struct{}
+
+func (v *activeMQArtemisSecurityValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cr, ok := obj.(*brokerv1beta1.ActiveMQArtemisSecurity)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisSecurity but got a %T", obj)
+	}
+	return admissionResult(validateSecurity(cr), nil)
+}
+
+func (v *activeMQArtemisSecurityValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCr, ok := oldObj.(*brokerv1beta1.ActiveMQArtemisSecurity)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisSecurity but got a %T", oldObj)
+	}
+	cr, ok := newObj.(*brokerv1beta1.ActiveMQArtemisSecurity)
+	if !ok {
+		return nil, fmt.Errorf("expected an ActiveMQArtemisSecurity but got a %T", newObj)
+	}
+	if cr.DeletionTimestamp != nil || reflect.DeepEqual(oldCr.Spec, cr.Spec) {
+		return nil, nil
+	}
+	return admissionResult(validateSecurity(cr), validateSecurity(oldCr))
+}
+
+func (v *activeMQArtemisSecurityValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+var loginModuleFlags = []string{"required", "requisite", "sufficient", "optional"}
+
+func validateSecurity(cr *brokerv1beta1.ActiveMQArtemisSecurity) error {
+	modules := map[string]bool{}
+	addModule := func(name string) error {
+		if name == "" {
+			return errors.New("login module names are required")
+		}
+		if modules[name] {
+			return fmt.Errorf("login module name %s is not unique", name)
+		}
+		modules[name] = true
+		return nil
+	}
+
+	for _, module := range cr.Spec.LoginModules.PropertiesLoginModules {
+		if err := addModule(module.Name); err != nil {
+			return err
+		}
+		users := map[string]bool{}
+		for _, user := range module.Users {
+			if users[user.Name] {
+				return fmt.Errorf("user %s is not unique in login module %s", user.Name, module.Name)
+			}
+			users[user.Name] = true
+		}
+	}
+	for _, module := range cr.Spec.LoginModules.GuestLoginModules {
+		if err := addModule(module.Name); err != nil {
+			return err
+		}
+	}
+	for _, module := range cr.Spec.LoginModules.KeycloakLoginModules {
+		if err := addModule(module.Name); err != nil {
+			return err
+		}
+	}
+	for _, module := range cr.Spec.LoginModules.OIDCLoginModules {
+		if err := addModule(module.Name); err != nil {
+			return err
+		}
+	}
+
+	domains := []struct {
+		path   string
+		domain brokerv1beta1.BrokerDomainType
+	}{
+		{".Spec.SecurityDomains.BrokerDomain", cr.Spec.SecurityDomains.BrokerDomain},
+		{".Spec.SecurityDomains.ConsoleDomain", cr.Spec.SecurityDomains.ConsoleDomain},
+	}
+	for _, d := range domains {
+		for _, reference := range d.domain.LoginModules {
+			if reference.Name == nil || !modules[*reference.Name] {
+				name := ""
+				if reference.Name != nil {
+					name = *reference.Name
+				}
+				return fmt.Errorf("%s references login module %q that is not defined", d.path, name)
+			}
+			if reference.Flag != nil && !slices.Contains(loginModuleFlags, *reference.Flag) {
+				return fmt.Errorf("%s login module %s flag %q is invalid, it must be one of %s", d.path, *reference.Name, *reference.Flag, strings.Join(loginModuleFlags, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+type brokerPodDefaulter struct {
+	client rtclient.Client
+}
+
+// Default applies the override and the placement zone of its ordinal to a pod
+// of the StatefulSet of an ActiveMQArtemis. A pod it can not apply them to is
+// admitted as it is, the reconcile recreates it.
+func (d *brokerPodDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got a %T", obj)
+	}
+	crName, found := pod.Labels[selectors.LabelResourceKey]
+	if !found {
+		return nil
+	}
+	namespace := pod.Namespace
+	if request, err := admission.RequestFromContext(ctx); err == nil && request.Namespace != "" {
+		namespace = request.Namespace
+	}
+
+	// the pods of the broker groups have no ordinal overrides
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" || owner.Name != namer.CrToSS(crName) {
+		return nil
+	}
+	ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.Name, owner.Name+"-"))
+	if err != nil {
+		return nil
+	}
+
+	cr := &brokerv1beta1.ActiveMQArtemis{}
+	if err := d.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: crName}, cr); err != nil {
+		if !apierrors.IsNotFound(err) {
+			ctrl.Log.WithName("brokerPodDefaulter").V(1).Info("unable to read the ActiveMQArtemis of the pod, admitting it as it is", "pod", pod.Name, "error", err)
+		}
+		return nil
+	}
+	applyOrdinalOverrideToPod(cr, zonePairOf(cr, d.client), int32(ordinal), pod)
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newDuplicatePortBroker(name string) *brokerv1beta1.ActiveMQArtemis {
+	return &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Acceptors: []brokerv1beta1.AcceptorType{
+				{Name: "a", Port: 61617},
+				{Name: "b", Port: 61617},
+			},
+		},
+	}
+}
+
+func newSSLConsoleBroker(name string) *brokerv1beta1.ActiveMQArtemis {
+	return &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			Console:       brokerv1beta1.ConsoleType{Expose: true, SSLEnabled: true},
+			IngressDomain: "example.com",
+		},
+	}
+}
+
+func TestActiveMQArtemisValidator(t *testing.T) {
+	validator := &activeMQArtemisValidator{}
+
+	warnings, err := validator.ValidateCreate(context.TODO(), &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "valid", Namespace: "default"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	_, err = validator.ValidateCreate(context.TODO(), newDuplicatePortBroker("dup"))
+	assert.ErrorContains(t, err, `.Spec.Acceptors "b" and "a" contain a duplicate port 61617`)
+
+	// a missing secret may be created later, the cluster is left to the reconcile
+	warnings, err = validator.ValidateCreate(context.TODO(), newSSLConsoleBroker("ssl"))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// an unchanged spec is not validated again
+	_, err = validator.ValidateUpdate(context.TODO(), newDuplicatePortBroker("dup"), newDuplicatePortBroker("dup"))
+	assert.NoError(t, err)
+
+	_, err = validator.ValidateUpdate(context.TODO(), &brokerv1beta1.ActiveMQArtemis{}, newDuplicatePortBroker("dup"))
+	assert.Error(t, err)
+
+	// a CR stored with the failure can still be changed
+	changed := newDuplicatePortBroker("dup")
+	changed.Spec.AdminUser = "admin"
+	warnings, err = validator.ValidateUpdate(context.TODO(), newDuplicatePortBroker("dup"), changed)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "duplicate port")
+}
+
+func TestValidateAddress(t *testing.T) {
+	queue, anycast, invalid := "q", "anycast", "broadcast"
+
+	assert.NoError(t, validateAddress(&brokerv1beta1.ActiveMQArtemisAddress{
+		Spec: brokerv1beta1.ActiveMQArtemisAddressSpec{AddressName: "a", QueueName: &queue, RoutingType: &anycast},
+	}))
+	assert.ErrorContains(t, validateAddress(&brokerv1beta1.ActiveMQArtemisAddress{}), ".Spec.AddressName is required")
+	// the address takes the routing type of the queue
+	assert.NoError(t, validateAddress(&brokerv1beta1.ActiveMQArtemisAddress{
+		Spec: brokerv1beta1.ActiveMQArtemisAddressSpec{AddressName: "a", QueueName: &queue},
+	}))
+	assert.ErrorContains(t, validateAddress(&brokerv1beta1.ActiveMQArtemisAddress{
+		Spec: brokerv1beta1.ActiveMQArtemisAddressSpec{AddressName: "a", RoutingType: &invalid},
+	}), `.Spec.RoutingType "broadcast" is invalid`)
+	assert.ErrorContains(t, validateAddress(&brokerv1beta1.ActiveMQArtemisAddress{
+		Spec: brokerv1beta1.ActiveMQArtemisAddressSpec{
+			AddressName:        "a",
+			QueueConfiguration: &brokerv1beta1.QueueConfigurationType{RoutingType: &invalid},
+		},
+	}), ".Spec.QueueConfiguration.RoutingType")
+}
+
+func newSecurityWithDomainModule(module string, flag string) *brokerv1beta1.ActiveMQArtemisSecurity {
+	return &brokerv1beta1.ActiveMQArtemisSecurity{
+		ObjectMeta: metav1.ObjectMeta{Name: "sec", Namespace: "default"},
+		Spec: brokerv1beta1.ActiveMQArtemisSecuritySpec{
+			LoginModules: brokerv1beta1.LoginModulesType{
+				PropertiesLoginModules: []brokerv1beta1.PropertiesLoginModuleType{{
+					Name:  "props",
+					Users: []brokerv1beta1.UserType{{Name: "joe"}},
+				}},
+			},
+			SecurityDomains: brokerv1beta1.SecurityDomainsType{
+				BrokerDomain: brokerv1beta1.BrokerDomainType{
+					LoginModules: []brokerv1beta1.LoginModuleReferenceType{{Name: &module, Flag: &flag}},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	assert.NoError(t, validateSecurity(newSecurityWithDomainModule("props", "sufficient")))
+	assert.ErrorContains(t, validateSecurity(newSecurityWithDomainModule("missing", "sufficient")),
+		`.Spec.SecurityDomains.BrokerDomain references login module "missing" that is not defined`)
+	assert.ErrorContains(t, validateSecurity(newSecurityWithDomainModule("props", "maybe")), `flag "maybe" is invalid`)
+
+	cr := newSecurityWithDomainModule("props", "required")
+	cr.Spec.LoginModules.GuestLoginModules = []brokerv1beta1.GuestLoginModuleType{{Name: "props"}}
+	assert.ErrorContains(t, validateSecurity(cr), "login module name props is not unique")
+
+	cr = newSecurityWithDomainModule("props", "required")
+	cr.Spec.LoginModules.PropertiesLoginModules[0].Users = append(cr.Spec.LoginModules.PropertiesLoginModules[0].Users, brokerv1beta1.UserType{Name: "joe"})
+	assert.ErrorContains(t, validateSecurity(cr), "user joe is not unique in login module props")
+}
+
+func TestBrokerPodDefaulter(t *testing.T) {
+	webhookScheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(webhookScheme))
+	assert.NoError(t, brokerv1beta1.AddToScheme(webhookScheme))
+	cr := &brokerv1beta1.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "test"},
+		Spec: brokerv1beta1.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv1beta1.DeploymentPlanType{
+				OrdinalOverrides: []brokerv1beta1.OrdinalOverrideType{{Ordinal: 1, Labels: map[string]string{"tier": "large"}}},
+			},
+		},
+	}
+	defaulter := &brokerPodDefaulter{client: fake.NewClientBuilder().WithScheme(webhookScheme).WithObjects(cr).Build()}
+	isController := true
+	newPod := func(name string, owner string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "test",
+			Labels:          map[string]string{"ActiveMQArtemis": "pinned"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner, Controller: &isController}},
+		}}
+	}
+
+	overridden := newPod("pinned-ss-1", "pinned-ss")
+	overridden.Spec.Containers = []corev1.Container{{Name: "broker"}}
+	assert.NoError(t, defaulter.Default(context.TODO(), overridden))
+	assert.Equal(t, "large", overridden.Labels["tier"])
+	assert.Equal(t, "true", overridden.Annotations[ordinalOverridesAppliedAnnotation])
+
+	// the other ordinals and the broker groups are admitted as they are
+	for _, pod := range []*corev1.Pod{newPod("pinned-ss-0", "pinned-ss"), newPod("pinned-ss-large-1", "pinned-ss-large")} {
+		pod.Spec.Containers = []corev1.Container{{Name: "broker"}}
+		assert.NoError(t, defaulter.Default(context.TODO(), pod))
+		assert.Empty(t, pod.Labels["tier"])
+		assert.Empty(t, pod.Annotations)
+	}
+
+	// a pod of an ActiveMQArtemis that can not be read is admitted as it is
+	failing := &brokerPodDefaulter{client: fake.NewClientBuilder().WithScheme(webhookScheme).WithObjects(cr).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, client client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return errors.New("the api server is unavailable")
+		},
+	}).Build()}
+	unread := newPod("pinned-ss-1", "pinned-ss")
+	unread.Spec.Containers = []corev1.Container{{Name: "broker"}}
+	assert.NoError(t, failing.Default(context.TODO(), unread))
+	assert.Empty(t, unread.Annotations)
+}
@@ -47,13 +47,16 @@ import (
 	goruntime "runtime"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	routev1 "github.com/openshift/api/route/v1"
 
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/log"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/render"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/sdkk8sutil"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
 
 	brokerv1alpha1 "github.com/arkmq-org/activemq-artemis-operator/api/v1alpha1"
 	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
@@ -110,6 +113,14 @@ func (l *TraceLogWarnings) HandleWarningHeader(code int, agent string, message s
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == render.Command {
+		if err := render.Run(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var leaseDurationSeconds int64
@@ -129,6 +140,8 @@ func main() {
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	var tracingOpts tracing.Options
+	tracingOpts.BindFlags(flag.CommandLine)
 
 	os.Args = append(os.Args, strings.Split(os.Getenv("ARGS"), " ")...)
 
@@ -145,6 +158,13 @@ func main() {
 
 	printVersion()
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOpts, version.Version)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -192,6 +212,8 @@ func main() {
 	}
 
 	mgrOptions.Client.WarningHandler.SuppressWarnings = true
+	// the storage classes and the nodes are cluster scoped, a namespaced operator may not watch them
+	mgrOptions.Client.Cache = &client.CacheOptions{DisableFor: []client.Object{&storagev1.StorageClass{}, &corev1.Node{}}}
 	rest.SetDefaultWarningHandler(&TraceLogWarnings{Log: ctrl.Log})
 
 	isLocal, watchList := common.ResolveWatchNamespaceForManager(oprNamespace, watchNamespace)
@@ -297,6 +319,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	backupReconciler := controllers.NewActiveMQArtemisBackupReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("ActiveMQArtemisBackupReconciler"))
+
+	if err = backupReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ActiveMQArtemisBackup")
+		os.Exit(1)
+	}
+
+	dataExportReconciler := controllers.NewActiveMQArtemisDataExportReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("ActiveMQArtemisDataExportReconciler"))
+
+	if err = dataExportReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ActiveMQArtemisDataExport")
+		os.Exit(1)
+	}
+
+	diagnosticsReconciler := controllers.NewActiveMQArtemisDiagnosticsReconciler(
+		mgr.GetClient(),
+		mgr.GetScheme(),
+		ctrl.Log.WithName("ActiveMQArtemisDiagnosticsReconciler"))
+
+	if err = diagnosticsReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ActiveMQArtemisDiagnostics")
+		os.Exit(1)
+	}
+
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = controllers.SetupWebhooksWithManager(mgr, brokerReconciler); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -49,6 +49,7 @@ import (
 	"strings"
 
 	rbacutil "github.com/arkmq-org/activemq-artemis-operator/pkg/rbac"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/metrics"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/selectors"
 	"k8s.io/apimachinery/pkg/labels"
@@ -486,6 +487,7 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 					return err
 				}
 
+				metrics.DrainStarted(sts.Namespace, sts.Name, podName)
 				if !c.localOnly {
 					c.recorder.Event(sts, corev1.EventTypeNormal, SuccessCreate, fmt.Sprintf(MessageDrainPodCreated, podName, sts.Name))
 				}
@@ -583,6 +585,13 @@ func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev
 		defer c.cleanupDrainRBACResources(sts.Namespace)
 	}
 
+	if podPhase == corev1.PodSucceeded || podPhase == corev1.PodFailed {
+		metrics.DrainEnded(sts.Namespace, podName)
+	} else {
+		// a drain that was running before a restart of the operator
+		metrics.DrainStarted(sts.Namespace, sts.Name, podName)
+	}
+
 	switch podPhase {
 	case (corev1.PodSucceeded):
 		c.log.V(1).Info("Drain pod " + podName + " finished.")
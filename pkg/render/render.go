@@ -0,0 +1,220 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render implements the render command of the operator binary, it
+// prints the resources that the operator would deploy for an ActiveMQArtemis CR
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/arkmq-org/activemq-artemis-operator/controllers"
+	routev1 "github.com/openshift/api/route/v1"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+const Command = "render"
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(routev1.AddToScheme(scheme))
+	utilruntime.Must(brokerv1beta1.AddToScheme(scheme))
+}
+
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// Run parses the arguments that follow the command name and writes the rendered
+// resources to out as a yaml stream
+func Run(args []string, out io.Writer) error {
+	var files fileList
+	var namespace string
+	var openShift bool
+
+	flags := flag.NewFlagSet(Command, flag.ContinueOnError)
+	flags.Var(&files, "f", "A yaml file, or a directory of yaml files, with the ActiveMQArtemis CR and the Secrets and ConfigMaps it references. May be repeated.")
+	flags.StringVar(&namespace, "namespace", "default", "The namespace of the objects that do not have one.")
+	flags.BoolVar(&openShift, "openshift", false, "Render Routes rather than Ingresses, as on OpenShift.")
+	opts := zap.Options{Level: zapcore.ErrorLevel, StacktraceLevel: zapcore.PanicLevel}
+	opts.BindFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if len(files) == 0 {
+		return errors.New("no input, provide the CR with -f")
+	}
+	objects, err := readObjects(files)
+	if err != nil {
+		return err
+	}
+
+	var cr *brokerv1beta1.ActiveMQArtemis
+	var referenced []rtclient.Object
+	for _, obj := range objects {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		if artemis, ok := obj.(*brokerv1beta1.ActiveMQArtemis); ok {
+			if cr != nil {
+				return fmt.Errorf("found ActiveMQArtemis %s and %s, only one CR can be rendered at a time", cr.Name, artemis.Name)
+			}
+			cr = artemis
+		} else {
+			referenced = append(referenced, obj)
+		}
+	}
+	if cr == nil {
+		return errors.New("no ActiveMQArtemis found in the input")
+	}
+
+	rendered, err := controllers.RenderManifests(cr, referenced, scheme, openShift)
+	if err != nil {
+		return fmt.Errorf("unable to render %s, %v", cr.Name, err)
+	}
+	return write(rendered, out)
+}
+
+func readObjects(paths []string) ([]rtclient.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	var objects []rtclient.Object
+	for _, path := range paths {
+		files, err := filesOf(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+			for {
+				document, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, fmt.Errorf("unable to read %s, %v", file, err)
+				}
+				if len(strings.TrimSpace(string(document))) == 0 {
+					continue
+				}
+				decoded, _, err := decoder.Decode(document, nil, nil)
+				if err != nil {
+					return nil, fmt.Errorf("unable to decode %s, %v", file, err)
+				}
+				obj, ok := decoded.(rtclient.Object)
+				if !ok {
+					return nil, fmt.Errorf("unable to use %T from %s, it is not an object", decoded, file)
+				}
+				objects = append(objects, obj)
+			}
+		}
+	}
+	return objects, nil
+}
+
+func filesOf(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+func write(objects []rtclient.Object, out io.Writer) error {
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		// what the cluster fills in has no place in a manifest
+		delete(content, "status")
+		dropUnsetTimestamps(content)
+
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the pod and claim templates carry a null creationTimestamp too
+func dropUnsetTimestamps(content interface{}) {
+	switch value := content.(type) {
+	case map[string]interface{}:
+		if timestamp, found := value["creationTimestamp"]; found && timestamp == nil {
+			delete(value, "creationTimestamp")
+		}
+		for _, nested := range value {
+			dropUnsetTimestamps(nested)
+		}
+	case []interface{}:
+		for _, nested := range value {
+			dropUnsetTimestamps(nested)
+		}
+	}
+}
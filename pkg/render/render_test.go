@@ -0,0 +1,141 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const brokerYaml = `apiVersion: broker.amq.io/v1beta1
+kind: ActiveMQArtemis
+metadata:
+  name: rendered
+spec:
+  ingressDomain: example.com
+  brokerProperties:
+  - globalMaxSize=512m
+  acceptors:
+  - name: amqp
+    port: 5672
+    expose: true
+    sslEnabled: true
+    sslSecret: amqp-ssl
+`
+
+const sslSecretYaml = `apiVersion: v1
+kind: Secret
+metadata:
+  name: amqp-ssl
+stringData:
+  broker.ks: keystore
+  client.ts: truststore
+  keyStorePassword: password
+  trustStorePassword: password
+`
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broker.yaml", brokerYaml)
+	writeFile(t, dir, "secret.yaml", sslSecretYaml)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, Run([]string{"-f", dir, "-namespace", "gitops"}, out))
+
+	rendered := out.String()
+	for _, expected := range []string{
+		"kind: StatefulSet\nmetadata:\n  labels:\n    ActiveMQArtemis: rendered\n    application: rendered-app\n  name: rendered-ss\n  namespace: gitops\n",
+		"  name: rendered-props\n",
+		"    globalMaxSize=512m\n",
+		"  name: rendered-amqp-0-svc\n",
+		"kind: Ingress\n",
+		"  name: rendered-amqp-0-svc-ing\n",
+	} {
+		assert.Contains(t, rendered, expected)
+	}
+	// the input is referenced, not rendered
+	assert.NotContains(t, rendered, "name: amqp-ssl\n")
+	for _, clusterField := range []string{"ownerReferences", "resourceVersion", "uid:", "creationTimestamp", "status:"} {
+		assert.NotContains(t, rendered, clusterField)
+	}
+}
+
+func TestRunIsStableAndLeavesCredentialsOut(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broker.yaml", brokerYaml)
+	writeFile(t, dir, "secret.yaml", sslSecretYaml)
+
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	assert.NoError(t, Run([]string{"-f", dir}, first))
+	assert.NoError(t, Run([]string{"-f", dir}, second))
+	assert.Equal(t, first.String(), second.String())
+	// referenced from the pod template, but the generated values are not rendered
+	assert.Contains(t, first.String(), "              name: rendered-credentials-secret\n")
+	assert.NotContains(t, first.String(), "\n  name: rendered-credentials-secret\n")
+	assert.NotContains(t, first.String(), "  AMQ_PASSWORD:")
+}
+
+func TestRunWithoutReferencedSecret(t *testing.T) {
+	dir := t.TempDir()
+	broker := writeFile(t, dir, "broker.yaml", brokerYaml)
+
+	err := Run([]string{"-f", broker}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "unable to render rendered")
+	assert.ErrorContains(t, err, "amqp-ssl")
+}
+
+func TestRunWithoutCR(t *testing.T) {
+	dir := t.TempDir()
+	secret := writeFile(t, dir, "secret.yaml", sslSecretYaml)
+
+	assert.ErrorContains(t, Run([]string{"-f", secret}, &bytes.Buffer{}), "no ActiveMQArtemis found")
+	assert.ErrorContains(t, Run(nil, &bytes.Buffer{}), "no input")
+}
+
+func TestRunWithProvidedCredentialsIsStable(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broker.yaml", brokerYaml)
+	writeFile(t, dir, "secret.yaml", sslSecretYaml)
+	writeFile(t, dir, "credentials.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: rendered-credentials-secret
+stringData:
+  AMQ_USER: admin
+  AMQ_PASSWORD: admin
+  AMQ_CLUSTER_USER: cluster
+  AMQ_CLUSTER_PASSWORD: cluster
+`)
+
+	first, second := &bytes.Buffer{}, &bytes.Buffer{}
+	assert.NoError(t, Run([]string{"-f", dir}, first))
+	assert.NoError(t, Run([]string{"-f", dir}, second))
+	assert.Equal(t, first.String(), second.String())
+	// referenced from the pod template, but not rendered
+	assert.Contains(t, first.String(), "              name: rendered-credentials-secret\n")
+	assert.NotContains(t, first.String(), "\n  name: rendered-credentials-secret\n")
+}
@@ -9,6 +9,8 @@ import (
 const (
 	NameEnvVar             = "AMQ_NAME"
 	NameEnvVarDefaultValue = "amq-broker"
+	RoleEnvVar             = "AMQ_ROLE"
+	RoleEnvVarDefaultValue = "admin"
 )
 
 func ResolveBrokerNameFromEnvs(envs []corev1.EnvVar, defaultValue string) string {
@@ -22,12 +24,19 @@ func ResolveBrokerNameFromEnvs(envs []corev1.EnvVar, defaultValue string) string
 	return defaultValue
 }
 
+func ResolveBrokerRoleFromEnvs(envs []corev1.EnvVar, defaultValue string) string {
+	if envVar := Find(envs, RoleEnvVar); envVar != nil {
+		return envVar.Value
+	}
+	return defaultValue
+}
+
 func AddEnvVarForBasic(requireLogin string, journalType string, svcPingName string) []corev1.EnvVar {
 
 	envVarArray := []corev1.EnvVar{
 		{
-			Name:      "AMQ_ROLE",
-			Value:     "admin",
+			Name:      RoleEnvVar,
+			Value:     RoleEnvVarDefaultValue,
 			ValueFrom: nil,
 		},
 		{
@@ -1,6 +1,7 @@
 package artemis
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -84,6 +85,13 @@ func (artemis *Artemis) GetJolokia() jolokia.IJolokia {
 	return artemis.jolokia
 }
 
+// WithJolokia returns a copy that makes its calls through j
+func (artemis *Artemis) WithJolokia(j jolokia.IJolokia) *Artemis {
+	withJolokia := *artemis
+	withJolokia.jolokia = j
+	return &withJolokia
+}
+
 func (artemis *Artemis) Uptime() (*jolokia.ResponseData, error) {
 
 	uptimeURL := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\"/Uptime"
@@ -105,6 +113,60 @@ func (artemis *Artemis) GetStatus() (string, error) {
 	return resp.Value, nil
 }
 
+// GetAttribute reads an attribute of the broker, the value is formatted like the
+// jolokia response value
+func (artemis *Artemis) GetAttribute(attribute string) (string, error) {
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\"/" + attribute
+
+	resp, err := artemis.jolokia.Read(url)
+	if err != nil || resp == nil {
+		return "", err
+	}
+	if resp.Status != 200 {
+		return "", fmt.Errorf("unable to retrieve attribute %s %v", attribute, resp.Error)
+	}
+	return resp.Value, nil
+}
+
+// StopAcceptor stops an acceptor of the broker, it closes the connections of
+// its clients until it starts again
+func (artemis *Artemis) StopAcceptor(acceptorName string) (*jolokia.ResponseData, error) {
+	return artemis.execAcceptorOperation(acceptorName, "stop()")
+}
+
+func (artemis *Artemis) StartAcceptor(acceptorName string) (*jolokia.ResponseData, error) {
+	return artemis.execAcceptorOperation(acceptorName, "start()")
+}
+
+func (artemis *Artemis) execAcceptorOperation(acceptorName string, operation string) (*jolokia.ResponseData, error) {
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\",component=acceptors,name=\"" + acceptorName + "\""
+	jsonStr := `{ "type":"EXEC","mbean":"` + strings.Replace(url, "\"", "\\\"", -1) + `","operation":"` + operation + `","arguments":[]` + ` }`
+	data, err := artemis.jolokia.Exec(url, jsonStr)
+	if err == nil && data != nil && data.Status != 200 {
+		err = fmt.Errorf("unable to %s acceptor %s %v", strings.TrimSuffix(operation, "()"), acceptorName, data.Error)
+	}
+	return data, err
+}
+
+// DiagnosticCommand runs a diagnostic command of the JVM of the broker, like
+// threadPrint, the value is the output of the command
+func (artemis *Artemis) DiagnosticCommand(command string, arguments ...string) (string, error) {
+	url := "com.sun.management:type=DiagnosticCommand"
+	encoded, err := json.Marshal([][]string{append([]string{}, arguments...)})
+	if err != nil {
+		return "", err
+	}
+	jsonStr := `{ "type":"EXEC","mbean":"` + url + `","operation":"` + command + `([Ljava.lang.String;)","arguments":` + string(encoded) + ` }`
+	data, err := artemis.jolokia.Exec(url, jsonStr)
+	if err != nil || data == nil {
+		return "", err
+	}
+	if data.Status != 200 {
+		return "", fmt.Errorf("unable to run diagnostic command %s %v", command, data.Error)
+	}
+	return data.Value, nil
+}
+
 func (artemis *Artemis) CreateQueue(addressName string, queueName string, routingType string) (*jolokia.ResponseData, error) {
 
 	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\""
@@ -183,3 +245,26 @@ func (artemis *Artemis) DeleteAddress(addressName string) (*jolokia.ResponseData
 
 	return data, err
 }
+
+func (artemis *Artemis) AddUser(userName string, password string, roles string) (*jolokia.ResponseData, error) {
+
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\""
+	return artemis.execWithArguments(url, "addUser(java.lang.String,java.lang.String,java.lang.String,boolean)", userName, password, roles, true)
+}
+
+func (artemis *Artemis) RemoveUser(userName string) (*jolokia.ResponseData, error) {
+
+	url := "org.apache.activemq.artemis:broker=\"" + artemis.name + "\""
+	return artemis.execWithArguments(url, "removeUser(java.lang.String)", userName)
+}
+
+// the arguments are encoded as json values, user provided strings like
+// passwords can hold any character
+func (artemis *Artemis) execWithArguments(url string, operation string, arguments ...interface{}) (*jolokia.ResponseData, error) {
+	encoded, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, err
+	}
+	jsonStr := `{ "type":"EXEC","mbean":"` + strings.Replace(url, "\"", "\\\"", -1) + `","operation":"` + operation + `","arguments":` + string(encoded) + ` }`
+	return artemis.jolokia.Exec(url, jsonStr)
+}
@@ -1,6 +1,7 @@
 package artemis
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -109,6 +110,124 @@ func TestGetStatusWithNilStatus(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAddUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Exec(gomock.Eq("org.apache.activemq.artemis:broker=\"someBroker\""), gomock.Eq(`{ "type":"EXEC","mbean":"org.apache.activemq.artemis:broker=\"someBroker\"","operation":"addUser(java.lang.String,java.lang.String,java.lang.String,boolean)","arguments":["joe","secret","amq",true] }`)).
+		Return(&jolokia.ResponseData{Status: 200}, nil).
+		Times(1)
+	data, err := artemis.AddUser("joe", "secret", "amq")
+
+	assert.Equal(t, 200, data.Status)
+	assert.Nil(t, err)
+}
+
+func TestGetAttribute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Read(gomock.Eq("org.apache.activemq.artemis:broker=\"someBroker\"/DiskStoreUsage")).
+		DoAndReturn(func(_ string) (*jolokia.ResponseData, error) {
+			return &jolokia.ResponseData{
+				Status: 200,
+				Value:  "0.42",
+			}, nil
+		}).
+		AnyTimes()
+	data, err := artemis.GetAttribute("DiskStoreUsage")
+
+	assert.Equal(t, "0.42", data)
+	assert.Nil(t, err)
+}
+
+func TestStopAcceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Exec(gomock.Eq("org.apache.activemq.artemis:broker=\"someBroker\",component=acceptors,name=\"amqp\""), gomock.Any()).
+		DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+			assert.Contains(t, body, `"operation":"stop()"`)
+			return &jolokia.ResponseData{
+				Status: 404,
+				Error:  "javax.management.InstanceNotFoundException",
+			}, nil
+		}).
+		Times(1)
+	_, err := artemis.StopAcceptor("amqp")
+
+	assert.ErrorContains(t, err, "unable to stop acceptor amqp")
+}
+
+func TestDiagnosticCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Exec(gomock.Eq("com.sun.management:type=DiagnosticCommand"), gomock.Any()).
+		DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+			assert.Contains(t, body, `"operation":"threadPrint([Ljava.lang.String;)","arguments":[["-l"]]`)
+			return &jolokia.ResponseData{
+				Status: 200,
+				Value:  "Full thread dump",
+			}, nil
+		}).
+		Times(1)
+	output, err := artemis.DiagnosticCommand("threadPrint", "-l")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Full thread dump", output)
+}
+
+func TestAddUserEncodesArguments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	j := jolokia.NewMockIJolokia(ctrl)
+
+	artemis := createMockArtemis(j)
+
+	j.
+		EXPECT().
+		Exec(gomock.Eq("org.apache.activemq.artemis:broker=\"someBroker\""), gomock.Any()).
+		DoAndReturn(func(_ string, body string) (*jolokia.ResponseData, error) {
+			request := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(body), &request))
+			assert.Equal(t, "addUser(java.lang.String,java.lang.String,java.lang.String,boolean)", request["operation"])
+			assert.Equal(t, []interface{}{"joe", `pa"ss\word`, "amq", true}, request["arguments"])
+			return &jolokia.ResponseData{
+				Status: 200,
+			}, nil
+		}).
+		Times(1)
+	_, err := artemis.AddUser("joe", `pa"ss\word`, "amq")
+
+	assert.NoError(t, err)
+}
+
 func createMockArtemis(j jolokia.IJolokia) Artemis {
 	return Artemis{
 		ip:          "0.0.0.0",
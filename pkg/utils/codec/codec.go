@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/blowfish"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// the two way algorithm of org.apache.activemq.artemis.utils.DefaultSensitiveStringCodec,
+// blowfish in ECB mode with PKCS5 padding and the cipher text as a signed hex integer
+
+const (
+	DefaultKey = "clusterpassword"
+
+	// read by the broker when decoding with the default codec
+	KeyEnvVar = "ARTEMIS_DEFAULT_SENSITIVE_STRING_CODEC_KEY"
+
+	maskPrefix = "ENC("
+	maskSuffix = ")"
+)
+
+func IsMasked(value string) bool {
+	return strings.HasPrefix(value, maskPrefix) && strings.HasSuffix(value, maskSuffix)
+}
+
+// Mask returns value in the ENC() form understood by the broker, values that are
+// already masked are returned as is
+func Mask(value string, key string) (string, error) {
+	if IsMasked(value) {
+		return value, nil
+	}
+	encoded, err := Encode(value, key)
+	if err != nil {
+		return "", err
+	}
+	return maskPrefix + encoded + maskSuffix, nil
+}
+
+// Unmask returns the value of the ENC() form, values that are not masked are
+// returned as is
+func Unmask(value string, key string) (string, error) {
+	if !IsMasked(value) {
+		return value, nil
+	}
+	return Decode(strings.TrimSuffix(strings.TrimPrefix(value, maskPrefix), maskSuffix), key)
+}
+
+func Encode(secret string, key string) (string, error) {
+	cipher, err := newCipher(key)
+	if err != nil {
+		return "", err
+	}
+	plain := pad([]byte(secret))
+	encrypted := make([]byte, len(plain))
+	for i := 0; i < len(plain); i += blowfish.BlockSize {
+		cipher.Encrypt(encrypted[i:i+blowfish.BlockSize], plain[i:i+blowfish.BlockSize])
+	}
+
+	// java.math.BigInteger(byte[]) treats the bytes as two's complement
+	n := new(big.Int).SetBytes(encrypted)
+	if encrypted[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(encrypted)*8)))
+	}
+	return n.Text(16), nil
+}
+
+func Decode(encoded string, key string) (string, error) {
+	cipher, err := newCipher(key)
+	if err != nil {
+		return "", err
+	}
+	n, ok := new(big.Int).SetString(encoded, 16)
+	if !ok {
+		return "", errors.New("encoded value is not a hex integer")
+	}
+
+	length := (len(new(big.Int).Abs(n).Bytes()) + blowfish.BlockSize - 1) / blowfish.BlockSize * blowfish.BlockSize
+	if length == 0 {
+		length = blowfish.BlockSize
+	}
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), uint(length*8)))
+	}
+	encrypted := n.FillBytes(make([]byte, length))
+
+	plain := make([]byte, length)
+	for i := 0; i < length; i += blowfish.BlockSize {
+		cipher.Decrypt(plain[i:i+blowfish.BlockSize], encrypted[i:i+blowfish.BlockSize])
+	}
+	unpadded, err := unpad(plain)
+	if err != nil {
+		return "", err
+	}
+	return string(unpadded), nil
+}
+
+func newCipher(key string) (*blowfish.Cipher, error) {
+	if key == "" {
+		key = DefaultKey
+	}
+	return blowfish.NewCipher([]byte(key))
+}
+
+func pad(data []byte) []byte {
+	padding := blowfish.BlockSize - len(data)%blowfish.BlockSize
+	return append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+func unpad(data []byte) ([]byte, error) {
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > blowfish.BlockSize || padding > len(data) {
+		return nil, errors.New("invalid padding, wrong key or corrupt value")
+	}
+	return data[:len(data)-padding], nil
+}
+
+const (
+	oneWayIterations = 1024
+	oneWaySaltLength = 32
+	oneWayKeyLength  = 64
+)
+
+// MaskOneWay returns the PBKDF2 hash of password in the ENC() form the default codec
+// verifies for login module users, the salt is derived from saltSeed so that the
+// result is stable for the same input
+func MaskOneWay(password string, saltSeed []byte) string {
+	if IsMasked(password) {
+		return password
+	}
+	salt := sha256.Sum256(saltSeed)
+	hash := pbkdf2.Key([]byte(password), salt[:oneWaySaltLength], oneWayIterations, oneWayKeyLength, sha1.New)
+	return fmt.Sprintf("%s%d:%s:%s%s", maskPrefix, oneWayIterations, hex.EncodeToString(salt[:oneWaySaltLength]), hex.EncodeToString(hash), maskSuffix)
+}
+
+// VerifyOneWay checks password against a value produced by MaskOneWay
+func VerifyOneWay(password string, masked string) bool {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(masked, maskPrefix), maskSuffix), ":")
+	if len(parts) != 3 {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, pbkdf2.Key([]byte(password), salt, iterations, len(expected), sha1.New))
+}
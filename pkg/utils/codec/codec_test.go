@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, secret := range []string{"", "a", "password", "exactly8", "a much longer password with spaces", "ünïcödé"} {
+		for _, key := range []string{"", DefaultKey, "another-key"} {
+			encoded, err := Encode(secret, key)
+			assert.NoError(t, err)
+
+			decoded, err := Decode(encoded, key)
+			assert.NoError(t, err, "secret %q key %q", secret, key)
+			assert.Equal(t, secret, decoded)
+		}
+	}
+}
+
+func TestEncodeIsSignedHex(t *testing.T) {
+	var negative, positive bool
+	// the sign depends on the first cipher byte, cover both
+	for i := 0; i < 64 && !(negative && positive); i++ {
+		secret := strings.Repeat("x", i)
+		encoded, err := Encode(secret, DefaultKey)
+		assert.NoError(t, err)
+		if strings.HasPrefix(encoded, "-") {
+			negative = true
+		} else {
+			positive = true
+		}
+		decoded, err := Decode(encoded, DefaultKey)
+		assert.NoError(t, err)
+		assert.Equal(t, secret, decoded)
+	}
+	assert.True(t, negative)
+	assert.True(t, positive)
+}
+
+func TestMask(t *testing.T) {
+	masked, err := Mask("password", "")
+	assert.NoError(t, err)
+	assert.True(t, IsMasked(masked))
+
+	again, err := Mask(masked, "")
+	assert.NoError(t, err)
+	assert.Equal(t, masked, again)
+
+	decoded, err := Decode(strings.TrimSuffix(strings.TrimPrefix(masked, "ENC("), ")"), DefaultKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", decoded)
+
+	unmasked, err := Unmask(masked, DefaultKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", unmasked)
+
+	unmasked, err = Unmask("password", DefaultKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "password", unmasked)
+
+	_, err = Decode(strings.TrimSuffix(strings.TrimPrefix(masked, "ENC("), ")"), "wrong-key")
+	if err == nil {
+		decoded, _ = Decode(strings.TrimSuffix(strings.TrimPrefix(masked, "ENC("), ")"), "wrong-key")
+		assert.NotEqual(t, "password", decoded)
+	}
+}
+
+func TestMaskOneWay(t *testing.T) {
+	masked := MaskOneWay("password", []byte("ns/sec/props/joe"))
+	assert.True(t, IsMasked(masked))
+	assert.Equal(t, masked, MaskOneWay("password", []byte("ns/sec/props/joe")))
+	assert.NotEqual(t, masked, MaskOneWay("password", []byte("ns/sec/props/ann")))
+	assert.Equal(t, masked, MaskOneWay(masked, []byte("ns/sec/props/joe")))
+
+	// iterations:salt:hash as verified by the broker
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(masked, "ENC("), ")"), ":")
+	assert.Len(t, parts, 3)
+	assert.Equal(t, "1024", parts[0])
+	assert.Len(t, parts[1], 64)
+	assert.Len(t, parts[2], 128)
+
+	assert.True(t, VerifyOneWay("password", masked))
+	assert.False(t, VerifyOneWay("other", masked))
+	assert.False(t, VerifyOneWay("password", "ENC(garbage)"))
+}
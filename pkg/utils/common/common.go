@@ -71,7 +71,10 @@ const (
 	DefaultOperandCertSecretName    = "broker-cert"     // or can be prefixed with `cr.Name-`
 	DefaultPrometheusCertSecretName = "prometheus-cert" // or can be prefixed with `cr.Name-`
 
-	BlockReconcileAnnotation = "arkmq.org/block-reconcile"
+	BlockReconcileAnnotation    = "arkmq.org/block-reconcile"
+	RotateCredentialsAnnotation = "arkmq.org/rotate-credentials"
+	PlanAnnotation              = "arkmq.org/plan"
+	RolloutNowAnnotation        = "arkmq.org/rollout-now"
 )
 
 var lastStatusMap map[types.NamespacedName]olm.DeploymentStatus = make(map[types.NamespacedName]olm.DeploymentStatus)
@@ -358,6 +361,8 @@ func ProcessStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, na
 
 	podStatus := updatePodStatus(cr, client, namespacedName)
 
+	updateBrokerGroupStatus(cr, client, namer)
+
 	reqLogger.V(1).Info("PodStatus current..................", "info:", podStatus)
 	reqLogger.V(1).Info("Ready Count........................", "info:", len(podStatus.Ready))
 	reqLogger.V(1).Info("Stopped Count......................", "info:", len(podStatus.Stopped))
@@ -389,6 +394,45 @@ func UpdateBlockedStatus(cr *brokerv1beta1.ActiveMQArtemis, blocked bool) {
 	}
 }
 
+func PauseOf(cr *brokerv1beta1.ActiveMQArtemis) brokerv1beta1.PauseType {
+	if cr.Spec.Pause == nil {
+		return brokerv1beta1.PauseType{}
+	}
+	return *cr.Spec.Pause
+}
+
+// PausedParts lists the paused parts of a CR by their field names in spec.pause
+func PausedParts(cr *brokerv1beta1.ActiveMQArtemis) []string {
+	var parts []string
+	pause := PauseOf(cr)
+	if pause.StatefulSet {
+		parts = append(parts, "statefulSet")
+	}
+	if pause.Exposure {
+		parts = append(parts, "exposure")
+	}
+	if pause.BrokerProperties {
+		parts = append(parts, "brokerProperties")
+	}
+	if pause.AddressAndSecurity {
+		parts = append(parts, "addressAndSecurity")
+	}
+	return parts
+}
+
+func UpdatePausedStatus(cr *brokerv1beta1.ActiveMQArtemis) {
+	if parts := PausedParts(cr); len(parts) > 0 {
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:    brokerv1beta1.ReconcilePausedType,
+			Status:  metav1.ConditionTrue,
+			Reason:  brokerv1beta1.ReconcilePausedReason,
+			Message: "Reconcile paused for " + strings.Join(parts, ", "),
+		})
+	} else {
+		meta.RemoveStatusCondition(&cr.Status.Conditions, brokerv1beta1.ReconcilePausedType)
+	}
+}
+
 func updateVersionStatus(cr *brokerv1beta1.ActiveMQArtemis) {
 	cr.Status.Version.Image = ResolveImage(cr, BrokerImageKey)
 	cr.Status.Version.InitImage = ResolveImage(cr, InitImageKey)
@@ -488,7 +532,16 @@ func ValidateBrokerImageVersion(customResource *brokerv1beta1.ActiveMQArtemis) *
 }
 
 func updateScaleStatus(cr *brokerv1beta1.ActiveMQArtemis, namer Namers) {
-	labels := make([]string, 0, len(namer.LabelBuilder.Labels())+len(cr.Spec.DeploymentPlan.Labels))
+	labels := scaleLabels(cr, namer)
+	// the pods of the broker groups are not scaled by spec.deploymentPlan.size
+	if len(cr.Spec.DeploymentPlan.BrokerGroups) > 0 {
+		labels = append(labels, "!"+selectors.LabelBrokerGroupKey)
+	}
+	cr.Status.ScaleLabelSelector = strings.Join(labels[:], ",")
+}
+
+func scaleLabels(cr *brokerv1beta1.ActiveMQArtemis, namer Namers) []string {
+	labels := make([]string, 0, len(namer.LabelBuilder.Labels())+len(cr.Spec.DeploymentPlan.Labels)+1)
 	for k, v := range namer.LabelBuilder.Labels() {
 		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
 	}
@@ -496,7 +549,28 @@ func updateScaleStatus(cr *brokerv1beta1.ActiveMQArtemis, namer Namers) {
 		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
 	}
 	sort.Strings(labels)
-	cr.Status.ScaleLabelSelector = strings.Join(labels[:], ",")
+	return labels
+}
+
+func updateBrokerGroupStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namers Namers) {
+	var groups []brokerv1beta1.BrokerGroupStatus
+	for _, group := range cr.Spec.DeploymentPlan.BrokerGroups {
+		groupStatus := brokerv1beta1.BrokerGroupStatus{
+			Name:          group.Name,
+			StatefulSet:   namer.CrToGroupSS(cr.Name, group.Name),
+			Size:          GetBrokerGroupSize(&group),
+			LabelSelector: strings.Join(append(scaleLabels(cr, namers), selectors.LabelBrokerGroupKey+"="+group.Name), ","),
+		}
+		ss := &appsv1.StatefulSet{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: groupStatus.StatefulSet, Namespace: cr.Namespace}, ss); err == nil {
+			if ss.Spec.Replicas != nil {
+				groupStatus.Replicas = *ss.Spec.Replicas
+			}
+			groupStatus.PodStatus = statefulSetStatus(ss)
+		}
+		groups = append(groups, groupStatus)
+	}
+	cr.Status.BrokerGroups = groups
 }
 
 func updatePodStatus(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, namespacedName types.NamespacedName) olm.DeploymentStatus {
@@ -551,12 +625,19 @@ func getValidCondition(cr *brokerv1beta1.ActiveMQArtemis) metav1.Condition {
 }
 
 func GetSingleStatefulSetStatus(ss *appsv1.StatefulSet, cr *brokerv1beta1.ActiveMQArtemis) olm.DeploymentStatus {
+	cr.Status.DeploymentPlanSize = 0
+	if ss.Spec.Replicas != nil {
+		cr.Status.DeploymentPlanSize = *ss.Spec.Replicas
+	}
+	return statefulSetStatus(ss)
+}
+
+func statefulSetStatus(ss *appsv1.StatefulSet) olm.DeploymentStatus {
 	var ready, starting, stopped []string
 	var requestedCount = int32(0)
 	if ss.Spec.Replicas != nil {
 		requestedCount = *ss.Spec.Replicas
 	}
-	cr.Status.DeploymentPlanSize = requestedCount
 
 	targetCount := ss.Status.Replicas
 	readyCount := ss.Status.ReadyReplicas
@@ -625,6 +706,16 @@ func getDeploymentCondition(cr *brokerv1beta1.ActiveMQArtemis, client rtclient.C
 		}
 		return crReadyCondition
 	}
+	for _, group := range cr.Status.BrokerGroups {
+		if len(group.PodStatus.Ready) != int(group.Size) {
+			return metav1.Condition{
+				Type:    brokerv1beta1.DeployedConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  brokerv1beta1.DeployedConditionNotReadyReason,
+				Message: fmt.Sprintf("group %s: %d/%d pods ready", group.Name, len(group.PodStatus.Ready), group.Size),
+			}
+		}
+	}
 	return metav1.Condition{
 		Type:   brokerv1beta1.DeployedConditionType,
 		Reason: brokerv1beta1.DeployedConditionReadyReason,
@@ -676,6 +767,48 @@ func GetDeploymentSize(cr *brokerv1beta1.ActiveMQArtemis) int32 {
 	return *cr.Spec.DeploymentPlan.Size
 }
 
+func GetBrokerGroupSize(group *brokerv1beta1.BrokerGroupType) int32 {
+	if group.Size == nil {
+		return DefaultDeploymentSize
+	}
+	return *group.Size
+}
+
+// GroupOrdinal is the ordinal of a broker of a group, the pods of the group are
+// <statefulset of the deployment plan>-<group>-<ordinal> so that each group has
+// its own ordinals for the per ordinal broker properties, services and status
+func GroupOrdinal(group string, ordinal int32) string {
+	return group + "-" + strconv.Itoa(int(ordinal))
+}
+
+// BrokerOrdinals are the ordinals of the brokers of the spec, those of the
+// deployment plan followed by those of each group
+func BrokerOrdinals(cr *brokerv1beta1.ActiveMQArtemis) []string {
+	var ordinals []string
+	for ordinal := int32(0); ordinal < GetDeploymentSize(cr); ordinal++ {
+		ordinals = append(ordinals, strconv.Itoa(int(ordinal)))
+	}
+	for index := range cr.Spec.DeploymentPlan.BrokerGroups {
+		group := &cr.Spec.DeploymentPlan.BrokerGroups[index]
+		for ordinal := int32(0); ordinal < GetBrokerGroupSize(group); ordinal++ {
+			ordinals = append(ordinals, GroupOrdinal(group.Name, ordinal))
+		}
+	}
+	return ordinals
+}
+
+// DeployedGroupOrdinals are the ordinals of the brokers of the deployed
+// StatefulSet of each group, as reported in the status
+func DeployedGroupOrdinals(cr *brokerv1beta1.ActiveMQArtemis) []string {
+	var ordinals []string
+	for _, group := range cr.Status.BrokerGroups {
+		for ordinal := int32(0); ordinal < group.Replicas; ordinal++ {
+			ordinals = append(ordinals, GroupOrdinal(group.Name, ordinal))
+		}
+	}
+	return ordinals
+}
+
 func GetDeployedResources(instance *brokerv1beta1.ActiveMQArtemis, client rtclient.Client, onOpenShift bool) (map[reflect.Type][]rtclient.Object, error) {
 	log := ctrl.Log.WithName("util_common")
 	reader := read.New(client).WithNamespace(instance.Namespace).WithOwnerObject(instance)
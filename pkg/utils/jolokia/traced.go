@@ -0,0 +1,40 @@
+package jolokia
+
+import (
+	"context"
+
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TracedJolokia records a span for each call of the wrapped client. The
+// context is looked up on each call, so a call is a child of the span that is
+// current at that time rather than when the client was created.
+type TracedJolokia struct {
+	IJolokia
+	contextOf  func() context.Context
+	attributes []attribute.KeyValue
+}
+
+func Traced(contextOf func() context.Context, j IJolokia, attributes ...attribute.KeyValue) *TracedJolokia {
+	return &TracedJolokia{IJolokia: j, contextOf: contextOf, attributes: attributes}
+}
+
+func (t *TracedJolokia) Read(path string) (*ResponseData, error) {
+	_, span := tracing.Start(t.contextOf(), "jolokia.Read", t.attributesFor(path)...)
+	data, err := t.IJolokia.Read(path)
+	tracing.End(span, err)
+	return data, err
+}
+
+func (t *TracedJolokia) Exec(path, postJsonString string) (*ResponseData, error) {
+	_, span := tracing.Start(t.contextOf(), "jolokia.Exec", t.attributesFor(path)...)
+	data, err := t.IJolokia.Exec(path, postJsonString)
+	tracing.End(span, err)
+	return data, err
+}
+
+// the post body is left out, it can hold passwords
+func (t *TracedJolokia) attributesFor(path string) []attribute.KeyValue {
+	return append([]attribute.KeyValue{tracing.JolokiaPathKey.String(path)}, t.attributes...)
+}
@@ -27,8 +27,12 @@ import (
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/resources/secrets"
 	ss "github.com/arkmq-org/activemq-artemis-operator/pkg/resources/statefulsets"
 	mgmt "github.com/arkmq-org/activemq-artemis-operator/pkg/utils/artemis"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/codec"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/common"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/jolokia"
 	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/namer"
+	"github.com/arkmq-org/activemq-artemis-operator/pkg/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -43,6 +47,16 @@ type JkInfo struct {
 	Ordinal string
 }
 
+// Traced makes the jolokia calls of the brokers children of the span that is
+// current in contextOf, with the ordinal of the broker as an attribute
+func Traced(contextOf func() context.Context, jkInfos []*JkInfo, attributes ...attribute.KeyValue) []*JkInfo {
+	for _, jk := range jkInfos {
+		jkAttributes := append([]attribute.KeyValue{tracing.OrdinalKey.String(jk.Ordinal)}, attributes...)
+		jk.Artemis = jk.Artemis.WithJolokia(jolokia.Traced(contextOf, jk.Artemis.GetJolokia(), jkAttributes...))
+	}
+	return jkInfos
+}
+
 // Get all matching broker pod infos for a give resource
 // parameters:
 // resource: the address CR for which the broker pods are gathered
@@ -64,17 +78,17 @@ func GetBrokers(resource types.NamespacedName, ssInfos []ss.StatefulSetInfo, cli
 
 func GetMinimalJolokiaAgents(cr *v1beta1.ActiveMQArtemis, client rtclient.Client) []*JkInfo {
 	var artemisArray []*JkInfo = []*JkInfo{} // empty slice
-	var i int32 = 0
-	for i = 0; i < cr.Status.DeploymentPlanSize; i++ {
+	// the brokers of the groups follow those of the deployment plan
+	for _, ordinal := range append(ordinalsOf(cr.Status.DeploymentPlanSize), common.DeployedGroupOrdinals(cr)...) {
 
-		ordinalFqdn := common.OrdinalFQDNS(cr.Name, cr.Namespace, i)
+		ordinalFqdn := common.OrdinalStringFQDNS(cr.Name, cr.Namespace, ordinal)
 
 		artemis := mgmt.GetArtemisAgentForRestricted(client, environments.ResolveBrokerNameFromEnvs(cr.Spec.Env, cr.Name), ordinalFqdn)
 
 		jkInfo := JkInfo{
 			Artemis: artemis,
 			IP:      ordinalFqdn,
-			Ordinal: strconv.FormatInt(int64(i), 10),
+			Ordinal: ordinal,
 		}
 		artemisArray = append(artemisArray, &jkInfo)
 	}
@@ -84,18 +98,22 @@ func GetMinimalJolokiaAgents(cr *v1beta1.ActiveMQArtemis, client rtclient.Client
 
 // Get brokers Using DNS names in the namespace
 func GetBrokersFromDNS(crName string, namespace string, size int32, client rtclient.Client) []*JkInfo {
+	return GetBrokersOfOrdinals(crName, namespace, ordinalsOf(size), client)
+}
+
+// GetBrokersOfOrdinals gets the brokers of the pods <statefulset>-<ordinal>,
+// an ordinal of a group of brokers is <group>-<ordinal>
+func GetBrokersOfOrdinals(crName string, namespace string, ordinals []string, client rtclient.Client) []*JkInfo {
 	reqLogger := ctrl.Log.WithName("jolokia").WithValues("Request.Namespace", namespace, "Request.Name", crName)
 
 	var artemisArray []*JkInfo = []*JkInfo{} // empty slice
 
-	var i int32 = 0
-
-	for i = 0; i < size; i++ {
-		ordinalFqdn := common.OrdinalFQDNS(crName, namespace, i)
+	for _, ordinal := range ordinals {
+		ordinalFqdn := common.OrdinalStringFQDNS(crName, namespace, ordinal)
 
 		pod := &corev1.Pod{}
 		podNamespacedName := types.NamespacedName{
-			Name:      namer.CrToSS(crName) + "-" + strconv.Itoa(int(i)),
+			Name:      namer.CrToSS(crName) + "-" + ordinal,
 			Namespace: namespace,
 		}
 
@@ -121,7 +139,7 @@ func GetBrokersFromDNS(crName string, namespace string, size int32, client rtcli
 			jkInfo := JkInfo{
 				Artemis: artemis,
 				IP:      ordinalFqdn,
-				Ordinal: strconv.FormatInt(int64(i), 10),
+				Ordinal: ordinal,
 			}
 			artemisArray = append(artemisArray, &jkInfo)
 		}
@@ -131,6 +149,14 @@ func GetBrokersFromDNS(crName string, namespace string, size int32, client rtcli
 	return artemisArray
 }
 
+func ordinalsOf(size int32) []string {
+	var ordinals []string
+	for i := int32(0); i < size; i++ {
+		ordinals = append(ordinals, strconv.FormatInt(int64(i), 10))
+	}
+	return ordinals
+}
+
 func resolveJolokiaRequestParams(namespace string,
 	client rtclient.Client,
 	scheme *runtime.Scheme,
@@ -158,11 +184,19 @@ func resolveJolokiaRequestParams(namespace string,
 	jolokiaProtocol = "http"
 	if len(*containers) == 1 {
 		envVars := (*containers)[0].Env
+		var userSource *corev1.EnvVarSource
+		passwordDefined := false
+		codecKey := codec.DefaultKey
 		for _, oneVar := range envVars {
+			if oneVar.Name == codec.KeyEnvVar {
+				codecKey = getEnvVarValue(&oneVar, &podNamespacedName, client, nil)
+			}
 			if !userDefined && oneVar.Name == "AMQ_USER" {
 				jolokiaUser = getEnvVarValue(&oneVar, &podNamespacedName, client, nil)
+				userSource = oneVar.ValueFrom
 			} else if !userDefined && oneVar.Name == "AMQ_PASSWORD" {
 				jolokiaPassword = getEnvVarValue(&oneVar, &podNamespacedName, client, nil)
+				passwordDefined = true
 			} else if oneVar.Name == "AMQ_CONSOLE_ARGS" {
 				consoleArgs := getEnvVarValue(&oneVar, &podNamespacedName, client, nil)
 				if strings.Contains(consoleArgs, "--ssl") {
@@ -176,6 +210,22 @@ func resolveJolokiaRequestParams(namespace string,
 				}
 			}
 		}
+		// a password projected as a file is kept in the secret of the user
+		if !userDefined && !passwordDefined && userSource != nil && userSource.SecretKeyRef != nil {
+			passwordSource := &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: userSource.SecretKeyRef.LocalObjectReference,
+					Key:                  "AMQ_PASSWORD",
+				},
+			}
+			jolokiaPassword = getEnvVarValueFromSecret("AMQ_PASSWORD", passwordSource, &podNamespacedName, client, nil)
+		}
+		// the generated credentials are masked with spec.passwordCodec, the broker decodes them
+		if !userDefined {
+			if unmasked, err := codec.Unmask(jolokiaPassword, codecKey); err == nil {
+				jolokiaPassword = unmasked
+			}
+		}
 	}
 
 	return jolokiaUser, jolokiaPassword, jolokiaProtocol
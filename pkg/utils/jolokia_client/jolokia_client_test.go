@@ -304,4 +304,25 @@ var _ = Describe("JolokiaClient", func() {
 			})
 		})
 	})
+
+	Describe("GetBrokersOfOrdinals", func() {
+		It("should return the brokers of a group by their group ordinal", func() {
+			objs := []client.Object{
+				&corev1.Pod{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "broker-ss-large-1",
+						Namespace: "some-ns",
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "broker-container"}},
+					},
+				},
+			}
+			client := fake.NewClientBuilder().WithObjects(objs...).Build()
+			infos := jolokia_client.GetBrokersOfOrdinals("broker", "some-ns", []string{"large-0", "large-1"}, client)
+			Expect(infos).Should(HaveLen(1))
+			Expect(infos[0].Ordinal).To(Equal("large-1"))
+			Expect(infos[0].IP).To(HavePrefix("broker-ss-large-1.broker-hdls-svc.some-ns.svc."))
+		})
+	})
 })
@@ -0,0 +1,206 @@
+// Package metrics holds the operator's own prometheus metrics. They are
+// registered with the controller-runtime registry, so they are served on the
+// metrics-bind-address endpoint next to the default controller metrics.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace = "arkmq"
+	subsystem = "operator"
+
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of a reconcile of a CR, by controller and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"controller", "namespace", "name", "outcome"})
+
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "reconcile_total",
+		Help:      "Number of reconciles of a CR, by controller and outcome.",
+	}, []string{"controller", "namespace", "name", "outcome"})
+
+	resources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "resources",
+		Help:      "Number of resources of a broker CR at the last reconcile, requested by the spec or deployed.",
+	}, []string{"namespace", "name", "state"})
+
+	jolokiaStatusCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "jolokia_status_check_failures_total",
+		Help:      "Number of failed broker status reads over Jolokia, by broker ordinal.",
+	}, []string{"namespace", "name", "ordinal"})
+
+	validationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "validation_failures_total",
+		Help:      "Number of reconciles that found the spec of a broker CR invalid, by reason.",
+	}, []string{"namespace", "name", "reason"})
+
+	drainsInProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "drains_in_progress",
+		Help:      "Number of drain pods that are moving messages off a scaled down broker.",
+	}, []string{"namespace", "statefulset"})
+
+	brokerPropertiesSync = newSyncCollector(prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "broker_properties_seconds_since_sync"),
+		"Seconds since the brokers last reported the current broker properties, 0 while they are in sync.",
+		[]string{"namespace", "name"}, nil))
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		reconcileDuration,
+		reconcileTotal,
+		resources,
+		jolokiaStatusCheckFailures,
+		validationFailures,
+		drainsInProgress,
+		brokerPropertiesSync,
+	)
+}
+
+// InstrumentReconciler times every reconcile of inner and counts its outcome
+func InstrumentReconciler(controller string, inner reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		start := time.Now()
+		result, err := inner.Reconcile(ctx, request)
+		outcome := OutcomeSuccess
+		if err != nil {
+			outcome = OutcomeError
+		}
+		reconcileDuration.WithLabelValues(controller, request.Namespace, request.Name, outcome).Observe(time.Since(start).Seconds())
+		reconcileTotal.WithLabelValues(controller, request.Namespace, request.Name, outcome).Inc()
+		return result, err
+	})
+}
+
+func SetResources(cr types.NamespacedName, requested int, deployed int) {
+	resources.WithLabelValues(cr.Namespace, cr.Name, "requested").Set(float64(requested))
+	resources.WithLabelValues(cr.Namespace, cr.Name, "deployed").Set(float64(deployed))
+}
+
+func JolokiaStatusCheckFailed(cr types.NamespacedName, ordinal string) {
+	jolokiaStatusCheckFailures.WithLabelValues(cr.Namespace, cr.Name, ordinal).Inc()
+}
+
+func ValidationFailed(cr types.NamespacedName, reason string) {
+	validationFailures.WithLabelValues(cr.Namespace, cr.Name, reason).Inc()
+}
+
+func BrokerPropertiesSynced(cr types.NamespacedName, inSync bool) {
+	brokerPropertiesSync.update(cr, inSync, time.Now())
+}
+
+var drains = struct {
+	sync.Mutex
+	pods map[types.NamespacedName]string
+}{pods: map[types.NamespacedName]string{}}
+
+// DrainStarted and DrainEnded track a drain pod, repeated calls for the same
+// pod are counted once
+func DrainStarted(namespace string, statefulSet string, pod string) {
+	drains.Lock()
+	defer drains.Unlock()
+	key := types.NamespacedName{Namespace: namespace, Name: pod}
+	if _, tracked := drains.pods[key]; !tracked {
+		drains.pods[key] = statefulSet
+		drainsInProgress.WithLabelValues(namespace, statefulSet).Inc()
+	}
+}
+
+func DrainEnded(namespace string, pod string) {
+	drains.Lock()
+	defer drains.Unlock()
+	key := types.NamespacedName{Namespace: namespace, Name: pod}
+	if statefulSet, tracked := drains.pods[key]; tracked {
+		delete(drains.pods, key)
+		drainsInProgress.WithLabelValues(namespace, statefulSet).Dec()
+	}
+}
+
+// Forget drops the state of a deleted broker CR, the reconcile counters are
+// kept as they describe the work of the operator
+func Forget(cr types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": cr.Namespace, "name": cr.Name}
+	resources.DeletePartialMatch(labels)
+	jolokiaStatusCheckFailures.DeletePartialMatch(labels)
+	validationFailures.DeletePartialMatch(labels)
+	brokerPropertiesSync.forget(cr)
+}
+
+// the time since the last sync grows between reconciles, it is computed on scrape
+type syncCollector struct {
+	desc  *prometheus.Desc
+	mutex sync.Mutex
+	state map[types.NamespacedName]syncState
+}
+
+type syncState struct {
+	inSync bool
+	// the last time in sync, or the first time out of sync when it never was
+	since time.Time
+}
+
+func newSyncCollector(desc *prometheus.Desc) *syncCollector {
+	return &syncCollector{desc: desc, state: map[types.NamespacedName]syncState{}}
+}
+
+func (c *syncCollector) update(cr types.NamespacedName, inSync bool, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	current, tracked := c.state[cr]
+	if inSync {
+		c.state[cr] = syncState{inSync: true, since: now}
+	} else if !tracked {
+		c.state[cr] = syncState{since: now}
+	} else if current.inSync {
+		c.state[cr] = syncState{since: current.since}
+	}
+}
+
+func (c *syncCollector) forget(cr types.NamespacedName) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.state, cr)
+}
+
+func (c *syncCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c *syncCollector) Collect(metrics chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for cr, state := range c.state {
+		seconds := 0.0
+		if !state.inSync {
+			seconds = time.Since(state.since).Seconds()
+		}
+		metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, seconds, cr.Namespace, cr.Name)
+	}
+}
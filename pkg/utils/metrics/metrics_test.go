@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestInstrumentReconciler(t *testing.T) {
+	var fail bool
+	instrumented := InstrumentReconciler("Test", reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		if fail {
+			return reconcile.Result{}, errors.New("failed")
+		}
+		return reconcile.Result{}, nil
+	}))
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "instrumented"}}
+
+	_, err := instrumented.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	fail = true
+	_, err = instrumented.Reconcile(context.TODO(), request)
+	assert.Error(t, err)
+	_, err = instrumented.Reconcile(context.TODO(), request)
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(reconcileTotal.WithLabelValues("Test", "test", "instrumented", OutcomeSuccess)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(reconcileTotal.WithLabelValues("Test", "test", "instrumented", OutcomeError)))
+}
+
+func TestDrainsInProgress(t *testing.T) {
+	DrainStarted("test", "broker-ss", "broker-ss-1")
+	DrainStarted("test", "broker-ss", "broker-ss-1")
+	DrainStarted("test", "broker-ss", "broker-ss-2")
+	assert.Equal(t, 2.0, testutil.ToFloat64(drainsInProgress.WithLabelValues("test", "broker-ss")))
+
+	DrainEnded("test", "broker-ss-1")
+	DrainEnded("test", "broker-ss-1")
+	assert.Equal(t, 1.0, testutil.ToFloat64(drainsInProgress.WithLabelValues("test", "broker-ss")))
+}
+
+func TestBrokerPropertiesSync(t *testing.T) {
+	collector := newSyncCollector(brokerPropertiesSync.desc)
+	cr := types.NamespacedName{Namespace: "test", Name: "synced"}
+
+	collector.update(cr, true, time.Now())
+	assert.Equal(t, 0.0, testutil.ToFloat64(collector))
+
+	// the time since the last sync is kept while out of sync
+	lastSync := time.Now().Add(-time.Minute)
+	collector.update(cr, true, lastSync)
+	collector.update(cr, false, time.Now())
+	collector.update(cr, false, time.Now())
+	assert.InDelta(t, 60, testutil.ToFloat64(collector), 5)
+
+	collector.forget(cr)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+func TestForget(t *testing.T) {
+	cr := types.NamespacedName{Namespace: "test", Name: "forgotten"}
+	SetResources(cr, 5, 4)
+	ValidationFailed(cr, "SomeReason")
+	JolokiaStatusCheckFailed(cr, "0")
+
+	expected := `
+# HELP arkmq_operator_resources Number of resources of a broker CR at the last reconcile, requested by the spec or deployed.
+# TYPE arkmq_operator_resources gauge
+arkmq_operator_resources{name="forgotten",namespace="test",state="deployed"} 4
+arkmq_operator_resources{name="forgotten",namespace="test",state="requested"} 5
+`
+	assert.NoError(t, testutil.CollectAndCompare(resources, strings.NewReader(expected)))
+
+	Forget(cr)
+	assert.Equal(t, 0, testutil.CollectAndCount(resources))
+	assert.Equal(t, 0, testutil.CollectAndCount(validationFailures))
+	assert.Equal(t, 0, testutil.CollectAndCount(jolokiaStatusCheckFailures))
+}
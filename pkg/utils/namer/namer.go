@@ -66,10 +66,22 @@ func CrToSS(crName string) string {
 	return crName + "-ss"
 }
 
+// CrToGroupSS is the name of the StatefulSet of a group of brokers
+func CrToGroupSS(crName string, group string) string {
+	return CrToSS(crName) + "-" + group
+}
+
 func CrToSSOrdinal(crName string, ordinal int) string {
 	return CrToSS(crName) + "-" + strconv.Itoa(ordinal)
 }
 
+// CrToJournalClaim is the name of the journal volume claim of a broker, the
+// StatefulSet names it <template>-<statefulset>-<ordinal> and the template has
+// the name of the CR
+func CrToJournalClaim(crName string, ordinal int) string {
+	return crName + "-" + CrToSSOrdinal(crName, ordinal)
+}
+
 func SSToCr(ssName string) string {
 	return strings.TrimSuffix(ssName, "-ss")
 }
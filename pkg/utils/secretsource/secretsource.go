@@ -0,0 +1,213 @@
+package secretsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	rtclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// a Secret consents to being read from other namespaces by listing them, or *, in this annotation
+	SharedWithNamespacesAnnotation = "arkmq.org/secret-source-namespaces"
+
+	FileRootEnvVar      = "SECRET_SOURCE_FILE_ROOT"
+	FileRootDefaultPath = "/etc/arkmq/secret-sources"
+)
+
+var ErrNoSource = errors.New("no source configured")
+
+// Source resolves values from one kind of external secret store
+type Source interface {
+	Handles(ref *brokerv1beta1.SecretSourceType) bool
+	Resolve(ctx context.Context, ref *brokerv1beta1.SecretSourceType, namespace string) (string, error)
+}
+
+type Resolver struct {
+	sources []Source
+}
+
+// a resolver for the secret, file and vault sources, more can be registered
+func NewResolver(client rtclient.Client) *Resolver {
+	return &Resolver{
+		sources: []Source{
+			&SecretSource{Client: client},
+			&FileSource{Root: fileRoot()},
+			&VaultSource{Client: client, HttpClient: &http.Client{Timeout: 10 * time.Second}},
+		},
+	}
+}
+
+func (r *Resolver) Register(source Source) {
+	r.sources = append(r.sources, source)
+}
+
+// Resolve the value of ref on behalf of a CR in namespace
+func (r *Resolver) Resolve(ctx context.Context, ref *brokerv1beta1.SecretSourceType, namespace string) (string, error) {
+	source, err := r.sourceOf(ref)
+	if err != nil {
+		return "", err
+	}
+	return source.Resolve(ctx, ref, namespace)
+}
+
+// Validate that ref configures a single source, without reaching out to it
+func (r *Resolver) Validate(ref *brokerv1beta1.SecretSourceType) error {
+	_, err := r.sourceOf(ref)
+	return err
+}
+
+func (r *Resolver) sourceOf(ref *brokerv1beta1.SecretSourceType) (Source, error) {
+	if ref == nil {
+		return nil, ErrNoSource
+	}
+	var source Source
+	for _, candidate := range r.sources {
+		if candidate.Handles(ref) {
+			if source != nil {
+				return nil, errors.New("more than one source configured")
+			}
+			source = candidate
+		}
+	}
+	if source == nil {
+		return nil, ErrNoSource
+	}
+	return source, nil
+}
+
+func fileRoot() string {
+	if root, defined := os.LookupEnv(FileRootEnvVar); defined && root != "" {
+		return root
+	}
+	return FileRootDefaultPath
+}
+
+type SecretSource struct {
+	Client rtclient.Client
+}
+
+func (s *SecretSource) Handles(ref *brokerv1beta1.SecretSourceType) bool {
+	return ref.Secret != nil
+}
+
+func (s *SecretSource) Resolve(ctx context.Context, ref *brokerv1beta1.SecretSourceType, namespace string) (string, error) {
+	secretNamespace := ref.Secret.Namespace
+	if secretNamespace == "" {
+		secretNamespace = namespace
+	}
+	secret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: ref.Secret.Name, Namespace: secretNamespace}, secret); err != nil {
+		return "", fmt.Errorf("unable to retrieve secret %s/%s, %v", secretNamespace, ref.Secret.Name, err)
+	}
+	if secretNamespace != namespace && !sharedWith(secret, namespace) {
+		return "", fmt.Errorf("secret %s/%s is not shared with namespace %s through the %s annotation", secretNamespace, ref.Secret.Name, namespace, SharedWithNamespacesAnnotation)
+	}
+	value, found := secret.Data[ref.Secret.Key]
+	if !found {
+		return "", fmt.Errorf("secret %s/%s has no key %s", secretNamespace, ref.Secret.Name, ref.Secret.Key)
+	}
+	return string(value), nil
+}
+
+func sharedWith(secret *corev1.Secret, namespace string) bool {
+	for _, shared := range strings.Split(secret.Annotations[SharedWithNamespacesAnnotation], ",") {
+		shared = strings.TrimSpace(shared)
+		if shared == "*" || shared == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+type FileSource struct {
+	Root string
+}
+
+func (s *FileSource) Handles(ref *brokerv1beta1.SecretSourceType) bool {
+	return ref.File != nil
+}
+
+func (s *FileSource) Resolve(ctx context.Context, ref *brokerv1beta1.SecretSourceType, namespace string) (string, error) {
+	// only files below the root can be read, anything else in the operator pod is off limits
+	path := filepath.Join(s.Root, filepath.Clean("/"+ref.File.Path))
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret source file %s, %v", ref.File.Path, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+type VaultSource struct {
+	Client     rtclient.Client
+	HttpClient *http.Client
+}
+
+func (s *VaultSource) Handles(ref *brokerv1beta1.SecretSourceType) bool {
+	return ref.Vault != nil
+}
+
+func (s *VaultSource) Resolve(ctx context.Context, ref *brokerv1beta1.SecretSourceType, namespace string) (string, error) {
+	vault := ref.Vault
+
+	tokenSecret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: vault.TokenSecretRef.Name, Namespace: namespace}, tokenSecret); err != nil {
+		return "", fmt.Errorf("unable to retrieve vault token secret %s, %v", vault.TokenSecretRef.Name, err)
+	}
+	token, found := tokenSecret.Data[vault.TokenSecretRef.Key]
+	if !found {
+		return "", fmt.Errorf("vault token secret %s has no key %s", vault.TokenSecretRef.Name, vault.TokenSecretRef.Key)
+	}
+
+	url := strings.TrimSuffix(vault.Address, "/") + "/v1/" + strings.TrimPrefix(vault.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	res, err := s.HttpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to read vault secret %s, %v", vault.Path, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to read vault secret %s, status %d", vault.Path, res.StatusCode)
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("unable to parse vault secret %s, %v", vault.Path, err)
+	}
+	data := response.Data
+	// version 2 of the key value engine nests the values with the metadata
+	if nested, isV2 := data["data"].(map[string]interface{}); isV2 {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+	value, found := data[vault.Key]
+	if !found {
+		return "", fmt.Errorf("vault secret %s has no key %s", vault.Path, vault.Key)
+	}
+	if str, isString := value.(string); isString {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
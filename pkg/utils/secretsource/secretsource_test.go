@@ -0,0 +1,152 @@
+package secretsource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	brokerv1beta1 "github.com/arkmq-org/activemq-artemis-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSecret(namespace string, name string, annotations map[string]string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+		Data:       map[string][]byte{},
+	}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func TestSecretSource(t *testing.T) {
+	client := fake.NewClientBuilder().WithObjects(
+		newSecret("brokers", "local", nil, map[string]string{"password": "local-value"}),
+		newSecret("vault", "private", nil, map[string]string{"password": "private-value"}),
+		newSecret("vault", "shared", map[string]string{SharedWithNamespacesAnnotation: "other, brokers"}, map[string]string{"password": "shared-value"}),
+	).Build()
+	resolver := NewResolver(client)
+
+	value, err := resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Name: "local", Key: "password"},
+	}, "brokers")
+	assert.NoError(t, err)
+	assert.Equal(t, "local-value", value)
+
+	_, err = resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Namespace: "vault", Name: "private", Key: "password"},
+	}, "brokers")
+	assert.ErrorContains(t, err, "not shared with namespace brokers")
+
+	value, err = resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Namespace: "vault", Name: "shared", Key: "password"},
+	}, "brokers")
+	assert.NoError(t, err)
+	assert.Equal(t, "shared-value", value)
+
+	_, err = resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Name: "local", Key: "missing"},
+	}, "brokers")
+	assert.ErrorContains(t, err, "has no key missing")
+}
+
+func TestFileSourceStaysBelowRoot(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "broker"), 0700))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "broker", "password"), []byte("file-value\n"), 0600))
+
+	outside := filepath.Join(filepath.Dir(root), "outside")
+	assert.NoError(t, os.WriteFile(outside, []byte("outside-value"), 0600))
+	defer os.Remove(outside)
+
+	source := &FileSource{Root: root}
+
+	value, err := source.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{File: &brokerv1beta1.SecretSourceFileType{Path: "broker/password"}}, "brokers")
+	assert.NoError(t, err)
+	assert.Equal(t, "file-value", value)
+
+	_, err = source.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{File: &brokerv1beta1.SecretSourceFileType{Path: "../outside"}}, "brokers")
+	assert.Error(t, err)
+}
+
+func TestVaultSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/broker":
+			w.Write([]byte(`{"data":{"data":{"password":"v2-value"},"metadata":{"version":3}}}`))
+		case "/v1/kv/broker":
+			w.Write([]byte(`{"data":{"password":"v1-value"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := fake.NewClientBuilder().WithObjects(
+		newSecret("brokers", "vault-token", nil, map[string]string{"token": "s.token\n"}),
+	).Build()
+	resolver := NewResolver(client)
+
+	vaultRef := func(path string) *brokerv1beta1.SecretSourceType {
+		return &brokerv1beta1.SecretSourceType{
+			Vault: &brokerv1beta1.SecretSourceVaultType{
+				Address: server.URL,
+				Path:    path,
+				Key:     "password",
+				TokenSecretRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "vault-token"},
+					Key:                  "token",
+				},
+			},
+		}
+	}
+
+	value, err := resolver.Resolve(context.TODO(), vaultRef("secret/data/broker"), "brokers")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2-value", value)
+
+	value, err = resolver.Resolve(context.TODO(), vaultRef("kv/broker"), "brokers")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1-value", value)
+
+	_, err = resolver.Resolve(context.TODO(), vaultRef("secret/data/missing"), "brokers")
+	assert.ErrorContains(t, err, "status 404")
+}
+
+func TestResolverRequiresSingleSource(t *testing.T) {
+	resolver := NewResolver(fake.NewClientBuilder().Build())
+
+	_, err := resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{}, "brokers")
+	assert.ErrorIs(t, err, ErrNoSource)
+
+	_, err = resolver.Resolve(context.TODO(), &brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Name: "a", Key: "b"},
+		File:   &brokerv1beta1.SecretSourceFileType{Path: "a"},
+	}, "brokers")
+	assert.ErrorContains(t, err, "more than one source")
+}
+
+func TestResolverValidateDoesNotResolve(t *testing.T) {
+	// a client without objects, the secret is not read
+	resolver := NewResolver(fake.NewClientBuilder().Build())
+
+	assert.NoError(t, resolver.Validate(&brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Name: "missing", Key: "b"},
+	}))
+	assert.ErrorIs(t, resolver.Validate(&brokerv1beta1.SecretSourceType{}), ErrNoSource)
+	assert.ErrorContains(t, resolver.Validate(&brokerv1beta1.SecretSourceType{
+		Secret: &brokerv1beta1.SecretSourceSecretType{Name: "a", Key: "b"},
+		Vault:  &brokerv1beta1.SecretSourceVaultType{Address: "http://vault"},
+	}), "more than one source")
+}
@@ -3,6 +3,8 @@ package selectors
 const (
 	LabelAppKey      = "application"
 	LabelResourceKey = "ActiveMQArtemis"
+	// the group of spec.deploymentPlan.brokerGroups of a broker pod
+	LabelBrokerGroupKey = "arkmq.org/broker-group"
 )
 
 type LabelerInterface interface {
@@ -0,0 +1,122 @@
+// Package tracing sets up the optional OpenTelemetry tracing of the operator.
+// Spans go to an OTLP/HTTP collector when one is configured, otherwise the
+// global no-op tracer provider drops them at no cost.
+package tracing
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	instrumentationName = "github.com/arkmq-org/activemq-artemis-operator"
+	serviceName         = "activemq-artemis-operator"
+
+	ControllerKey   = attribute.Key("arkmq.controller")
+	CRNamespaceKey  = attribute.Key("arkmq.cr.namespace")
+	CRNameKey       = attribute.Key("arkmq.cr.name")
+	OrdinalKey      = attribute.Key("arkmq.broker.ordinal")
+	ResourceKindKey = attribute.Key("arkmq.resource.kind")
+	ResourceNameKey = attribute.Key("arkmq.resource.name")
+	JolokiaPathKey  = attribute.Key("arkmq.jolokia.path")
+)
+
+// the standard variables of the exporter, either enables tracing without the flag
+var endpointEnvVars = []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}
+
+type Options struct {
+	// host:port of an OTLP/HTTP collector
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "otlp-endpoint", "", "The host:port of an OTLP/HTTP collector to send traces to. Tracing is off unless this or OTEL_EXPORTER_OTLP_ENDPOINT is set.")
+	fs.BoolVar(&o.Insecure, "otlp-insecure", false, "Send traces over plain http rather than https.")
+	fs.Float64Var(&o.SampleRatio, "trace-sample-ratio", 1, "The ratio of reconciles to trace, from 0 to 1.")
+}
+
+func (o *Options) Enabled() bool {
+	if o.Endpoint != "" {
+		return true
+	}
+	for _, envVar := range endpointEnvVars {
+		if os.Getenv(envVar) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Setup installs the global tracer provider, the returned func flushes the
+// pending spans on exit
+func Setup(ctx context.Context, o Options, version string) (func(context.Context) error, error) {
+	if !o.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporterOptions []otlptracehttp.Option
+	if o.Endpoint != "" {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithEndpoint(o.Endpoint))
+	}
+	if o.Insecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// Start starts a span that is a child of the span in ctx, if any
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End ends a span, an error marks it as failed
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func CR(namespace string, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{CRNamespaceKey.String(namespace), CRNameKey.String(name)}
+}
+
+// InstrumentReconciler starts a span for every reconcile of inner, the spans
+// started with the context of the reconcile are its children
+func InstrumentReconciler(controller string, inner reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		ctx, span := Start(ctx, "Reconcile", append(CR(request.Namespace, request.Name), ControllerKey.String(controller))...)
+		result, err := inner.Reconcile(ctx, request)
+		End(span, err)
+		return result, err
+	})
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// RecordSpans installs a tracer provider that keeps the ended spans in memory
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	previous := otel.GetTracerProvider()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributesOf(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attributes := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value.Emit()
+	}
+	return attributes
+}
+
+func TestInstrumentReconciler(t *testing.T) {
+	recorder := recordSpans(t)
+
+	instrumented := InstrumentReconciler("Test", reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		_, span := Start(ctx, "validate")
+		End(span, nil)
+		return reconcile.Result{}, errors.New("failed")
+	}))
+	_, err := instrumented.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "traced"}})
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	child, root := spans[0], spans[1]
+	assert.Equal(t, "validate", child.Name())
+	assert.Equal(t, "Reconcile", root.Name())
+	assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, map[attribute.Key]string{ControllerKey: "Test", CRNamespaceKey: "test", CRNameKey: "traced"}, attributesOf(root))
+	assert.Equal(t, codes.Error, root.Status().Code)
+	assert.Equal(t, codes.Unset, child.Status().Code)
+}
+
+func TestSetupIsOffByDefault(t *testing.T) {
+	for _, envVar := range endpointEnvVars {
+		t.Setenv(envVar, "")
+	}
+	previous := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.TODO(), Options{SampleRatio: 1}, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, previous, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.TODO()))
+}
+
+func TestSetupExportsToCollector(t *testing.T) {
+	received := make(chan *http.Request, 10)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	shutdown, err := Setup(context.TODO(), Options{
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Insecure:    true,
+		SampleRatio: 1,
+	}, "test")
+	assert.NoError(t, err)
+
+	_, span := Start(context.TODO(), "Reconcile", CR("test", "exported")...)
+	End(span, nil)
+	// flushes the batch
+	assert.NoError(t, shutdown(context.TODO()))
+
+	select {
+	case request := <-received:
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/v1/traces", request.URL.Path)
+	default:
+		t.Fatal("the collector received no spans")
+	}
+}